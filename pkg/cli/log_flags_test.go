@@ -35,7 +35,7 @@ func TestSetupLogging(t *testing.T) {
 	reWhitespace := regexp.MustCompile(`(?ms:((\s|\n)+))`)
 	reBracketWhitespace := regexp.MustCompile(`(?P<bracket>[{[])\s+`)
 
-	reSimplify := regexp.MustCompile(`(?ms:^\s*(auditable: false|redact: false|exit-on-error: true|max-group-size: 100MiB)\n)`)
+	reSimplify := regexp.MustCompile(`(?ms:^\s*(auditable: false|redact: false|compress-rotated-files: false|rotation-interval: 0s|exit-on-error: true|max-group-size: 100MiB)\n)`)
 
 	const defaultFluentConfig = `fluent-defaults: {` +
 		`filter: INFO, ` +
@@ -25,9 +25,10 @@ import (
 type Metrics struct {
 	JobMetrics [jobspb.NumJobTypes]*JobTypeMetrics
 
-	RowLevelTTL  metric.Struct
-	Changefeed   metric.Struct
-	StreamIngest metric.Struct
+	RowLevelTTL    metric.Struct
+	Changefeed     metric.Struct
+	StreamIngest   metric.Struct
+	SchemaChangeGC metric.Struct
 
 	// AdoptIterations counts the number of adopt loops executed by Registry.
 	AdoptIterations *metric.Counter
@@ -189,6 +190,9 @@ func (m *Metrics) init(histogramWindowInterval time.Duration) {
 	if MakeStreamIngestMetricsHook != nil {
 		m.StreamIngest = MakeStreamIngestMetricsHook(histogramWindowInterval)
 	}
+	if MakeSchemaChangeGCMetricsHook != nil {
+		m.SchemaChangeGC = MakeSchemaChangeGCMetricsHook(histogramWindowInterval)
+	}
 	m.AdoptIterations = metric.NewCounter(metaAdoptIterations)
 	m.ClaimedJobs = metric.NewCounter(metaClaimedJobs)
 	m.ResumedJobs = metric.NewCounter(metaResumedClaimedJobs)
@@ -222,6 +226,10 @@ var MakeStreamIngestMetricsHook func(duration time.Duration) metric.Struct
 // MakeRowLevelTTLMetricsHook allows for registration of row-level TTL metrics.
 var MakeRowLevelTTLMetricsHook func(time.Duration) metric.Struct
 
+// MakeSchemaChangeGCMetricsHook allows for registration of schema change GC
+// metrics.
+var MakeSchemaChangeGCMetricsHook func(time.Duration) metric.Struct
+
 // JobTelemetryMetrics is a telemetry metrics for individual job types.
 type JobTelemetryMetrics struct {
 	Successful telemetry.Counter
@@ -0,0 +1,48 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// logFileFormat overrides the format of the entries written to the file
+// sinks of the logging configuration. It is system-only, as the log files of
+// a node are shared by all of the tenants it serves.
+var logFileFormat = settings.RegisterEnumSetting(
+	settings.SystemOnly,
+	"log.file.format",
+	"the format of the entries written to log files; text uses the format "+
+		"configured for each file sink, json and logfmt override it; changing it "+
+		"rotates the open log files",
+	"text",
+	map[int64]string{
+		0: "text",
+		1: "json",
+		2: "logfmt",
+	},
+)
+
+// startApplyingLogFileFormat applies the log.file.format cluster setting to
+// the logging package, now and whenever the setting changes.
+func (s *Server) startApplyingLogFileFormat(ctx context.Context) {
+	apply := func(ctx context.Context) {
+		format := logFileFormat.String(&s.st.SV)
+		if err := log.SetFileFormat(format); err != nil {
+			log.Warningf(ctx, "unable to apply log file format %q: %v", format, err)
+		}
+	}
+	logFileFormat.SetOnChange(&s.st.SV, apply)
+	apply(ctx)
+}
@@ -1366,6 +1366,8 @@ func (s *Server) PreStart(ctx context.Context) error {
 		return err
 	}
 
+	s.startApplyingLogFileFormat(ctx)
+
 	var graphiteOnce sync.Once
 	graphiteEndpoint.SetOnChange(&s.st.SV, func(context.Context) {
 		if graphiteEndpoint.Get(&s.st.SV) != "" {
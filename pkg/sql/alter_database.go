@@ -14,6 +14,8 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
+	"unicode"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/security"
@@ -1064,7 +1066,12 @@ func (n *alterDatabaseSurvivalGoalNode) startExec(params runParams) error {
 		)
 	}
 
-	if n.n.SurvivalGoal == tree.SurvivalGoalRegionFailure &&
+	survivalGoal, err := TranslateSurvivalGoal(n.n.SurvivalGoal)
+	if err != nil {
+		return err
+	}
+
+	if survivalGoal != descpb.SurvivalGoal_ZONE_FAILURE &&
 		n.desc.RegionConfig.Placement == descpb.DataPlacement_RESTRICTED {
 		return errors.WithDetailf(
 			pgerror.New(pgcode.InvalidParameterValue,
@@ -1073,13 +1080,13 @@ func (n *alterDatabaseSurvivalGoalNode) startExec(params runParams) error {
 		)
 	}
 
-	if n.n.SurvivalGoal == tree.SurvivalGoalRegionFailure {
+	if survivalGoal != descpb.SurvivalGoal_ZONE_FAILURE {
 		superRegions, err := params.p.getSuperRegionsForDatabase(params.ctx, n.desc)
 		if err != nil {
 			return err
 		}
 		for _, sr := range superRegions {
-			if err := multiregion.CanSatisfySurvivalGoal(descpb.SurvivalGoal_REGION_FAILURE, len(sr.Regions)); err != nil {
+			if err := multiregion.CanSatisfySurvivalGoal(survivalGoal, len(sr.Regions)); err != nil {
 				return errors.Wrapf(err, "super region %s only has %d region(s)", sr.SuperRegionName, len(sr.Regions))
 			}
 		}
@@ -1099,10 +1106,6 @@ func (n *alterDatabaseSurvivalGoalNode) startExec(params runParams) error {
 	)
 
 	// Update the survival goal in the database descriptor
-	survivalGoal, err := TranslateSurvivalGoal(n.n.SurvivalGoal)
-	if err != nil {
-		return err
-	}
 	n.desc.RegionConfig.SurvivalGoal = survivalGoal
 
 	if err := params.p.writeNonDropDatabaseChange(
@@ -1206,7 +1209,7 @@ func (n *alterDatabasePlacementNode) startExec(params runParams) error {
 	}
 
 	if n.n.Placement == tree.DataPlacementRestricted &&
-		n.desc.RegionConfig.SurvivalGoal == descpb.SurvivalGoal_REGION_FAILURE {
+		n.desc.RegionConfig.SurvivalGoal != descpb.SurvivalGoal_ZONE_FAILURE {
 		return errors.WithDetailf(
 			pgerror.New(pgcode.InvalidParameterValue,
 				"a region-survivable database cannot also have a restricted placement policy"),
@@ -1325,6 +1328,10 @@ func (p *planner) AlterDatabaseAddSuperRegion(
 }
 
 func (n *alterDatabaseAddSuperRegion) startExec(params runParams) error {
+	if err := validateSuperRegionName(n.n.SuperRegionName); err != nil {
+		return err
+	}
+
 	// If the database is not a multi-region database, a super region cannot
 	// be added.
 	if !n.desc.IsMultiRegion() {
@@ -1350,6 +1357,21 @@ func (n *alterDatabaseAddSuperRegion) startExec(params runParams) error {
 	return params.p.addSuperRegion(params.ctx, n.desc, typeDesc, n.n.Regions, n.n.SuperRegionName, tree.AsStringWithFQNames(n.n, params.Ann()))
 }
 
+// validateSuperRegionName ensures that the name of a new super region is
+// not empty and only contains printable characters. Super region names which
+// were already persisted are not checked, so this is not part of the
+// validation of the region config.
+func validateSuperRegionName(name tree.Name) error {
+	if err := catalog.ValidateName(string(name), "super region"); err != nil {
+		return err
+	}
+	if i := strings.IndexFunc(string(name), func(r rune) bool { return !unicode.IsPrint(r) }); i != -1 {
+		return pgerror.Newf(pgcode.InvalidName,
+			"super region name %q contains the non-printable character %q", string(name), []rune(string(name)[i:])[0])
+	}
+	return nil
+}
+
 // addSuperRegion adds the super region in sorted order based on the
 // name of super region.
 func addSuperRegion(r *descpb.TypeDescriptor_RegionConfig, superRegion descpb.SuperRegion) {
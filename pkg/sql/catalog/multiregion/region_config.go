@@ -26,19 +26,74 @@ import (
 // a database must have to survive a REGION failure.
 const minNumRegionsForSurviveRegionGoal = 3
 
+// minNumRegionsForSurviveTwoRegionsGoal is the minimum number of regions that
+// a database must have to survive two simultaneous REGION failures.
+const minNumRegionsForSurviveTwoRegionsGoal = 5
+
+// DefaultConstraintKey is the locality tier key on which the constraints
+// generated for a multi-region database are keyed by default.
+const DefaultConstraintKey = "region"
+
+// AvailabilityZoneConstraintKey is the locality tier key on which the
+// constraints spreading voters across the availability zones of the primary
+// region are keyed.
+const AvailabilityZoneConstraintKey = "zone"
+
+// minAvailabilityZonesForZoneSurvival is the number of availability zones of
+// the primary region needed to spread the voters of a zone survivable
+// database across distinct availability zones.
+const minAvailabilityZonesForZoneSurvival = 3
+
+// ConstraintKind identifies a kind of constraints generated for a
+// multi-region database.
+type ConstraintKind int
+
+const (
+	// ReplicaConstraintKind identifies the constraints on all replicas.
+	ReplicaConstraintKind ConstraintKind = iota
+	// VoterConstraintKind identifies the constraints on the voting replicas.
+	VoterConstraintKind
+	// LeaseConstraintKind identifies the lease preferences.
+	LeaseConstraintKind
+
+	numConstraintKinds
+)
+
 // RegionConfig represents the user configured state of a multi-region database.
 // RegionConfig is intended to be a READ-ONLY struct and as such all members
 // are private. Any modifications to the underlying type desc / db desc that
 // inform be a RegionConfig must be made directly on those structs and a new
 // RegionConfig must be synthesized to pick up those changes.
 type RegionConfig struct {
-	survivalGoal         descpb.SurvivalGoal
-	regions              catpb.RegionNames
-	transitioningRegions catpb.RegionNames
-	primaryRegion        catpb.RegionName
-	regionEnumID         descpb.ID
-	placement            descpb.DataPlacement
-	superRegions         []descpb.SuperRegion
+	survivalGoal          descpb.SurvivalGoal
+	regions               catpb.RegionNames
+	transitioningRegions  catpb.RegionNames
+	primaryRegion         catpb.RegionName
+	regionEnumID          descpb.ID
+	placement             descpb.DataPlacement
+	superRegions          []descpb.SuperRegion
+	primaryOnlyVoters     bool
+	voterWeights          map[catpb.RegionName]int32
+	partitionSurvival     map[catpb.RegionName]descpb.SurvivalGoal
+	extraPrimaryNonVoters int32
+	nonVotingRegions      catpb.RegionNames
+	secondaryRegion       catpb.RegionName
+	voterRegions          catpb.RegionNames
+	regionPlacements      map[catpb.RegionName]descpb.DataPlacement
+	tieBreakerKey         string
+	tieBreakerValue       string
+	constraintKeys        [numConstraintKinds]string
+	prohibitedRegions     catpb.RegionNames
+	availabilityZones     map[catpb.RegionName]int32
+
+	// nonVoterStorageClass is only meaningful if hasNonVoterStorageClass is
+	// set, so that an empty storage class can be rejected by validation.
+	nonVoterStorageClass    string
+	hasNonVoterStorageClass bool
+
+	standbyRegion catpb.RegionName
+
+	primaryRegionAvailabilityZones []string
 }
 
 // SurvivalGoal returns the survival goal configured on the RegionConfig.
@@ -128,6 +183,258 @@ func (r *RegionConfig) SuperRegions() []descpb.SuperRegion {
 	return r.superRegions
 }
 
+// IsPrimaryRegionOnlyVoters returns true if all voting replicas are to be
+// placed in the primary region, with a non-voting replica in every other
+// region.
+func (r *RegionConfig) IsPrimaryRegionOnlyVoters() bool {
+	return r.primaryOnlyVoters
+}
+
+// VoterWeights returns the number of voting replicas explicitly requested for
+// each region, if any were configured.
+func (r *RegionConfig) VoterWeights() map[catpb.RegionName]int32 {
+	return r.voterWeights
+}
+
+// HasVoterWeights returns true if voting replicas are to be distributed
+// according to explicit per-region weights.
+func (r *RegionConfig) HasVoterWeights() bool {
+	return len(r.voterWeights) > 0
+}
+
+// PartitionSurvivalGoal returns the survival goal of the REGIONAL BY ROW
+// partition homed in the given region. This is the database's survival goal
+// unless it was overridden for the partition.
+func (r *RegionConfig) PartitionSurvivalGoal(region catpb.RegionName) descpb.SurvivalGoal {
+	if goal, ok := r.partitionSurvival[region]; ok {
+		return goal
+	}
+	return r.survivalGoal
+}
+
+// ExtraPrimaryRegionNonVoters returns the number of non-voting replicas to
+// place in the primary region in addition to its voting replicas.
+func (r *RegionConfig) ExtraPrimaryRegionNonVoters() int32 {
+	return r.extraPrimaryNonVoters
+}
+
+// NonVotingRegions returns the regions which may only hold non-voting
+// replicas.
+func (r *RegionConfig) NonVotingRegions() catpb.RegionNames {
+	return r.nonVotingRegions
+}
+
+// HasNonVotingRegions returns true if some regions of the RegionConfig may
+// only hold non-voting replicas.
+func (r *RegionConfig) HasNonVotingRegions() bool {
+	return len(r.nonVotingRegions) > 0
+}
+
+// IsVotingRegion returns true if the given region may hold voting replicas.
+// Neither the non-voting regions nor the standby region may.
+func (r *RegionConfig) IsVotingRegion(region catpb.RegionName) bool {
+	if r.HasStandbyRegion() && region == r.standbyRegion {
+		return false
+	}
+	for _, nonVotingRegion := range r.nonVotingRegions {
+		if region == nonVotingRegion {
+			return false
+		}
+	}
+	return true
+}
+
+// VotingRegions returns the regions of the RegionConfig which may hold voting
+// replicas.
+func (r *RegionConfig) VotingRegions() catpb.RegionNames {
+	if !r.HasNonVotingRegions() && !r.HasStandbyRegion() {
+		return r.regions
+	}
+	ret := make(catpb.RegionNames, 0, len(r.regions))
+	for _, region := range r.regions {
+		if r.IsVotingRegion(region) {
+			ret = append(ret, region)
+		}
+	}
+	return ret
+}
+
+// StandbyRegion returns the region which holds a full copy of the data for
+// disaster recovery, but neither votes nor holds leases, if one was
+// configured.
+func (r *RegionConfig) StandbyRegion() catpb.RegionName {
+	return r.standbyRegion
+}
+
+// HasStandbyRegion returns true if a standby region was configured.
+func (r *RegionConfig) HasStandbyRegion() bool {
+	return r.standbyRegion != ""
+}
+
+// PrimaryRegionAvailabilityZones returns the names of the availability zones
+// of the primary region across which the voters are spread, if any.
+func (r *RegionConfig) PrimaryRegionAvailabilityZones() []string {
+	return r.primaryRegionAvailabilityZones
+}
+
+// HasPrimaryRegionAvailabilityZones returns true if the voters are spread
+// across named availability zones of the primary region.
+func (r *RegionConfig) HasPrimaryRegionAvailabilityZones() bool {
+	return len(r.primaryRegionAvailabilityZones) > 0
+}
+
+// SecondaryRegion returns the region which is preferred for leases if the
+// primary region becomes unavailable, if one was configured.
+func (r *RegionConfig) SecondaryRegion() catpb.RegionName {
+	return r.secondaryRegion
+}
+
+// HasSecondaryRegion returns true if a secondary region was configured.
+func (r *RegionConfig) HasSecondaryRegion() bool {
+	return r.secondaryRegion != ""
+}
+
+// VoterRegions returns the regions across which voting replicas are spread
+// regardless of the survival goal, if they were configured.
+func (r *RegionConfig) VoterRegions() catpb.RegionNames {
+	return r.voterRegions
+}
+
+// HasVoterRegions returns true if voting replicas are to be spread across an
+// explicit set of regions, decoupled from the primary region which holds the
+// lease preference.
+func (r *RegionConfig) HasVoterRegions() bool {
+	return len(r.voterRegions) > 0
+}
+
+// TieBreakerVoter returns the locality tier, outside of the regions of the
+// database, in which a tie-breaker voting replica is placed.
+func (r *RegionConfig) TieBreakerVoter() (key, value string) {
+	return r.tieBreakerKey, r.tieBreakerValue
+}
+
+// HasTieBreakerVoter returns true if a tie-breaker voting replica is placed
+// outside of the regions of the database.
+func (r *RegionConfig) HasTieBreakerVoter() bool {
+	return r.tieBreakerKey != ""
+}
+
+// ConstraintKey returns the locality tier key on which the constraints of the
+// given kind are keyed.
+func (r *RegionConfig) ConstraintKey(kind ConstraintKind) string {
+	if key := r.constraintKeys[kind]; key != "" {
+		return key
+	}
+	return DefaultConstraintKey
+}
+
+// AvailabilityZones returns the number of availability zones of the given
+// region, or 0 if it is unknown.
+func (r *RegionConfig) AvailabilityZones(region catpb.RegionName) int32 {
+	return r.availabilityZones[region]
+}
+
+// HasAvailabilityZones returns true if the number of availability zones of
+// some regions of the RegionConfig is known.
+func (r *RegionConfig) HasAvailabilityZones() bool {
+	return len(r.availabilityZones) > 0
+}
+
+// NonVoterStorageClass returns the store attribute which the non-voting
+// replicas of the database are constrained to, if any.
+func (r *RegionConfig) NonVoterStorageClass() string {
+	return r.nonVoterStorageClass
+}
+
+// HasNonVoterStorageClass returns true if the non-voting replicas of the
+// database are constrained to a storage class.
+func (r *RegionConfig) HasNonVoterStorageClass() bool {
+	return r.hasNonVoterStorageClass
+}
+
+// RegionPlacement returns the data placement of the given region, which is
+// the database's placement unless it was overridden for the region.
+func (r *RegionConfig) RegionPlacement(region catpb.RegionName) descpb.DataPlacement {
+	if placement, ok := r.regionPlacements[region]; ok {
+		return placement
+	}
+	return r.placement
+}
+
+// HasRegionPlacements returns true if the data placement was overridden for
+// some regions of the RegionConfig.
+func (r *RegionConfig) HasRegionPlacements() bool {
+	return len(r.regionPlacements) > 0
+}
+
+// PlacedRegions returns the regions of the RegionConfig which hold a replica,
+// i.e. the primary region and the regions whose data placement is DEFAULT,
+// other than the prohibited regions.
+func (r *RegionConfig) PlacedRegions() catpb.RegionNames {
+	if !r.HasRegionPlacements() && !r.HasProhibitedRegions() {
+		return r.regions
+	}
+	ret := make(catpb.RegionNames, 0, len(r.regions))
+	for _, region := range r.regions {
+		if r.IsProhibitedRegion(region) {
+			continue
+		}
+		if region == r.primaryRegion || r.RegionPlacement(region) == descpb.DataPlacement_DEFAULT {
+			ret = append(ret, region)
+		}
+	}
+	return ret
+}
+
+// ProhibitedRegions returns the regions of the RegionConfig which are
+// prohibited from holding any replica.
+func (r *RegionConfig) ProhibitedRegions() catpb.RegionNames {
+	return r.prohibitedRegions
+}
+
+// HasProhibitedRegions returns true if some regions of the RegionConfig are
+// prohibited from holding any replica.
+func (r *RegionConfig) HasProhibitedRegions() bool {
+	return len(r.prohibitedRegions) > 0
+}
+
+// IsProhibitedRegion returns true if the given region is prohibited from
+// holding any replica.
+func (r *RegionConfig) IsProhibitedRegion(region catpb.RegionName) bool {
+	for _, prohibited := range r.prohibitedRegions {
+		if region == prohibited {
+			return true
+		}
+	}
+	return false
+}
+
+// WithAddedRegion returns a copy of the RegionConfig with the given region
+// added to its regions. The RegionConfig itself is left unchanged.
+func (r RegionConfig) WithAddedRegion(region catpb.RegionName) RegionConfig {
+	ret := r
+	ret.regions = make(catpb.RegionNames, 0, len(r.regions)+1)
+	ret.regions = append(ret.regions, r.regions...)
+	ret.regions = append(ret.regions, region)
+	return ret
+}
+
+// WithPrimaryRegion returns a copy of the RegionConfig with the given primary
+// region. The RegionConfig itself is left unchanged.
+func (r RegionConfig) WithPrimaryRegion(primaryRegion catpb.RegionName) RegionConfig {
+	ret := r
+	ret.primaryRegion = primaryRegion
+	return ret
+}
+
+// WithSurvivalGoal returns a copy of the RegionConfig with the given survival
+// goal. The RegionConfig itself is left unchanged.
+func (r RegionConfig) WithSurvivalGoal(survivalGoal descpb.SurvivalGoal) RegionConfig {
+	ret := r
+	ret.survivalGoal = survivalGoal
+	return ret
+}
+
 // MakeRegionConfigOption is an option for MakeRegionConfig
 type MakeRegionConfigOption func(r *RegionConfig)
 
@@ -139,6 +446,160 @@ func WithTransitioningRegions(transitioningRegions catpb.RegionNames) MakeRegion
 	}
 }
 
+// WithPrimaryRegionOnlyVoters is an option to constrain all voting replicas
+// to the primary region while every other region holds a non-voting replica.
+// Unlike RESTRICTED placement, non-voters are kept in the non-primary regions.
+func WithPrimaryRegionOnlyVoters() MakeRegionConfigOption {
+	return func(r *RegionConfig) {
+		r.primaryOnlyVoters = true
+	}
+}
+
+// WithVoterWeights is an option to bias the placement of voting replicas
+// towards particular regions. Each entry maps a region to the number of voting
+// replicas that should be constrained to it.
+func WithVoterWeights(voterWeights map[catpb.RegionName]int32) MakeRegionConfigOption {
+	return func(r *RegionConfig) {
+		r.voterWeights = voterWeights
+	}
+}
+
+// WithAvailabilityZones is an option to make the placement of replicas aware
+// of the number of availability zones of some regions, so that a region is
+// never required to hold more voting replicas than can be spread across its
+// zones without losing quorum on a zone failure. Each entry maps a region to
+// its number of availability zones.
+func WithAvailabilityZones(availabilityZones map[catpb.RegionName]int32) MakeRegionConfigOption {
+	return func(r *RegionConfig) {
+		r.availabilityZones = availabilityZones
+	}
+}
+
+// WithNonVoterStorageClass is an option to constrain the non-voting read
+// replicas of the database to stores with the given attribute, e.g. to pin
+// them to cheaper storage on a cluster with tiered storage. The voting
+// replicas are unaffected. Only the zone config of the database is affected.
+func WithNonVoterStorageClass(storageClass string) MakeRegionConfigOption {
+	return func(r *RegionConfig) {
+		r.nonVoterStorageClass = storageClass
+		r.hasNonVoterStorageClass = true
+	}
+}
+
+// WithPartitionSurvivalGoals is an option to override the survival goal of
+// the REGIONAL BY ROW partitions homed in the given regions.
+func WithPartitionSurvivalGoals(
+	partitionSurvival map[catpb.RegionName]descpb.SurvivalGoal,
+) MakeRegionConfigOption {
+	return func(r *RegionConfig) {
+		r.partitionSurvival = partitionSurvival
+	}
+}
+
+// WithExtraPrimaryRegionNonVoters is an option to place the given number of
+// additional non-voting replicas in the primary region, e.g. to serve local
+// reads. The placement of voting replicas is unaffected.
+func WithExtraPrimaryRegionNonVoters(n int32) MakeRegionConfigOption {
+	return func(r *RegionConfig) {
+		r.extraPrimaryNonVoters = n
+	}
+}
+
+// WithNonVotingRegions is an option to designate "observer" regions, which
+// serve reads from non-voting replicas but never hold voting replicas.
+func WithNonVotingRegions(nonVotingRegions catpb.RegionNames) MakeRegionConfigOption {
+	return func(r *RegionConfig) {
+		r.nonVotingRegions = nonVotingRegions
+	}
+}
+
+// WithStandbyRegion is an option to designate a read-only standby region,
+// e.g. for disaster recovery, which holds a non-voting replica in addition to
+// the replicas needed by the other regions, and which never holds voting
+// replicas or leases. Only the zone config of the database is affected.
+func WithStandbyRegion(standbyRegion catpb.RegionName) MakeRegionConfigOption {
+	return func(r *RegionConfig) {
+		r.standbyRegion = standbyRegion
+	}
+}
+
+// WithPrimaryRegionAvailabilityZones is an option to spread the voters of a
+// zone survivable database across the given availability zones of the primary
+// region, using constraints on the "zone" locality tier, rather than relying on
+// the allocator to spread them. Only the zone config of the database is
+// affected.
+func WithPrimaryRegionAvailabilityZones(zones ...string) MakeRegionConfigOption {
+	return func(r *RegionConfig) {
+		r.primaryRegionAvailabilityZones = zones
+	}
+}
+
+// WithSecondaryRegion is an option to designate the region which is preferred
+// for leases after the primary region.
+func WithSecondaryRegion(secondaryRegion catpb.RegionName) MakeRegionConfigOption {
+	return func(r *RegionConfig) {
+		r.secondaryRegion = secondaryRegion
+	}
+}
+
+// WithVoterRegions is an option to spread the voting replicas across the given
+// regions, which must include the primary region, rather than placing them
+// according to the survival goal. The lease preference remains pinned to the
+// primary region, allowing for low-latency leases while the voters are
+// distributed for durability.
+func WithVoterRegions(voterRegions catpb.RegionNames) MakeRegionConfigOption {
+	return func(r *RegionConfig) {
+		r.voterRegions = voterRegions
+	}
+}
+
+// WithRegionPlacements is an option to override the data placement of the
+// given regions of a database with DEFAULT placement. The regions with
+// RESTRICTED placement other than the primary region hold no non-voting
+// replica of the database, while the other regions keep theirs. Only the
+// zone config of the database is affected.
+func WithRegionPlacements(
+	regionPlacements map[catpb.RegionName]descpb.DataPlacement,
+) MakeRegionConfigOption {
+	return func(r *RegionConfig) {
+		r.regionPlacements = regionPlacements
+	}
+}
+
+// WithTieBreakerVoter is an option to place one of the voting replicas in the
+// locality tier key=value, which lies outside of the regions of the database,
+// e.g. in a different cloud. The number of voters is unchanged, so that it
+// remains odd, and the voters placed in the regions are reduced by one. Only
+// the zone config of the database is affected.
+func WithTieBreakerVoter(key, value string) MakeRegionConfigOption {
+	return func(r *RegionConfig) {
+		r.tieBreakerKey = key
+		r.tieBreakerValue = value
+	}
+}
+
+// WithConstraintKey is an option to key the constraints of the given kind on
+// the locality tier key instead of DefaultConstraintKey, e.g. to key the voter
+// constraints on a "zone" tier nested within the regions. The values of the
+// constraints remain the region names. Only the zone config of the database is
+// affected.
+func WithConstraintKey(kind ConstraintKind, key string) MakeRegionConfigOption {
+	return func(r *RegionConfig) {
+		r.constraintKeys[kind] = key
+	}
+}
+
+// WithProhibitedRegions is an option to keep the non-voting read replicas of
+// the database out of the given regions, e.g. for data residency. The voters
+// are never placed in these regions either, as every replica of the database
+// is pinned to the other regions. Only the zone config of the database is
+// affected.
+func WithProhibitedRegions(prohibitedRegions catpb.RegionNames) MakeRegionConfigOption {
+	return func(r *RegionConfig) {
+		r.prohibitedRegions = prohibitedRegions
+	}
+}
+
 // MakeRegionConfig constructs a RegionConfig.
 func MakeRegionConfig(
 	regions catpb.RegionNames,
@@ -179,6 +640,19 @@ func CanSatisfySurvivalGoal(survivalGoal descpb.SurvivalGoal, numRegions int) er
 			)
 		}
 	}
+	if survivalGoal == descpb.SurvivalGoal_TWO_REGION_FAILURE {
+		if numRegions < minNumRegionsForSurviveTwoRegionsGoal {
+			return errors.WithHintf(
+				pgerror.Newf(
+					pgcode.InvalidParameterValue,
+					"at least %d regions are required for surviving two region failures",
+					minNumRegionsForSurviveTwoRegionsGoal,
+				),
+				"you must add additional regions to the database or "+
+					"change the survivability goal",
+			)
+		}
+	}
 	return nil
 }
 
@@ -191,10 +665,55 @@ func ValidateRegionConfig(config RegionConfig) error {
 		return errors.AssertionFailedf("expected > 0 number of regions in the region config")
 	}
 	if config.placement == descpb.DataPlacement_RESTRICTED &&
-		config.survivalGoal == descpb.SurvivalGoal_REGION_FAILURE {
+		config.survivalGoal != descpb.SurvivalGoal_ZONE_FAILURE {
 		return errors.AssertionFailedf(
 			"cannot have a database with restricted placement that is also region survivable")
 	}
+	if err := ValidatePrimaryRegionOnlyVoters(config); err != nil {
+		return err
+	}
+	if err := validateVoterWeights(config); err != nil {
+		return err
+	}
+	if err := ValidatePartitionSurvivalGoals(config); err != nil {
+		return err
+	}
+	if err := validateExtraPrimaryRegionNonVoters(config); err != nil {
+		return err
+	}
+	if err := validateNonVotingRegions(config); err != nil {
+		return err
+	}
+	if err := validateSecondaryRegion(config); err != nil {
+		return err
+	}
+	if err := validateStandbyRegion(config); err != nil {
+		return err
+	}
+	if err := validateVoterRegions(config); err != nil {
+		return err
+	}
+	if err := validateRegionPlacements(config); err != nil {
+		return err
+	}
+	if err := validateTieBreakerVoter(config); err != nil {
+		return err
+	}
+	if err := validateProhibitedRegions(config); err != nil {
+		return err
+	}
+	if err := validateConstraintKeys(config); err != nil {
+		return err
+	}
+	if err := validateAvailabilityZones(config); err != nil {
+		return err
+	}
+	if err := validateNonVoterStorageClass(config); err != nil {
+		return err
+	}
+	if err := validatePrimaryRegionAvailabilityZones(config); err != nil {
+		return err
+	}
 
 	err := ValidateSuperRegions(config.SuperRegions(), config.SurvivalGoal(), config.Regions(), func(err error) error {
 		return err
@@ -206,6 +725,381 @@ func ValidateRegionConfig(config RegionConfig) error {
 	return CanSatisfySurvivalGoal(config.survivalGoal, len(config.regions))
 }
 
+// ValidatePrimaryRegionOnlyVoters ensures that a RegionConfig which places
+// all voters in the primary region is compatible with its survival goal and
+// data placement.
+func ValidatePrimaryRegionOnlyVoters(config RegionConfig) error {
+	if !config.primaryOnlyVoters {
+		return nil
+	}
+	if config.survivalGoal != descpb.SurvivalGoal_ZONE_FAILURE {
+		return errors.AssertionFailedf(
+			"cannot constrain all voters to the primary region of a region survivable database")
+	}
+	if config.placement == descpb.DataPlacement_RESTRICTED {
+		return errors.AssertionFailedf(
+			"cannot constrain all voters to the primary region of a database with restricted placement")
+	}
+	return nil
+}
+
+// validateVoterWeights ensures that any per-region voter weights only refer to
+// regions of the database and are only used when voters may be spread across
+// regions, i.e. under region survivability.
+func validateVoterWeights(config RegionConfig) error {
+	if !config.HasVoterWeights() {
+		return nil
+	}
+	if config.survivalGoal != descpb.SurvivalGoal_REGION_FAILURE {
+		return errors.AssertionFailedf(
+			"voter weights can only be configured on a region survivable database")
+	}
+	for region, weight := range config.voterWeights {
+		if weight < 0 {
+			return errors.AssertionFailedf("voter weight for region %s must be non-negative, found %d", region, weight)
+		}
+		if !config.IsValidRegionNameString(string(region)) {
+			return errors.AssertionFailedf("voter weight configured for region %s which is not part of the database", region)
+		}
+	}
+	return nil
+}
+
+// validateAvailabilityZones ensures that the number of availability zones is
+// only configured for regions of the database, and is positive.
+func validateAvailabilityZones(config RegionConfig) error {
+	for region, n := range config.availabilityZones {
+		if !config.IsValidRegionNameString(string(region)) {
+			return errors.AssertionFailedf(
+				"availability zones configured for region %s which is not part of the database", region)
+		}
+		if n <= 0 {
+			return errors.AssertionFailedf(
+				"number of availability zones of region %s must be positive, found %d", region, n)
+		}
+	}
+	return nil
+}
+
+// validatePrimaryRegionAvailabilityZones ensures that the availability zones
+// of the primary region across which the voters are spread are only
+// configured for a zone survivable database, are named and distinct, and are
+// numerous enough to place each voter in its own availability zone.
+func validatePrimaryRegionAvailabilityZones(config RegionConfig) error {
+	if !config.HasPrimaryRegionAvailabilityZones() {
+		return nil
+	}
+	if config.survivalGoal != descpb.SurvivalGoal_ZONE_FAILURE {
+		return errors.AssertionFailedf(
+			"availability zones of the primary region can only be configured for a zone survivable database")
+	}
+	seen := make(map[string]struct{}, len(config.primaryRegionAvailabilityZones))
+	for _, zone := range config.primaryRegionAvailabilityZones {
+		if zone == "" {
+			return errors.AssertionFailedf("availability zone of the primary region must not be empty")
+		}
+		if _, ok := seen[zone]; ok {
+			return errors.AssertionFailedf("duplicate availability zone %s of the primary region", zone)
+		}
+		seen[zone] = struct{}{}
+	}
+	if n := len(config.primaryRegionAvailabilityZones); n < minAvailabilityZonesForZoneSurvival {
+		return errors.AssertionFailedf(
+			"at least %d availability zones of the primary region are required to survive a zone failure, found %d",
+			minAvailabilityZonesForZoneSurvival, n)
+	}
+	return nil
+}
+
+// validateNonVoterStorageClass ensures that the storage class of the
+// non-voting replicas is not empty if one was configured.
+func validateNonVoterStorageClass(config RegionConfig) error {
+	if config.hasNonVoterStorageClass && config.nonVoterStorageClass == "" {
+		return errors.AssertionFailedf("storage class of the non-voting replicas must not be empty")
+	}
+	return nil
+}
+
+// validateVoterRegions ensures that the regions across which voting replicas
+// are spread are regions of the database which include the primary region, so
+// that it can hold the lease, and that there are enough of them to satisfy the
+// survival goal.
+func validateVoterRegions(config RegionConfig) error {
+	if !config.HasVoterRegions() {
+		return nil
+	}
+	hasPrimary := false
+	for _, region := range config.voterRegions {
+		if !config.IsValidRegionNameString(string(region)) {
+			return errors.AssertionFailedf("voter region %s is not a region of the database", region)
+		}
+		if region == config.primaryRegion {
+			hasPrimary = true
+		}
+	}
+	if !hasPrimary {
+		return errors.AssertionFailedf(
+			"voter regions must include the primary region %s", config.primaryRegion)
+	}
+	if config.placement == descpb.DataPlacement_RESTRICTED {
+		return errors.AssertionFailedf(
+			"cannot have voter regions in a database with restricted placement")
+	}
+	if config.primaryOnlyVoters || config.HasVoterWeights() || config.HasNonVotingRegions() {
+		return errors.AssertionFailedf(
+			"voter regions cannot be combined with primary region only voters, voter weights or non-voting regions")
+	}
+	if config.survivalGoal == descpb.SurvivalGoal_TWO_REGION_FAILURE {
+		return errors.AssertionFailedf(
+			"cannot have voter regions in a database surviving two region failures")
+	}
+	if err := CanSatisfySurvivalGoal(config.survivalGoal, len(config.voterRegions)); err != nil {
+		return errors.Wrap(err, "insufficient voter regions")
+	}
+	return nil
+}
+
+// validateTieBreakerVoter ensures that the tie-breaker voter is placed in a
+// locality tier other than the regions, and that it is not combined with
+// options which constrain all of the voters.
+func validateTieBreakerVoter(config RegionConfig) error {
+	if config.tieBreakerKey == "" && config.tieBreakerValue == "" {
+		return nil
+	}
+	if config.tieBreakerKey == "" || config.tieBreakerValue == "" {
+		return errors.AssertionFailedf(
+			"expected a key and a value for the tie-breaker voter, found %q=%q",
+			config.tieBreakerKey, config.tieBreakerValue)
+	}
+	if config.tieBreakerKey == DefaultConstraintKey {
+		return errors.AssertionFailedf(
+			"tie-breaker voter must be placed outside of the regions of the database")
+	}
+	if config.primaryOnlyVoters || config.HasVoterWeights() || config.HasVoterRegions() {
+		return errors.AssertionFailedf(
+			"tie-breaker voter cannot be combined with primary region only voters, voter weights or voter regions")
+	}
+	return nil
+}
+
+// validateConstraintKeys ensures that the constraints are not keyed on the
+// locality tier of the tie-breaker voter, which lies outside of the regions.
+func validateConstraintKeys(config RegionConfig) error {
+	if !config.HasTieBreakerVoter() {
+		return nil
+	}
+	for kind := ConstraintKind(0); kind < numConstraintKinds; kind++ {
+		if config.ConstraintKey(kind) == config.tieBreakerKey {
+			return errors.AssertionFailedf(
+				"constraints cannot be keyed on %q, the locality tier of the tie-breaker voter",
+				config.tieBreakerKey)
+		}
+	}
+	return nil
+}
+
+// validateProhibitedRegions ensures that the prohibited regions are regions
+// of the database in which no voter is required, and that enough regions
+// remain to satisfy the survival goal.
+func validateProhibitedRegions(config RegionConfig) error {
+	if !config.HasProhibitedRegions() {
+		return nil
+	}
+	if config.placement == descpb.DataPlacement_RESTRICTED {
+		return errors.AssertionFailedf(
+			"cannot prohibit regions of a database with restricted placement")
+	}
+	for _, region := range config.prohibitedRegions {
+		if !config.IsValidRegionNameString(string(region)) {
+			return errors.AssertionFailedf("prohibited region %s is not part of the database", region)
+		}
+		if region == config.primaryRegion {
+			return errors.AssertionFailedf("cannot prohibit the primary region %s", region)
+		}
+		if region == config.secondaryRegion {
+			return errors.AssertionFailedf("cannot prohibit the secondary region %s", region)
+		}
+	}
+	if config.primaryOnlyVoters || config.HasVoterWeights() || config.HasVoterRegions() ||
+		config.HasRegionPlacements() || config.extraPrimaryNonVoters > 0 {
+		return errors.AssertionFailedf(
+			"prohibited regions cannot be combined with primary region only voters, voter weights, " +
+				"voter regions, region placements or extra primary region non-voters")
+	}
+	if err := CanSatisfySurvivalGoal(config.survivalGoal, len(config.PlacedRegions())); err != nil {
+		return errors.Wrap(err, "insufficient regions which are not prohibited")
+	}
+	return nil
+}
+
+// validateRegionPlacements ensures that the data placement is only overridden
+// for regions of a database with DEFAULT placement, and that enough regions
+// are guaranteed to hold a replica to satisfy the survival goal.
+func validateRegionPlacements(config RegionConfig) error {
+	if !config.HasRegionPlacements() {
+		return nil
+	}
+	if config.placement == descpb.DataPlacement_RESTRICTED {
+		return errors.AssertionFailedf(
+			"cannot override the placement of regions of a database with restricted placement")
+	}
+	for region := range config.regionPlacements {
+		if !config.IsValidRegionNameString(string(region)) {
+			return errors.AssertionFailedf("placement configured for region %s which is not part of the database", region)
+		}
+		if config.RegionPlacement(region) == descpb.DataPlacement_RESTRICTED && !config.IsVotingRegion(region) {
+			return errors.AssertionFailedf("non-voting region %s cannot have restricted placement", region)
+		}
+	}
+	if config.primaryOnlyVoters || config.HasVoterWeights() || config.HasVoterRegions() {
+		return errors.AssertionFailedf(
+			"region placements cannot be combined with primary region only voters, voter weights or voter regions")
+	}
+	if err := CanSatisfySurvivalGoal(config.survivalGoal, len(config.PlacedRegions())); err != nil {
+		return errors.Wrap(err, "insufficient regions with default placement")
+	}
+	return nil
+}
+
+// validateExtraPrimaryRegionNonVoters ensures that extra non-voting replicas
+// are only requested for the primary region of databases which may hold
+// non-voting replicas.
+func validateExtraPrimaryRegionNonVoters(config RegionConfig) error {
+	if config.extraPrimaryNonVoters < 0 {
+		return errors.AssertionFailedf(
+			"number of extra non-voters in the primary region must be non-negative, found %d",
+			config.extraPrimaryNonVoters)
+	}
+	if config.extraPrimaryNonVoters > 0 && config.placement == descpb.DataPlacement_RESTRICTED {
+		return errors.AssertionFailedf(
+			"cannot place extra non-voters in the primary region of a database with restricted placement")
+	}
+	return nil
+}
+
+// validateNonVotingRegions ensures that the non-voting regions of a
+// RegionConfig are regions of the database other than the primary region, and
+// that enough voting regions remain to satisfy the survival goal.
+func validateNonVotingRegions(config RegionConfig) error {
+	if !config.HasNonVotingRegions() {
+		return nil
+	}
+	for _, region := range config.nonVotingRegions {
+		if !config.IsValidRegionNameString(string(region)) {
+			return errors.AssertionFailedf(
+				"non-voting region %s is not a region of the database", region)
+		}
+		if region == config.primaryRegion {
+			return errors.AssertionFailedf("primary region %s cannot be a non-voting region", region)
+		}
+	}
+	if config.placement == descpb.DataPlacement_RESTRICTED {
+		return errors.AssertionFailedf(
+			"cannot have non-voting regions in a database with restricted placement")
+	}
+	if config.HasVoterWeights() {
+		return errors.AssertionFailedf("cannot have both voter weights and non-voting regions")
+	}
+	if config.survivalGoal == descpb.SurvivalGoal_TWO_REGION_FAILURE {
+		return errors.AssertionFailedf(
+			"cannot have non-voting regions in a database surviving two region failures")
+	}
+	if err := CanSatisfySurvivalGoal(config.survivalGoal, len(config.VotingRegions())); err != nil {
+		return errors.Wrap(err, "insufficient voting regions")
+	}
+	return nil
+}
+
+// validateStandbyRegion ensures that the standby region is a region of the
+// database which is not required to hold voters or leases, and that enough
+// voting regions remain to satisfy the survival goal.
+func validateStandbyRegion(config RegionConfig) error {
+	if !config.HasStandbyRegion() {
+		return nil
+	}
+	region := config.standbyRegion
+	if !config.IsValidRegionNameString(string(region)) {
+		return errors.AssertionFailedf("standby region %s is not a region of the database", region)
+	}
+	if region == config.primaryRegion {
+		return errors.AssertionFailedf("primary region %s cannot be the standby region", region)
+	}
+	if region == config.secondaryRegion {
+		return errors.AssertionFailedf("secondary region %s cannot be the standby region", region)
+	}
+	if config.RegionPlacement(region) == descpb.DataPlacement_RESTRICTED ||
+		config.IsProhibitedRegion(region) {
+		return errors.AssertionFailedf(
+			"standby region %s must be allowed to hold non-voting replicas", region)
+	}
+	if config.HasVoterWeights() || config.HasVoterRegions() {
+		return errors.AssertionFailedf(
+			"standby region cannot be combined with voter weights or voter regions")
+	}
+	if config.survivalGoal == descpb.SurvivalGoal_TWO_REGION_FAILURE {
+		return errors.AssertionFailedf(
+			"cannot have a standby region in a database surviving two region failures")
+	}
+	if err := CanSatisfySurvivalGoal(config.survivalGoal, len(config.VotingRegions())); err != nil {
+		return errors.Wrap(err, "insufficient voting regions")
+	}
+	return nil
+}
+
+// validateSecondaryRegion ensures that the secondary region is a region of the
+// database other than the primary region. If the primary region is part of a
+// super region, the data homed in the primary region is constrained to that
+// super region, so the secondary region must be part of it as well for its
+// lease preference to be satisfiable.
+func validateSecondaryRegion(config RegionConfig) error {
+	if !config.HasSecondaryRegion() {
+		return nil
+	}
+	if !config.IsValidRegionNameString(string(config.secondaryRegion)) {
+		return errors.AssertionFailedf(
+			"secondary region %s is not a region of the database", config.secondaryRegion)
+	}
+	if config.secondaryRegion == config.primaryRegion {
+		return errors.AssertionFailedf(
+			"secondary region %s cannot be the primary region", config.secondaryRegion)
+	}
+	if !config.IsMemberOfExplicitSuperRegion(config.primaryRegion) {
+		return nil
+	}
+	for _, region := range config.GetSuperRegionRegionsForRegion(config.primaryRegion) {
+		if region == config.secondaryRegion {
+			return nil
+		}
+	}
+	return errors.AssertionFailedf(
+		"secondary region %s must be part of the super region of primary region %s",
+		config.secondaryRegion, config.primaryRegion)
+}
+
+// ValidatePartitionSurvivalGoals validates that any per-partition survival
+// goal overrides refer to regions of the database which are not part of a
+// super region, and that the database has enough regions to satisfy them.
+func ValidatePartitionSurvivalGoals(config RegionConfig) error {
+	for region, goal := range config.partitionSurvival {
+		if !config.IsValidRegionNameString(string(region)) {
+			return errors.AssertionFailedf(
+				"survival goal configured for partition %s which is not a region of the database", region)
+		}
+		if config.IsMemberOfExplicitSuperRegion(region) {
+			return errors.AssertionFailedf(
+				"cannot override the survival goal of partition %s which is part of a super region", region)
+		}
+		if goal != descpb.SurvivalGoal_ZONE_FAILURE && config.IsPlacementRestricted() {
+			return errors.AssertionFailedf(
+				"cannot have a partition %s that is region survivable in a database with restricted placement", region)
+		}
+		if err := CanSatisfySurvivalGoal(goal, len(config.regions)); err != nil {
+			return errors.Wrapf(err, "partition %s", region)
+		}
+	}
+	return nil
+}
+
 // ValidateSuperRegions validates that:
 //   1. Region names are unique within a super region and are sorted.
 //   2. All region within a super region map to a region on the RegionConfig.
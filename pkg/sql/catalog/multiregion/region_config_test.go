@@ -44,6 +44,20 @@ func TestValidateRegionConfig(t *testing.T) {
 				"region_b",
 			}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil),
 		},
+		{
+			err: "5 regions are required for surviving two region failures",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
+				"region_a",
+				"region_b",
+				"region_c",
+				"region_d",
+			}, "region_b", descpb.SurvivalGoal_TWO_REGION_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil),
+		},
+		{
+			err: "cannot have non-voting regions in a database surviving two region failures",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b", "region_c", "region_d", "region_e", "region_f"}, "region_b", descpb.SurvivalGoal_TWO_REGION_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithNonVotingRegions(catpb.RegionNames{"region_a"})),
+		},
 		{
 			err:          "expected > 0 number of regions in the region config",
 			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil),
@@ -52,6 +66,146 @@ func TestValidateRegionConfig(t *testing.T) {
 			err:          "cannot have a database with restricted placement that is also region survivable",
 			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, validRegionEnumID, descpb.DataPlacement_RESTRICTED, nil),
 		},
+		{
+			err: "cannot constrain all voters to the primary region of a region survivable database",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithPrimaryRegionOnlyVoters()),
+		},
+		{
+			err: "cannot constrain all voters to the primary region of a database with restricted placement",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b"}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_RESTRICTED, nil,
+				multiregion.WithPrimaryRegionOnlyVoters()),
+		},
+		{
+			err: "number of extra non-voters in the primary region must be non-negative, found -1",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b"}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithExtraPrimaryRegionNonVoters(-1)),
+		},
+		{
+			err: "primary region region_b cannot be a non-voting region",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithNonVotingRegions(catpb.RegionNames{"region_b"})),
+		},
+		{
+			err: "insufficient voting regions: at least 3 regions are required for surviving a region failure",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithNonVotingRegions(catpb.RegionNames{"region_a", "region_c"})),
+		},
+		{
+			err: "cannot place extra non-voters in the primary region of a database with restricted placement",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b"}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_RESTRICTED, nil,
+				multiregion.WithExtraPrimaryRegionNonVoters(2)),
+		},
+		{
+			err: "voter weights can only be configured on a region survivable database",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b"}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithVoterWeights(map[catpb.RegionName]int32{"region_b": 3})),
+		},
+		{
+			err: "voter weight configured for region region_d which is not part of the database",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithVoterWeights(map[catpb.RegionName]int32{"region_d": 1})),
+		},
+		{
+			err: "voter regions must include the primary region region_b",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithVoterRegions(catpb.RegionNames{"region_a", "region_c"})),
+		},
+		{
+			err: "insufficient voter regions: at least 3 regions are required for surviving a region failure",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithVoterRegions(catpb.RegionNames{"region_a", "region_b"})),
+		},
+		{
+			err: "insufficient regions with default placement: at least 3 regions are required for surviving a region failure",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"}, "region_a", descpb.SurvivalGoal_REGION_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithRegionPlacements(map[catpb.RegionName]descpb.DataPlacement{
+					"region_c": descpb.DataPlacement_RESTRICTED,
+					"region_d": descpb.DataPlacement_RESTRICTED,
+				})),
+		},
+		{
+			err: "cannot override the placement of regions of a database with restricted placement",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_RESTRICTED, nil,
+				multiregion.WithRegionPlacements(map[catpb.RegionName]descpb.DataPlacement{
+					"region_b": descpb.DataPlacement_DEFAULT,
+				})),
+		},
+		{
+			err: "tie-breaker voter must be placed outside of the regions of the database",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b"}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithTieBreakerVoter("region", "region_c")),
+		},
+		{
+			err: "tie-breaker voter cannot be combined with primary region only voters, voter weights or voter regions",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b"}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithTieBreakerVoter("cloud", "gcp"), multiregion.WithPrimaryRegionOnlyVoters()),
+		},
+		{
+			err: `constraints cannot be keyed on "zone", the locality tier of the tie-breaker voter`,
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b"}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithTieBreakerVoter("zone", "gcp"), multiregion.WithConstraintKey(multiregion.VoterConstraintKind, "zone")),
+		},
+		{
+			err: "cannot prohibit the primary region region_a",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b"}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithProhibitedRegions(catpb.RegionNames{"region_a"})),
+		},
+		{
+			err: "insufficient regions which are not prohibited: at least 3 regions are required for surviving a region failure",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_a", descpb.SurvivalGoal_REGION_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithProhibitedRegions(catpb.RegionNames{"region_c"})),
+		},
+		{
+			err: "availability zones configured for region region_c which is not part of the database",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b"}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithAvailabilityZones(map[catpb.RegionName]int32{"region_c": 2})),
+		},
+		{
+			err: "number of availability zones of region region_a must be positive, found 0",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b"}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithAvailabilityZones(map[catpb.RegionName]int32{"region_a": 0})),
+		},
+		{
+			err: "storage class of the non-voting replicas must not be empty",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b"}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithNonVoterStorageClass("")),
+		},
+		{
+			err: "standby region region_c is not a region of the database",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b"}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithStandbyRegion("region_c")),
+		},
+		{
+			err: "primary region region_a cannot be the standby region",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b"}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithStandbyRegion("region_a")),
+		},
+		{
+			err: "insufficient voting regions: at least 3 regions are required for surviving a region failure",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_a", descpb.SurvivalGoal_REGION_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithStandbyRegion("region_c")),
+		},
+		{
+			err: "availability zones of the primary region can only be configured for a zone survivable database",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_a", descpb.SurvivalGoal_REGION_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithPrimaryRegionAvailabilityZones("az1", "az2", "az3")),
+		},
+		{
+			err: "availability zone of the primary region must not be empty",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b"}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithPrimaryRegionAvailabilityZones("az1", "", "az3")),
+		},
+		{
+			err: "duplicate availability zone az1 of the primary region",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b"}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithPrimaryRegionAvailabilityZones("az1", "az2", "az1")),
+		},
+		{
+			err: "at least 3 availability zones of the primary region are required to survive a zone failure, found 2",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b"}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithPrimaryRegionAvailabilityZones("az1", "az2")),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -150,6 +304,19 @@ func TestValidateSuperRegionConfig(t *testing.T) {
 				},
 			}),
 		},
+		{
+			testName: "super region names which need to be quoted are valid",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, []descpb.SuperRegion{
+				{
+					SuperRegionName: "Super Region",
+					Regions:         []catpb.RegionName{"region_a"},
+				},
+				{
+					SuperRegionName: "super_region_bc",
+					Regions:         []catpb.RegionName{"region_b", "region_c"},
+				},
+			}),
+		},
 		{
 			testName: "a super region should have at least one region",
 			err:      "no regions found within super region sr1",
@@ -160,6 +327,26 @@ func TestValidateSuperRegionConfig(t *testing.T) {
 				},
 			}),
 		},
+		{
+			testName: "a super region should not have a dangling region among valid ones",
+			err:      "region region_d not part of database",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, []descpb.SuperRegion{
+				{
+					SuperRegionName: "sr1",
+					Regions:         []catpb.RegionName{"region_a", "region_d"},
+				},
+			}),
+		},
+		{
+			testName: "a super region should not have duplicate regions among valid ones",
+			err:      "duplicate region region_c found in super region sr1",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, []descpb.SuperRegion{
+				{
+					SuperRegionName: "sr1",
+					Regions:         []catpb.RegionName{"region_a", "region_c", "region_c"},
+				},
+			}),
+		},
 		{
 			testName: "a super region should have at least three regions if the survival mode is region failure",
 			err:      "super region sr1 only has 2 region(s): at least 3 regions are required for surviving a region failure",
@@ -174,6 +361,10 @@ func TestValidateSuperRegionConfig(t *testing.T) {
 
 	for _, tc := range testCases {
 		err := multiregion.ValidateRegionConfig(tc.regionConfig)
+		if tc.err == "" {
+			require.NoError(t, err, "test %s", tc.testName)
+			continue
+		}
 
 		require.Error(t, err)
 		require.True(
@@ -186,3 +377,63 @@ func TestValidateSuperRegionConfig(t *testing.T) {
 		)
 	}
 }
+
+func TestValidateSecondaryRegion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const validRegionEnumID = 100
+
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"}
+	superRegions := []descpb.SuperRegion{
+		{
+			SuperRegionName: "sr1",
+			Regions:         []catpb.RegionName{"region_a", "region_b"},
+		},
+	}
+
+	testCases := []struct {
+		testName     string
+		err          string
+		regionConfig multiregion.RegionConfig
+	}{
+		{
+			testName: "secondary region in the super region of the primary region",
+			regionConfig: multiregion.MakeRegionConfig(regions, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, superRegions,
+				multiregion.WithSecondaryRegion("region_a")),
+		},
+		{
+			testName: "secondary region in a super region without the primary region",
+			regionConfig: multiregion.MakeRegionConfig(regions, "region_c", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, superRegions,
+				multiregion.WithSecondaryRegion("region_a")),
+		},
+		{
+			testName: "secondary region outside of the super region of the primary region",
+			err:      "secondary region region_c must be part of the super region of primary region region_b",
+			regionConfig: multiregion.MakeRegionConfig(regions, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, superRegions,
+				multiregion.WithSecondaryRegion("region_c")),
+		},
+		{
+			testName: "secondary region is the primary region",
+			err:      "secondary region region_b cannot be the primary region",
+			regionConfig: multiregion.MakeRegionConfig(regions, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithSecondaryRegion("region_b")),
+		},
+		{
+			testName: "secondary region not part of the database",
+			err:      "secondary region region_e is not a region of the database",
+			regionConfig: multiregion.MakeRegionConfig(regions, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, validRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithSecondaryRegion("region_e")),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			err := multiregion.ValidateRegionConfig(tc.regionConfig)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, testutils.IsError(err, tc.err), "expected err %v, got %v", tc.err, err)
+		})
+	}
+}
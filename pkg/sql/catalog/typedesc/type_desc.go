@@ -725,16 +725,17 @@ func (desc *immutable) validateMultiRegion(
 	if err != nil {
 		vea.Report(err)
 	}
-	if dbDesc.GetRegionConfig().SurvivalGoal == descpb.SurvivalGoal_REGION_FAILURE {
-		if len(regionNames) < 3 {
-			vea.Report(
-				errors.AssertionFailedf(
-					"expected >= 3 regions, got %d: %s",
+	if err := multiregion.CanSatisfySurvivalGoal(
+		dbDesc.GetRegionConfig().SurvivalGoal, len(regionNames),
+	); err != nil {
+		vea.Report(
+			errors.HandleAsAssertionFailure(
+				errors.Wrapf(err, "got %d regions: %s",
 					len(regionNames),
 					strings.Join(regionNames.ToStrings(), ","),
 				),
-			)
-		}
+			),
+		)
 	}
 
 	superRegions, err := desc.SuperRegions()
@@ -321,6 +321,9 @@ CREATE TABLE crdb_internal.databases (
 					case descpb.SurvivalGoal_REGION_FAILURE:
 						survivalGoal = tree.NewDString("region")
 						createNode.SurvivalGoal = tree.SurvivalGoalRegionFailure
+					case descpb.SurvivalGoal_TWO_REGION_FAILURE:
+						survivalGoal = tree.NewDString("two region")
+						createNode.SurvivalGoal = tree.SurvivalGoalTwoRegionFailure
 					default:
 						return errors.Newf("unknown survival goal: %d", db.GetRegionConfig().SurvivalGoal)
 					}
@@ -116,7 +116,8 @@ func (p *planner) CreateDatabase(ctx context.Context, n *tree.CreateDatabase) (p
 		}
 
 		if n.Placement == tree.DataPlacementRestricted &&
-			n.SurvivalGoal == tree.SurvivalGoalRegionFailure {
+			(n.SurvivalGoal == tree.SurvivalGoalRegionFailure ||
+				n.SurvivalGoal == tree.SurvivalGoalTwoRegionFailure) {
 			return nil, pgerror.New(
 				pgcode.InvalidDatabaseDefinition,
 				"PLACEMENT RESTRICTED can only be used with SURVIVE ZONE FAILURE",
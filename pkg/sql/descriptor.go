@@ -303,6 +303,8 @@ func TranslateSurvivalGoal(g tree.SurvivalGoal) (descpb.SurvivalGoal, error) {
 		return descpb.SurvivalGoal_ZONE_FAILURE, nil
 	case tree.SurvivalGoalRegionFailure:
 		return descpb.SurvivalGoal_REGION_FAILURE, nil
+	case tree.SurvivalGoalTwoRegionFailure:
+		return descpb.SurvivalGoal_TWO_REGION_FAILURE, nil
 	default:
 		return 0, errors.Newf("unknown survival goal: %d", g)
 	}
@@ -0,0 +1,319 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gcjob
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+var prioritizeLargeElementsEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.gc_job.prioritize_large_elements.enabled",
+	"if enabled, the GC passes of the GC jobs running on a node run one at a "+
+		"time, starting with the pass whose expired elements have the largest "+
+		"estimated size, and the elements of each pass are GC'd from the largest "+
+		"to the smallest, so that disk space is reclaimed as fast as possible",
+	false,
+)
+
+// maxConcurrentClearsPerNode bounds the number of GC jobs clearing data
+// concurrently on each node, rather than in the whole cluster, as the jobs
+// only share an in-memory semaphore. The cluster-wide number of such jobs is
+// thus bounded by the setting times the number of nodes running GC jobs.
+var maxConcurrentClearsPerNode = settings.RegisterIntSetting(
+	settings.TenantWritable,
+	"sql.gc_job.max_concurrent_clears_per_node",
+	"if positive, the maximum number of GC jobs which clear data concurrently "+
+		"on each node, which is not a cluster-wide limit; the other GC jobs with "+
+		"expired elements on the node persist their progress and wait until a "+
+		"slot frees up",
+	0,
+	settings.NonNegativeInt,
+)
+
+// gcSlots bounds the number of GC jobs running on this node which clear data
+// concurrently, when sql.gc_job.max_concurrent_clears_per_node is set.
+var gcSlots = &gcJobSlots{}
+
+// gcJobSlots is a counting semaphore whose limit is read on every acquisition,
+// so that changes to sql.gc_job.max_concurrent_clears_per_node apply to the
+// jobs which are already waiting.
+type gcJobSlots struct {
+	mu struct {
+		syncutil.Mutex
+		inUse int64
+		// freedC is closed, and reset, whenever a slot is released.
+		freedC chan struct{}
+	}
+}
+
+// acquire blocks until fewer than limit() slots are in use, or returns right
+// away if limit() is not positive. onWait is called once, before blocking, if
+// the caller has to wait. The returned function must be called to release the
+// slot.
+func (s *gcJobSlots) acquire(
+	ctx context.Context, limit func() int64, onWait func(),
+) (release func(), _ error) {
+	waited := false
+	for {
+		s.mu.Lock()
+		if l := limit(); l <= 0 || s.mu.inUse < l {
+			s.mu.inUse++
+			s.mu.Unlock()
+			return s.release, nil
+		}
+		if s.mu.freedC == nil {
+			s.mu.freedC = make(chan struct{})
+		}
+		freedC := s.mu.freedC
+		s.mu.Unlock()
+
+		if !waited {
+			waited = true
+			onWait()
+		}
+		select {
+		case <-freedC:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (s *gcJobSlots) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.inUse--
+	if s.mu.freedC != nil {
+		close(s.mu.freedC)
+		s.mu.freedC = nil
+	}
+}
+
+// gcAdmission orders the GC passes of the GC jobs running on this node when
+// sql.gc_job.prioritize_large_elements.enabled is set.
+var gcAdmission = &gcAdmissionQueue{}
+
+// gcAdmissionQueue admits GC passes one at a time. When a pass completes, the
+// waiting pass with the largest estimated size is admitted next.
+type gcAdmissionQueue struct {
+	mu struct {
+		syncutil.Mutex
+		// admitted is set while an admitted pass is running.
+		admitted bool
+		waiters  []*gcAdmissionWaiter
+	}
+}
+
+type gcAdmissionWaiter struct {
+	sizeBytes int64
+	admittedC chan struct{}
+}
+
+// admit blocks until a GC pass of the given estimated size may run. The
+// returned function must be called once the pass completes.
+func (q *gcAdmissionQueue) admit(ctx context.Context, sizeBytes int64) (release func(), _ error) {
+	q.mu.Lock()
+	if !q.mu.admitted {
+		q.mu.admitted = true
+		q.mu.Unlock()
+		return q.release, nil
+	}
+	w := &gcAdmissionWaiter{sizeBytes: sizeBytes, admittedC: make(chan struct{})}
+	q.mu.waiters = append(q.mu.waiters, w)
+	q.mu.Unlock()
+
+	select {
+	case <-w.admittedC:
+		return q.release, nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		for i := range q.mu.waiters {
+			if q.mu.waiters[i] == w {
+				q.mu.waiters = append(q.mu.waiters[:i], q.mu.waiters[i+1:]...)
+				return nil, ctx.Err()
+			}
+		}
+		// The pass was admitted concurrently with the cancellation. Hand the
+		// admission over to the next waiter.
+		q.releaseLocked()
+		return nil, ctx.Err()
+	}
+}
+
+func (q *gcAdmissionQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.releaseLocked()
+}
+
+func (q *gcAdmissionQueue) releaseLocked() {
+	if len(q.mu.waiters) == 0 {
+		q.mu.admitted = false
+		return
+	}
+	// Admit the largest waiting pass. Ties go to the pass which waited the
+	// longest.
+	next := 0
+	for i, w := range q.mu.waiters {
+		if w.sizeBytes > q.mu.waiters[next].sizeBytes {
+			next = i
+		}
+	}
+	w := q.mu.waiters[next]
+	q.mu.waiters = append(q.mu.waiters[:next], q.mu.waiters[next+1:]...)
+	close(w.admittedC)
+}
+
+// performGCWithAdmission runs a GC pass. If
+// sql.gc_job.prioritize_large_elements.enabled is set, the pass first waits
+// for its turn in gcAdmission, based on the estimated size of the elements it
+// is about to GC.
+func performGCWithAdmission(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	jobID jobspb.JobID,
+	details *jobspb.SchemaChangeGCDetails,
+	progress *jobspb.SchemaChangeGCProgress,
+) (deferred bool, _ error) {
+	if prioritizeLargeElementsEnabled.Get(&execCfg.Settings.SV) {
+		release, err := gcAdmission.admit(ctx, expiredSizeBytes(details, progress))
+		if err != nil {
+			return false, err
+		}
+		defer release()
+	}
+	return performGCWithWatchdog(ctx, execCfg, jobID, details, progress)
+}
+
+// maybeEstimateElementSizes sets the estimated size of the dropped tables and
+// indexes of the job whose size is not known yet, from the MVCC stats of the
+// ranges under their prefixes, and persists them in the job details. It only
+// does so when sql.gc_job.prioritize_large_elements.enabled is set, as the
+// estimates are only used to order the GC passes, and only in the system
+// tenant, which can read the stats of the ranges. The ranges are expected to
+// still be split off from the live data, so the estimates are not taken once
+// they have been unsplit. The estimates are best effort: a failure to compute
+// one is logged and leaves it unset.
+func maybeEstimateElementSizes(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	jobID jobspb.JobID,
+	details *jobspb.SchemaChangeGCDetails,
+	progress *jobspb.SchemaChangeGCProgress,
+) error {
+	if !prioritizeLargeElementsEnabled.Get(&execCfg.Settings.SV) ||
+		!execCfg.Codec.ForSystemTenant() || progress.RangesUnsplitDone {
+		return nil
+	}
+	var updated bool
+	estimate := func(prefix roachpb.Key, sizeBytes *int64) {
+		if *sizeBytes != 0 {
+			return
+		}
+		size, err := spanSizeBytes(ctx, execCfg, roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()})
+		if err != nil {
+			log.Warningf(ctx, "estimating size of span %s, err = %+v", prefix, err)
+			return
+		}
+		*sizeBytes = size
+		updated = updated || size != 0
+	}
+	for i := range details.Tables {
+		table := &details.Tables[i]
+		estimate(execCfg.Codec.TablePrefix(uint32(tableDataID(details, table.ID))), &table.EstimatedSizeBytes)
+	}
+	for i := range details.Indexes {
+		index := &details.Indexes[i]
+		estimate(execCfg.Codec.IndexPrefix(uint32(details.ParentID), uint32(index.IndexID)), &index.EstimatedSizeBytes)
+	}
+	if !updated {
+		return nil
+	}
+	return execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		job, err := execCfg.JobRegistry.LoadJobWithTxn(ctx, jobID, txn)
+		if err != nil {
+			return err
+		}
+		return job.SetDetails(ctx, txn, *details)
+	})
+}
+
+// expiredSizeBytes returns the estimated size of the elements of the job
+// which are in the DELETING state.
+func expiredSizeBytes(
+	details *jobspb.SchemaChangeGCDetails, progress *jobspb.SchemaChangeGCProgress,
+) (sizeBytes int64) {
+	for _, table := range progress.Tables {
+		if table.Status == jobspb.SchemaChangeGCProgress_DELETING {
+			sizeBytes += tableSizeBytes(details, table.ID)
+		}
+	}
+	for _, index := range progress.Indexes {
+		if index.Status == jobspb.SchemaChangeGCProgress_DELETING {
+			sizeBytes += indexSizeBytes(details, index.IndexID)
+		}
+	}
+	return sizeBytes
+}
+
+// tableSizeBytes returns the estimated size of the specified dropped table,
+// or zero if it is unknown.
+func tableSizeBytes(details *jobspb.SchemaChangeGCDetails, tableID descpb.ID) int64 {
+	for _, table := range details.Tables {
+		if table.ID == tableID {
+			return table.EstimatedSizeBytes
+		}
+	}
+	return 0
+}
+
+// indexSizeBytes returns the estimated size of the specified dropped index,
+// or zero if it is unknown.
+func indexSizeBytes(details *jobspb.SchemaChangeGCDetails, indexID descpb.IndexID) int64 {
+	for _, index := range details.Indexes {
+		if index.IndexID == indexID {
+			return index.EstimatedSizeBytes
+		}
+	}
+	return 0
+}
+
+// tablesBySize returns a copy of the given tables ordered from the largest
+// estimated size to the smallest. Tables of equal size keep their order.
+func tablesBySize(
+	details *jobspb.SchemaChangeGCDetails, tables []jobspb.SchemaChangeGCProgress_TableProgress,
+) []jobspb.SchemaChangeGCProgress_TableProgress {
+	sorted := append([]jobspb.SchemaChangeGCProgress_TableProgress(nil), tables...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return tableSizeBytes(details, sorted[i].ID) > tableSizeBytes(details, sorted[j].ID)
+	})
+	return sorted
+}
+
+// sortIndexIDsBySize orders the given indexes from the largest estimated size
+// to the smallest. Indexes of equal size keep their order.
+func sortIndexIDsBySize(details *jobspb.SchemaChangeGCDetails, indexIDs []descpb.IndexID) {
+	sort.SliceStable(indexIDs, func(i, j int) bool {
+		return indexSizeBytes(details, indexIDs[i]) > indexSizeBytes(details, indexIDs[j])
+	})
+}
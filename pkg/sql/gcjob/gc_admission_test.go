@@ -0,0 +1,209 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gcjob
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCAdmissionQueueAdmitsLargestFirst(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	var q gcAdmissionQueue
+	waitForWaiters := func(n int) {
+		testutils.SucceedsSoon(t, func() error {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			if len(q.mu.waiters) != n {
+				return errors.Newf("expected %d waiters, found %d", n, len(q.mu.waiters))
+			}
+			return nil
+		})
+	}
+
+	// The first pass is admitted right away and holds up the others.
+	release, err := q.admit(ctx, 1)
+	require.NoError(t, err)
+
+	admittedC := make(chan int64)
+	sizes := []int64{10, 1000, 100}
+	for i, size := range sizes {
+		size := size
+		go func() {
+			release, err := q.admit(ctx, size)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			admittedC <- size
+			release()
+		}()
+		waitForWaiters(i + 1)
+	}
+
+	// A canceled waiter gives up its place in the queue.
+	cancelCtx, cancel := context.WithCancel(ctx)
+	errC := make(chan error)
+	go func() {
+		_, err := q.admit(cancelCtx, 10000)
+		errC <- err
+	}()
+	waitForWaiters(len(sizes) + 1)
+	cancel()
+	require.ErrorIs(t, <-errC, context.Canceled)
+	waitForWaiters(len(sizes))
+
+	release()
+	for _, expected := range []int64{1000, 100, 10} {
+		require.Equal(t, expected, <-admittedC)
+	}
+
+	// Once all passes completed, the next one is admitted right away.
+	release, err = q.admit(ctx, 1)
+	require.NoError(t, err)
+	release()
+}
+
+func TestGCJobSlotsBoundConcurrentClears(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	var s gcJobSlots
+	var limit int64 = 2
+	limitFn := func() int64 { return atomic.LoadInt64(&limit) }
+
+	const numJobs = 5
+	var running, maxRunning, waited int64
+	proceedC := make(chan struct{})
+	admittedC := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < numJobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := s.acquire(ctx, limitFn, func() {
+				atomic.AddInt64(&waited, 1)
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			n := atomic.AddInt64(&running, 1)
+			for {
+				m := atomic.LoadInt64(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt64(&maxRunning, m, n) {
+					break
+				}
+			}
+			admittedC <- struct{}{}
+			<-proceedC
+			atomic.AddInt64(&running, -1)
+			release()
+		}()
+	}
+
+	// Only two jobs clear at a time; the others wait for a slot.
+	<-admittedC
+	<-admittedC
+	testutils.SucceedsSoon(t, func() error {
+		if w := atomic.LoadInt64(&waited); w != numJobs-2 {
+			return errors.Newf("expected %d waiting jobs, found %d", numJobs-2, w)
+		}
+		return nil
+	})
+	select {
+	case <-admittedC:
+		t.Fatal("unexpected admission while no slot is free")
+	default:
+	}
+
+	// Each job which finishes lets a waiting job in.
+	for i := 2; i < numJobs; i++ {
+		proceedC <- struct{}{}
+		<-admittedC
+	}
+	close(proceedC)
+	wg.Wait()
+	require.Equal(t, int64(2), atomic.LoadInt64(&maxRunning))
+
+	// A canceled waiter gives up, and disabling the limit lets jobs through.
+	release, err := s.acquire(ctx, limitFn, func() {})
+	require.NoError(t, err)
+	atomic.StoreInt64(&limit, 1)
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = s.acquire(cancelCtx, limitFn, func() {})
+	require.ErrorIs(t, err, context.Canceled)
+	atomic.StoreInt64(&limit, 0)
+	release2, err := s.acquire(ctx, limitFn, func() { t.Error("unexpected wait") })
+	require.NoError(t, err)
+	release2()
+	release()
+}
+
+func TestOrderElementsBySize(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	details := &jobspb.SchemaChangeGCDetails{
+		Indexes: []jobspb.SchemaChangeGCDetails_DroppedIndex{
+			{IndexID: 2, EstimatedSizeBytes: 10},
+			{IndexID: 3, EstimatedSizeBytes: 300},
+			{IndexID: 4},
+			{IndexID: 5, EstimatedSizeBytes: 20},
+		},
+		Tables: []jobspb.SchemaChangeGCDetails_DroppedID{
+			{ID: 100, EstimatedSizeBytes: 5},
+			{ID: 101},
+			{ID: 102, EstimatedSizeBytes: 500},
+		},
+	}
+	progress := &jobspb.SchemaChangeGCProgress{
+		Indexes: []jobspb.SchemaChangeGCProgress_IndexProgress{
+			{IndexID: 2, Status: jobspb.SchemaChangeGCProgress_DELETING},
+			{IndexID: 3, Status: jobspb.SchemaChangeGCProgress_WAITING_FOR_GC},
+			{IndexID: 5, Status: jobspb.SchemaChangeGCProgress_DELETING},
+		},
+		Tables: []jobspb.SchemaChangeGCProgress_TableProgress{
+			{ID: 100, Status: jobspb.SchemaChangeGCProgress_DELETING},
+			{ID: 101, Status: jobspb.SchemaChangeGCProgress_DELETING},
+			{ID: 102, Status: jobspb.SchemaChangeGCProgress_DELETED},
+		},
+	}
+
+	// Only the elements being deleted count towards the size of a pass.
+	require.Equal(t, int64(35), expiredSizeBytes(details, progress))
+
+	indexIDs := []descpb.IndexID{2, 3, 4, 5}
+	sortIndexIDsBySize(details, indexIDs)
+	require.Equal(t, []descpb.IndexID{3, 5, 2, 4}, indexIDs)
+
+	var tableIDs []descpb.ID
+	for _, table := range tablesBySize(details, progress.Tables) {
+		tableIDs = append(tableIDs, table.ID)
+	}
+	require.Equal(t, []descpb.ID{102, 100, 101}, tableIDs)
+	// The progress of the job is left untouched.
+	require.Equal(t, descpb.ID(100), progress.Tables[0].ID)
+}
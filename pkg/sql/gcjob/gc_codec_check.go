@@ -0,0 +1,53 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gcjob
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/errors"
+)
+
+// The synthetic dropped table and index used to check the codec of the GC
+// job. They do not need to exist.
+const (
+	codecCheckTableID descpb.ID      = 1 << 20
+	codecCheckIndexID descpb.IndexID = 2
+)
+
+// verifyCodecPrefixes checks that the prefixes which the GC job computes with
+// codec to clear and unsplit the spans of dropped tables and indexes lie in
+// the keyspace of the given tenant. A misconfigured codec would otherwise have
+// the job target the data of another tenant.
+func verifyCodecPrefixes(codec keys.SQLCodec, tenantID roachpb.TenantID) error {
+	expectedTablePrefix := roachpb.Key(encoding.EncodeUvarintAscending(
+		keys.MakeTenantPrefix(tenantID), uint64(codecCheckTableID),
+	))
+	if tablePrefix := codec.TablePrefix(uint32(codecCheckTableID)); !tablePrefix.Equal(expectedTablePrefix) {
+		return errors.AssertionFailedf(
+			"codec computes the prefix %s for table %d, expected %s for tenant %s",
+			tablePrefix, codecCheckTableID, expectedTablePrefix, tenantID,
+		)
+	}
+	expectedIndexPrefix := roachpb.Key(encoding.EncodeUvarintAscending(
+		expectedTablePrefix.Clone(), uint64(codecCheckIndexID),
+	))
+	indexPrefix := codec.IndexPrefix(uint32(codecCheckTableID), uint32(codecCheckIndexID))
+	if !indexPrefix.Equal(expectedIndexPrefix) {
+		return errors.AssertionFailedf(
+			"codec computes the prefix %s for index %d of table %d, expected %s for tenant %s",
+			indexPrefix, codecCheckIndexID, codecCheckTableID, expectedIndexPrefix, tenantID,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gcjob
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyCodecPrefixes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tenant10 := roachpb.MakeTenantID(10)
+	tenant11 := roachpb.MakeTenantID(11)
+
+	for _, tc := range []struct {
+		desc     string
+		codec    keys.SQLCodec
+		tenantID roachpb.TenantID
+		ok       bool
+	}{
+		{"system tenant", keys.SystemSQLCodec, roachpb.SystemTenantID, true},
+		{"secondary tenant", keys.MakeSQLCodec(tenant10), tenant10, true},
+		{"system codec for secondary tenant", keys.SystemSQLCodec, tenant10, false},
+		{"tenant codec for system tenant", keys.MakeSQLCodec(tenant10), roachpb.SystemTenantID, false},
+		{"codec of another tenant", keys.MakeSQLCodec(tenant11), tenant10, false},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := verifyCodecPrefixes(tc.codec, tc.tenantID)
+			if tc.ok {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.True(t, errors.HasAssertionFailure(err), "%+v", err)
+			require.Contains(t, err.Error(), "for tenant "+tc.tenantID.String())
+		})
+	}
+}
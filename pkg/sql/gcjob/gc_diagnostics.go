@@ -0,0 +1,222 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gcjob
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// ElementGCStatus describes why a table or index waiting for GC is, or is
+// not yet, eligible for GC.
+type ElementGCStatus struct {
+	// ID is the ID of the table, or of the table of the index.
+	ID descpb.ID
+	// IndexID is the ID of the index, or 0 for a table.
+	IndexID descpb.IndexID
+	// DropTime is the time from which the GC TTL is measured. For a table, it
+	// is the time of the last reference to the table if it was referenced
+	// after it was dropped.
+	DropTime time.Time
+	// TTL is the GC TTL of the element, as configured by its zone config.
+	TTL time.Duration
+	// Deadline is the time at which the element becomes eligible for GC.
+	Deadline time.Time
+	// Protected is true if a protected timestamp delays the GC of the element.
+	Protected bool
+	// Expired is true if the element is eligible for GC, i.e. its deadline
+	// has passed and it is not protected.
+	Expired bool
+}
+
+// DescribeGCStatus returns the status of each table and index of a GC job
+// with the given details and progress which is waiting for GC, as computed by
+// refreshTables. Unlike refreshTables, it does not update the progress, nor
+// release stale protected timestamps. Tables whose descriptor is missing are
+// omitted, as they were GC'd by another job.
+func DescribeGCStatus(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	jobID jobspb.JobID,
+	details *jobspb.SchemaChangeGCDetails,
+	progress *jobspb.SchemaChangeGCProgress,
+) ([]ElementGCStatus, error) {
+	tableDropTimes, indexDropTimes := getDropTimes(details)
+	tableExpirationBases := getTableExpirationBases(details)
+	var ret []ElementGCStatus
+	for _, tableID := range getAllTablesWaitingForGC(details, progress) {
+		var statuses []ElementGCStatus
+		if err := sql.DescsTxn(ctx, execCfg, func(ctx context.Context, txn *kv.Txn, col *descs.Collection) error {
+			statuses = nil
+			table, err := col.Direct().MustGetTableDescByID(ctx, txn, tableID)
+			if err != nil {
+				return err
+			}
+			zoneCfg, tableTTL, err := getTableZoneConfigAndTTL(execCfg, tableID)
+			if err != nil {
+				return err
+			}
+
+			if table.Dropped() {
+				for _, t := range progress.Tables {
+					if t.ID != tableID || t.Status == jobspb.SchemaChangeGCProgress_DELETED {
+						continue
+					}
+					deadline, protected, err := tableGCStatus(
+						ctx, execCfg, jobID, int64(tableTTL), table, tableDropTimes, tableExpirationBases,
+					)
+					if err != nil {
+						return err
+					}
+					statuses = append(statuses, ElementGCStatus{
+						ID:        tableID,
+						DropTime:  timeutil.Unix(0, tableExpirationBases[tableID]),
+						TTL:       time.Duration(tableTTL) * time.Second,
+						Deadline:  deadline,
+						Protected: protected,
+						Expired:   !protected && timeutil.Until(deadline) < 0,
+					})
+					break
+				}
+			}
+
+			for _, idx := range progress.Indexes {
+				if idx.Status == jobspb.SchemaChangeGCProgress_DELETED {
+					continue
+				}
+				ttlSeconds, deadline, protected, err := indexGCStatus(
+					ctx, execCfg, jobID, tableTTL, table, execCfg.ProtectedTimestampProvider,
+					zoneCfg, idx.IndexID, indexDropTimes,
+				)
+				if err != nil {
+					return err
+				}
+				statuses = append(statuses, ElementGCStatus{
+					ID:        tableID,
+					IndexID:   idx.IndexID,
+					DropTime:  timeutil.Unix(0, indexDropTimes[idx.IndexID]),
+					TTL:       time.Duration(ttlSeconds) * time.Second,
+					Deadline:  deadline,
+					Protected: protected,
+					Expired:   !protected && timeutil.Until(deadline) < 0,
+				})
+			}
+			return nil
+		}); err != nil {
+			if errors.Is(err, catalog.ErrDescriptorNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		ret = append(ret, statuses...)
+	}
+	return ret, nil
+}
+
+// estimatedClearBytesPerSecond is the rate at which the GC job is assumed to
+// clear the data of the elements it GCs, when estimating how long it takes.
+const estimatedClearBytesPerSecond = 64 << 20 // 64 MiB
+
+// EstimateConfidence indicates how reliable an estimate returned by
+// EstimateTimeToCompletion is.
+type EstimateConfidence int
+
+const (
+	// EstimateConfidenceHigh means that the deadlines and sizes of all of the
+	// pending elements are known.
+	EstimateConfidenceHigh EstimateConfidence = iota
+	// EstimateConfidenceLow means that a protected timestamp delays the GC of
+	// an element by an unknown amount of time, or that the size of an element
+	// is unknown.
+	EstimateConfidenceLow
+)
+
+// String implements the fmt.Stringer interface.
+func (c EstimateConfidence) String() string {
+	switch c {
+	case EstimateConfidenceHigh:
+		return "high"
+	case EstimateConfidenceLow:
+		return "low"
+	default:
+		return "unknown"
+	}
+}
+
+// EstimateTimeToCompletion estimates the time until a GC job with the given
+// details and progress completes: the time until the GC TTL of the last of
+// its pending elements expires, as computed by DescribeGCStatus, plus the
+// time it takes to clear the estimated size of the elements which are yet to
+// be cleared. The estimate is for display purposes only.
+func EstimateTimeToCompletion(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	jobID jobspb.JobID,
+	details *jobspb.SchemaChangeGCDetails,
+	progress *jobspb.SchemaChangeGCProgress,
+) (time.Duration, EstimateConfidence, error) {
+	statuses, err := DescribeGCStatus(ctx, execCfg, jobID, details, progress)
+	if err != nil {
+		return 0, EstimateConfidenceLow, err
+	}
+	confidence := EstimateConfidenceHigh
+	var untilExpired time.Duration
+	for _, s := range statuses {
+		if s.Protected {
+			confidence = EstimateConfidenceLow
+		}
+		if d := timeutil.Until(s.Deadline); d > untilExpired {
+			untilExpired = d
+		}
+	}
+	sizeBytes, sizeUnknown := pendingSizeBytes(details, progress)
+	if sizeUnknown {
+		confidence = EstimateConfidenceLow
+	}
+	clearing := time.Duration(float64(sizeBytes) / estimatedClearBytesPerSecond * float64(time.Second))
+	return untilExpired + clearing, confidence, nil
+}
+
+// pendingSizeBytes returns the estimated size of the elements of the job
+// whose data is yet to be cleared, as recorded in its details by
+// maybeEstimateElementSizes, and whether the size of any of them is unknown.
+func pendingSizeBytes(
+	details *jobspb.SchemaChangeGCDetails, progress *jobspb.SchemaChangeGCProgress,
+) (sizeBytes int64, unknown bool) {
+	pending := func(status jobspb.SchemaChangeGCProgress_Status) bool {
+		return status == jobspb.SchemaChangeGCProgress_WAITING_FOR_GC ||
+			status == jobspb.SchemaChangeGCProgress_DELETING
+	}
+	for _, table := range progress.Tables {
+		if pending(table.Status) {
+			size := tableSizeBytes(details, table.ID)
+			sizeBytes += size
+			unknown = unknown || size == 0
+		}
+	}
+	for _, index := range progress.Indexes {
+		if pending(index.Status) {
+			size := indexSizeBytes(details, index.IndexID)
+			sizeBytes += size
+			unknown = unknown || size == 0
+		}
+	}
+	return sizeBytes, unknown
+}
@@ -0,0 +1,185 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gcjob
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDescribeGCStatus ensures that the status reported for each element
+// waiting for GC matches the decision of refreshTables.
+func TestDescribeGCStatus(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			JobsTestingKnobs: jobs.NewTestingKnobsWithShortIntervals(),
+		},
+	})
+	defer srv.Stopper().Stop(ctx)
+	execCfg := srv.ExecutorConfig().(sql.ExecutorConfig)
+	tdb := sqlutils.MakeSQLRunner(db)
+
+	tdb.Exec(t, "SET use_declarative_schema_changer = 'off'")
+	tdb.Exec(t, "CREATE DATABASE db")
+	tdb.Exec(t, "CREATE TABLE db.t1 (i INT PRIMARY KEY)")
+	tdb.Exec(t, "CREATE TABLE db.t2 (i INT PRIMARY KEY)")
+	tdb.Exec(t, "ALTER TABLE db.t1 CONFIGURE ZONE USING gc.ttlseconds = 3600")
+	tdb.Exec(t, "ALTER TABLE db.t2 CONFIGURE ZONE USING gc.ttlseconds = 3600")
+	var t1ID, t2ID descpb.ID
+	tdb.QueryRow(t, "SELECT 'db.t1'::REGCLASS::INT").Scan(&t1ID)
+	tdb.QueryRow(t, "SELECT 'db.t2'::REGCLASS::INT").Scan(&t2ID)
+	// The tables are left in the DROP state, since the GC TTL of the job
+	// created by the DROP has not expired.
+	tdb.Exec(t, "DROP DATABASE db CASCADE")
+
+	// Only the first table was dropped long enough ago for its TTL to have
+	// expired.
+	now := timeutil.Now()
+	details := &jobspb.SchemaChangeGCDetails{
+		Tables: []jobspb.SchemaChangeGCDetails_DroppedID{
+			{ID: t1ID, DropTime: now.Add(-2 * time.Hour).UnixNano()},
+			{ID: t2ID, DropTime: now.UnixNano()},
+		},
+	}
+	newProgress := func() *jobspb.SchemaChangeGCProgress {
+		return &jobspb.SchemaChangeGCProgress{
+			Tables: []jobspb.SchemaChangeGCProgress_TableProgress{
+				{ID: t1ID, Status: jobspb.SchemaChangeGCProgress_WAITING_FOR_GC},
+				{ID: t2ID, Status: jobspb.SchemaChangeGCProgress_WAITING_FOR_GC},
+			},
+		}
+	}
+
+	// Wait for the zone configs to be reflected in the system config.
+	var statuses []ElementGCStatus
+	testutils.SucceedsSoon(t, func() error {
+		var err error
+		statuses, err = DescribeGCStatus(ctx, &execCfg, jobspb.InvalidJobID, details, newProgress())
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			if s.TTL != time.Hour {
+				return errors.Newf("table %d has TTL %s", s.ID, s.TTL)
+			}
+		}
+		return nil
+	})
+	require.Len(t, statuses, 2)
+	for i, s := range statuses {
+		dropTime := timeutil.Unix(0, details.Tables[i].DropTime)
+		require.Equal(t, details.Tables[i].ID, s.ID)
+		require.Equal(t, descpb.IndexID(0), s.IndexID)
+		require.True(t, dropTime.Equal(s.DropTime))
+		require.True(t, dropTime.Add(time.Hour).Equal(s.Deadline))
+		require.False(t, s.Protected)
+	}
+	require.True(t, statuses[0].Expired)
+	require.False(t, statuses[1].Expired)
+
+	// The GC job only marks the expired table for deletion.
+	progress := newProgress()
+	tableDropTimes, indexDropTimes := getDropTimes(details)
+	expired, _ := refreshTables(
+		ctx, &execCfg, []descpb.ID{t1ID, t2ID}, tableDropTimes, getTableExpirationBases(details),
+		indexDropTimes, jobspb.InvalidJobID, progress,
+	)
+	require.True(t, expired)
+	for i, s := range statuses {
+		require.Equal(t, s.Expired, progress.Tables[i].Status == jobspb.SchemaChangeGCProgress_DELETING)
+	}
+}
+
+// TestEstimateTimeToCompletion ensures that the estimated time to completion
+// of a GC job accounts for the time until the GC TTL of its pending elements
+// expires and for the time it takes to clear them.
+func TestEstimateTimeToCompletion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			JobsTestingKnobs: jobs.NewTestingKnobsWithShortIntervals(),
+		},
+	})
+	defer srv.Stopper().Stop(ctx)
+	execCfg := srv.ExecutorConfig().(sql.ExecutorConfig)
+	tdb := sqlutils.MakeSQLRunner(db)
+
+	tdb.Exec(t, "SET use_declarative_schema_changer = 'off'")
+	tdb.Exec(t, "CREATE DATABASE db")
+	tdb.Exec(t, "CREATE TABLE db.t (i INT PRIMARY KEY)")
+	tdb.Exec(t, "ALTER TABLE db.t CONFIGURE ZONE USING gc.ttlseconds = 3600")
+	var tableID descpb.ID
+	tdb.QueryRow(t, "SELECT 'db.t'::REGCLASS::INT").Scan(&tableID)
+	tdb.Exec(t, "DROP TABLE db.t")
+
+	// The table was dropped half an hour ago, and takes two seconds to clear.
+	dropTime := timeutil.Now().Add(-30 * time.Minute)
+	details := &jobspb.SchemaChangeGCDetails{
+		Tables: []jobspb.SchemaChangeGCDetails_DroppedID{
+			{ID: tableID, DropTime: dropTime.UnixNano(), EstimatedSizeBytes: 2 * estimatedClearBytesPerSecond},
+		},
+	}
+	progress := &jobspb.SchemaChangeGCProgress{
+		Tables: []jobspb.SchemaChangeGCProgress_TableProgress{
+			{ID: tableID, Status: jobspb.SchemaChangeGCProgress_WAITING_FOR_GC},
+		},
+	}
+
+	// Wait for the zone config to be reflected in the system config.
+	var estimate time.Duration
+	var confidence EstimateConfidence
+	testutils.SucceedsSoon(t, func() error {
+		var err error
+		before := timeutil.Now()
+		estimate, confidence, err = EstimateTimeToCompletion(ctx, &execCfg, jobspb.InvalidJobID, details, progress)
+		if err != nil {
+			return err
+		}
+		after := timeutil.Now()
+		// The TTL expires an hour after the drop.
+		upper := dropTime.Add(time.Hour).Sub(before) + 2*time.Second
+		lower := dropTime.Add(time.Hour).Sub(after) + 2*time.Second
+		if estimate < lower || estimate > upper {
+			return errors.Newf("estimate %s not in [%s, %s]", estimate, lower, upper)
+		}
+		return nil
+	})
+	require.Equal(t, EstimateConfidenceHigh, confidence)
+
+	// The estimate is less reliable if the size of the table is unknown.
+	details.Tables[0].EstimatedSizeBytes = 0
+	estimate, confidence, err := EstimateTimeToCompletion(ctx, &execCfg, jobspb.InvalidJobID, details, progress)
+	require.NoError(t, err)
+	require.Equal(t, EstimateConfidenceLow, confidence)
+	require.LessOrEqual(t, estimate, 30*time.Minute)
+}
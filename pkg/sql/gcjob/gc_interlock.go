@@ -0,0 +1,62 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gcjob
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// destructiveOpsEnabled is a safety interlock for incident response. When it
+// is disabled, GC jobs neither clear the data of the dropped elements nor
+// unsplit their ranges, and stay parked until it is enabled again. It is set
+// by the operator of the cluster and applies to the GC jobs of all tenants.
+var destructiveOpsEnabled = settings.RegisterBoolSetting(
+	settings.TenantReadOnly,
+	"kv.background_gc.destructive_ops.enabled",
+	"if disabled, GC jobs do not clear the data of dropped tables, indexes and "+
+		"tenants nor unsplit their ranges, and wait until it is enabled again",
+	true,
+)
+
+// waitForDestructiveOps blocks until destructive operations are enabled by
+// the kv.background_gc.destructive_ops.enabled setting, checking it again
+// every MaxSQLGCInterval. The progress is persisted before blocking so that the
+// job reports why it is not making progress.
+func waitForDestructiveOps(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	jobID jobspb.JobID,
+	progress *jobspb.SchemaChangeGCProgress,
+) error {
+	if destructiveOpsEnabled.Get(&execCfg.Settings.SV) {
+		return nil
+	}
+	log.Infof(ctx, "destructive operations are disabled, GC job %d is waiting to unsplit ranges", jobID)
+	persistProgress(ctx, execCfg, jobID, progress, sql.RunningStatusWaitingForDestructiveOps)
+	timer := timeutil.NewTimer()
+	defer timer.Stop()
+	for !destructiveOpsEnabled.Get(&execCfg.Settings.SV) {
+		timer.Reset(MaxSQLGCInterval)
+		select {
+		case <-timer.C:
+			timer.Read = true
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
@@ -17,13 +17,20 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/logtags"
 )
 
 var (
@@ -32,6 +39,83 @@ var (
 	MaxSQLGCInterval = 5 * time.Minute
 )
 
+var deleteDatabaseZoneConfigEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.gc_job.delete_database_zone_config.enabled",
+	"if enabled, the GC job deletes the zone config of a dropped database once "+
+		"all of its tables have been garbage collected; disable this if database "+
+		"zone configs are managed externally",
+	true,
+)
+
+var hotIndexQPSThreshold = settings.RegisterFloatSetting(
+	settings.TenantWritable,
+	"sql.gc_job.hot_index_qps_threshold",
+	"if positive, the GC job defers clearing a dropped index while the ranges "+
+		"of the index serve more than this many queries per second, and checks "+
+		"the index again later; an index whose ranges are merged with other data "+
+		"is not deferred",
+	0,
+	settings.NonNegativeFloat,
+)
+
+// hotIndexMaxDeferral bounds how long the GC of a hot index is deferred, so
+// that an index which keeps being queried, e.g. by a client which wasn't
+// updated after the index was dropped, is eventually GC'd.
+var hotIndexMaxDeferral = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.gc_job.hot_index_max_deferral",
+	"the maximum amount of time for which the GC job defers clearing a dropped "+
+		"index because it is hot, after which the index is cleared regardless (0 "+
+		"disables the limit)",
+	time.Hour,
+	settings.NonNegativeDuration,
+)
+
+var coalesceIndexClearRangeEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.gc_job.coalesce_index_clear_range.enabled",
+	"if enabled, the GC job clears the data of dropped indexes of a table whose "+
+		"spans are adjacent with a single ClearRange over their combined span",
+	false,
+)
+
+var sortIndexesByIDEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.gc_job.sort_indexes_by_id.enabled",
+	"if enabled, the GC job clears the dropped indexes of a table in ascending "+
+		"index ID order rather than in the order they were dropped",
+	true,
+)
+
+var unsplitAfterClearEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.gc_job.unsplit_after_clear.enabled",
+	"if enabled, the GC job unsplits the ranges of dropped tables and indexes "+
+		"once all of their data has been cleared, rather than before clearing it, "+
+		"so that no merged range holds the data of several dropped elements",
+	false,
+)
+
+var lockDescriptorForDeleteEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.gc_job.lock_descriptor_for_delete.enabled",
+	"if enabled, once the GC job has cleared the data of a dropped table, it "+
+		"locks the descriptor of the table and only deletes it if it was not "+
+		"rewritten while the data was cleared, so that a descriptor written "+
+		"under the ID of the table in the meantime is left in place",
+	false,
+)
+
+var gcTablesBeforeIndexesEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.gc_job.gc_tables_before_indexes.enabled",
+	"if enabled, a GC job which drops both tables and indexes clears the data "+
+		"of the tables before that of the indexes; by default the indexes are "+
+		"cleared first, which reduces the peak disk usage of the job",
+	false,
+)
+
 // SetSmallMaxGCIntervalForTest sets the MaxSQLGCInterval and then returns a closure
 // that resets it.
 // This is to be used in tests like:
@@ -49,28 +133,48 @@ type schemaChangeGCResumer struct {
 }
 
 // performGC GCs any schema elements that are in the DELETING state and returns
-// a bool indicating if it GC'd any elements.
+// a bool indicating if the GC of any of them was deferred because they were
+// still hot.
 func performGC(
 	ctx context.Context,
 	execCfg *sql.ExecutorConfig,
 	details *jobspb.SchemaChangeGCDetails,
 	progress *jobspb.SchemaChangeGCProgress,
-) error {
+) (deferred bool, _ error) {
+	// Keep the job parked while destructive operations are disabled. The
+	// elements stay in the DELETING state, so that they are GC'd on a later
+	// pass.
+	if !destructiveOpsEnabled.Get(&execCfg.Settings.SV) {
+		log.Infof(ctx, "destructive operations are disabled, skipping the clearing of the GC'd elements")
+		return true, nil
+	}
 	if details.Tenant != nil {
-		return errors.Wrapf(
+		return false, errors.Wrapf(
 			gcTenant(ctx, execCfg, details.Tenant.ID, progress),
 			"attempting to GC tenant %+v", details.Tenant,
 		)
 	}
-	if details.Indexes != nil {
-		return errors.Wrap(gcIndexes(ctx, execCfg, details.ParentID, progress), "attempting to GC indexes")
-	} else if details.Tables != nil {
-		if err := gcTables(ctx, execCfg, progress); err != nil {
+	gcIndexesStep := func() error {
+		if details.Indexes == nil {
+			return nil
+		}
+		var err error
+		deferred, err = gcIndexes(ctx, execCfg, details, progress)
+		return errors.Wrap(err, "attempting to GC indexes")
+	}
+	gcTablesStep := func() error {
+		if details.Tables == nil {
+			return nil
+		}
+		if err := gcTables(ctx, execCfg, details, progress); err != nil {
 			return errors.Wrap(err, "attempting to GC tables")
 		}
 
-		// Drop database zone config when all the tables have been GCed.
-		if details.ParentID != descpb.InvalidID && isDoneGC(progress) {
+		// Drop database zone config when all the tables have been GCed. The
+		// ParentID of a job which also drops indexes is that of their table,
+		// not of a database.
+		if details.ParentID != descpb.InvalidID && details.Indexes == nil && isDoneGC(progress) &&
+			deleteDatabaseZoneConfigEnabled.Get(&execCfg.Settings.SV) {
 			if err := deleteDatabaseZoneConfig(
 				ctx,
 				execCfg.DB,
@@ -81,21 +185,31 @@ func performGC(
 				return errors.Wrap(err, "deleting database zone config")
 			}
 		}
+		return nil
 	}
-	return nil
+	// When a job drops both indexes and tables, the indexes are GC'd first
+	// unless the job is asked to do the opposite.
+	steps := []func() error{gcIndexesStep, gcTablesStep}
+	if gcTablesBeforeIndexesEnabled.Get(&execCfg.Settings.SV) {
+		steps[0], steps[1] = steps[1], steps[0]
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return false, err
+		}
+	}
+	return deferred, nil
 }
 
 func unsplitRangesForTables(
-	ctx context.Context,
-	execCfg *sql.ExecutorConfig,
-	droppedTables []jobspb.SchemaChangeGCDetails_DroppedID,
+	ctx context.Context, execCfg *sql.ExecutorConfig, details *jobspb.SchemaChangeGCDetails,
 ) error {
 	if !execCfg.Codec.ForSystemTenant() {
 		return nil
 	}
 
-	for _, droppedTable := range droppedTables {
-		startKey := execCfg.Codec.TablePrefix(uint32(droppedTable.ID))
+	for _, droppedTable := range details.Tables {
+		startKey := execCfg.Codec.TablePrefix(uint32(tableDataID(details, droppedTable.ID)))
 		span := roachpb.Span{
 			Key:    startKey,
 			EndKey: startKey.PrefixEnd(),
@@ -144,6 +258,9 @@ func maybeUnsplitRanges(
 	if progress.RangesUnsplitDone {
 		return nil
 	}
+	if err := waitForDestructiveOps(ctx, execCfg, jobID, progress); err != nil {
+		return err
+	}
 
 	if len(details.Indexes) > 0 {
 		if err := unsplitRangesForIndexes(ctx, execCfg, details.Indexes, details.ParentID); err != nil {
@@ -152,42 +269,83 @@ func maybeUnsplitRanges(
 	}
 
 	if len(details.Tables) > 0 {
-		if err := unsplitRangesForTables(ctx, execCfg, details.Tables); err != nil {
+		if err := unsplitRangesForTables(ctx, execCfg, details); err != nil {
 			return err
 		}
 	}
 
 	progress.RangesUnsplitDone = true
 	persistProgress(ctx, execCfg, jobID, progress, runningStatusGC(progress))
+	if fn := execCfg.GCJobTestingKnobs.RunAfterUnsplitRanges; fn != nil {
+		fn(jobID)
+	}
 
 	return nil
 }
 
 // Resume is part of the jobs.Resumer interface.
 func (r schemaChangeGCResumer) Resume(ctx context.Context, execCtx interface{}) (err error) {
+	p := execCtx.(sql.JobExecContext)
+	// TODO(pbardea): Wait for no versions.
+	execCfg := p.ExecCfg()
+	var progress *jobspb.SchemaChangeGCProgress
 	defer func() {
-		if err != nil && !r.isPermanentGCError(err) {
+		if err == nil {
+			return
+		}
+		if !r.isPermanentGCError(err) {
 			err = jobs.MarkAsRetryJobError(err)
+			return
+		}
+		// Record why the job failed, so that permanent failures can be
+		// grouped by their reason.
+		if progress != nil {
+			progress.FailureReason = gcFailureReason(err)
+			persistProgress(ctx, execCfg, r.jobID, progress, runningStatusGC(progress))
 		}
 	}()
-	p := execCtx.(sql.JobExecContext)
-	// TODO(pbardea): Wait for no versions.
-	execCfg := p.ExecCfg()
 	if fn := execCfg.GCJobTestingKnobs.RunBeforeResume; fn != nil {
 		if err := fn(r.jobID); err != nil {
 			return err
 		}
 	}
+	if execCfg.GCJobTestingKnobs.VerifyCodecOnResume {
+		if err := verifyCodecPrefixes(execCfg.Codec, execCfg.RPCContext.TenantID); err != nil {
+			log.Errorf(ctx, "GC job %d: %v", r.jobID, err)
+			return err
+		}
+	}
 	details, progress, err := initDetailsAndProgress(ctx, execCfg, r.jobID)
 	if err != nil {
 		return err
 	}
 
-	if err := maybeUnsplitRanges(ctx, execCfg, r.jobID, details, progress); err != nil {
-		return err
+	if !details.UnsplitOnly {
+		if err := maybeEstimateElementSizes(ctx, execCfg, r.jobID, details, progress); err != nil {
+			return err
+		}
+	}
+
+	// Unless the job is asked to unsplit after clearing the data, the ranges
+	// are unsplit upfront. Otherwise, or if the setting was changed while the
+	// job was running, they are unsplit once all of the elements are GC'd.
+	if details.UnsplitOnly || !unsplitAfterClearEnabled.Get(&execCfg.Settings.SV) {
+		if err := maybeUnsplitRanges(ctx, execCfg, r.jobID, details, progress); err != nil {
+			return err
+		}
+	}
+	if details.UnsplitOnly {
+		// The job was only asked to merge away the splits of the dropped
+		// elements. Their data is left untouched.
+		log.Infof(ctx, "unsplit ranges for GC job %d, skipping GC", r.jobID)
+		return nil
 	}
 
 	tableDropTimes, indexDropTimes := getDropTimes(details)
+	if m := gcMetrics(execCfg); m != nil {
+		defer m.setOldestPendingDrop(r.jobID, 0)
+	}
+	tableExpirationBases := getTableExpirationBases(details)
 
 	timer := timeutil.NewTimer()
 	defer timer.Stop()
@@ -215,7 +373,7 @@ func (r schemaChangeGCResumer) Resume(ctx context.Context, execCtx interface{})
 		if details.Tenant == nil {
 			remainingTables := getAllTablesWaitingForGC(details, progress)
 			expired, earliestDeadline = refreshTables(
-				ctx, execCfg, remainingTables, tableDropTimes, indexDropTimes, r.jobID, progress,
+				ctx, execCfg, remainingTables, tableDropTimes, tableExpirationBases, indexDropTimes, r.jobID, progress,
 			)
 		} else {
 			expired, earliestDeadline, err = refreshTenant(ctx, execCfg, details.Tenant.DropTime, details, progress)
@@ -233,16 +391,56 @@ func (r schemaChangeGCResumer) Resume(ctx context.Context, execCtx interface{})
 					return err
 				}
 			}
-			if err := performGC(ctx, execCfg, details, progress); err != nil {
+			// Wait for a slot if too many GC jobs are already clearing data.
+			// The progress is persisted first so that the job reports why it
+			// is not making progress.
+			releaseSlot, err := gcSlots.acquire(ctx, func() int64 {
+				return maxConcurrentClearsPerNode.Get(&execCfg.Settings.SV)
+			}, func() {
+				persistProgress(ctx, execCfg, r.jobID, progress, sql.RunningStatusWaitingForGCSlot)
+			})
+			if err != nil {
+				return err
+			}
+			deleting := tablesBeingDeleted(execCfg, progress)
+			deferred, err := performGCWithAdmission(ctx, execCfg, r.jobID, details, progress)
+			releaseSlot()
+			recordGCTombstones(ctx, execCfg, r.jobID, details, progress, deleting)
+			if err != nil {
+				if ctx.Err() != nil {
+					// The pass was interrupted because the job was canceled or
+					// paused. Save the progress made on the elements which were
+					// already GC'd, using a context which is not canceled.
+					persistCtx := logtags.WithTags(context.Background(), logtags.FromContext(ctx))
+					persistProgress(persistCtx, execCfg, r.jobID, progress, runningStatusGC(progress))
+				}
 				return err
 			}
-			persistProgress(ctx, execCfg, r.jobID, progress, sql.RunningStatusWaitingGC)
+			runningStatus := sql.RunningStatusWaitingGC
+			if !destructiveOpsEnabled.Get(&execCfg.Settings.SV) {
+				runningStatus = sql.RunningStatusWaitingForDestructiveOps
+			}
+			persistProgress(ctx, execCfg, r.jobID, progress, runningStatus)
+			if fn := execCfg.GCJobTestingKnobs.RunAfterPerformGC; fn != nil {
+				if err := fn(r.jobID); err != nil {
+					return err
+				}
+			}
 
-			// Trigger immediate re-run in case of more expired elements.
+			// Trigger immediate re-run in case of more expired elements. If
+			// some elements were deferred because they were still hot, check
+			// them again after the polling interval instead.
 			timerDuration = 0
+			if deferred {
+				timerDuration = MaxSQLGCInterval
+			}
 		}
 
 		if isDoneGC(progress) {
+			if err := maybeUnsplitRanges(ctx, execCfg, r.jobID, details, progress); err != nil {
+				return err
+			}
+			emitGCFinishedEvent(ctx, execCfg, r.jobID, details)
 			return nil
 		}
 
@@ -254,6 +452,40 @@ func (r schemaChangeGCResumer) Resume(ctx context.Context, execCtx interface{})
 	}
 }
 
+// emitGCFinishedEvent records an event in the event log once all of the
+// elements of the job have been GC'd. Failing to record the event does not
+// fail the job.
+func emitGCFinishedEvent(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	jobID jobspb.JobID,
+	details *jobspb.SchemaChangeGCDetails,
+) {
+	event := eventpb.FinishGarbageCollection{ParentID: uint32(details.ParentID)}
+	for _, table := range details.Tables {
+		event.TableIDs = append(event.TableIDs, uint32(table.ID))
+	}
+	for _, index := range details.Indexes {
+		event.IndexIDs = append(event.IndexIDs, uint32(index.IndexID))
+	}
+	if details.Tenant != nil {
+		event.TenantID = details.Tenant.ID
+	}
+	job, err := execCfg.JobRegistry.LoadJob(ctx, jobID)
+	if err != nil {
+		log.Warningf(ctx, "failed to log event: %v", err)
+		return
+	}
+	payload := job.Payload()
+	if err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		return sql.LogEventForJobs(
+			ctx, execCfg, txn, &event, int64(jobID), payload, payload.UsernameProto.Decode(), jobs.StatusSucceeded,
+		)
+	}); err != nil {
+		log.Warningf(ctx, "failed to log event: %v", err)
+	}
+}
+
 // OnFailOrCancel is part of the jobs.Resumer interface.
 func (r schemaChangeGCResumer) OnFailOrCancel(context.Context, interface{}) error {
 	return nil
@@ -268,6 +500,28 @@ func (r *schemaChangeGCResumer) isPermanentGCError(err error) bool {
 	return sql.IsPermanentSchemaChangeError(err)
 }
 
+// Reason codes recorded in the job progress when a GC job fails permanently.
+const (
+	gcFailureReasonDescriptorGone = "descriptor_gone"
+	gcFailureReasonPermission     = "permission"
+	gcFailureReasonUnknown        = "unknown"
+)
+
+// gcFailureReason classifies an error which caused a GC job to fail
+// permanently into a reason code.
+func gcFailureReason(err error) string {
+	if errors.Is(err, catalog.ErrDescriptorNotFound) {
+		return gcFailureReasonDescriptorGone
+	}
+	switch pgerror.GetPGCode(err) {
+	case pgcode.UndefinedTable, pgcode.UndefinedObject, pgcode.UndefinedDatabase:
+		return gcFailureReasonDescriptorGone
+	case pgcode.InsufficientPrivilege:
+		return gcFailureReasonPermission
+	}
+	return gcFailureReasonUnknown
+}
+
 func init() {
 	createResumerFn := func(job *jobs.Job, settings *cluster.Settings) jobs.Resumer {
 		return &schemaChangeGCResumer{
@@ -275,4 +529,5 @@ func init() {
 		}
 	}
 	jobs.RegisterConstructor(jobspb.TypeSchemaChangeGC, createResumerFn)
+	jobs.MakeSchemaChangeGCMetricsHook = makeMetrics
 }
@@ -0,0 +1,128 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gcjob
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/desctestutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPerformGCIndexesBeforeTables ensures that a GC pass over both dropped
+// indexes and dropped tables clears the indexes first, unless it is asked to
+// clear the tables first.
+func TestPerformGCIndexesBeforeTables(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	srv, db, kvDB := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			JobsTestingKnobs: jobs.NewTestingKnobsWithShortIntervals(),
+		},
+	})
+	defer srv.Stopper().Stop(ctx)
+	execCfg := srv.ExecutorConfig().(sql.ExecutorConfig)
+	tr := srv.TracerI().(*tracing.Tracer)
+	tdb := sqlutils.MakeSQLRunner(db)
+
+	tdb.Exec(t, "SET use_declarative_schema_changer = 'off'")
+	tdb.Exec(t, "CREATE DATABASE db")
+
+	clearRangeRE := regexp.MustCompile(`^ClearRange /Table/(\d+)`)
+	for i, tablesFirst := range []bool{false, true} {
+		t.Run(fmt.Sprintf("tablesFirst=%t", tablesFirst), func(t *testing.T) {
+			tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.gc_tables_before_indexes.enabled = $1", tablesFirst)
+
+			// The dropped elements are left in the DROP state, since the GC TTL
+			// of the jobs created by the schema changes has not expired.
+			withIndex, dropped := fmt.Sprintf("with_index_%d", i), fmt.Sprintf("dropped_%d", i)
+			tdb.Exec(t, fmt.Sprintf("CREATE TABLE db.%s (k INT PRIMARY KEY, v INT, INDEX idx (v))", withIndex))
+			tdb.Exec(t, fmt.Sprintf("CREATE TABLE db.%s (k INT PRIMARY KEY)", dropped))
+			table := desctestutils.TestingGetPublicTableDescriptor(kvDB, execCfg.Codec, "db", withIndex)
+			idx, err := table.FindIndexWithName("idx")
+			require.NoError(t, err)
+			var droppedID descpb.ID
+			tdb.QueryRow(t, fmt.Sprintf("SELECT 'db.%s'::REGCLASS::INT", dropped)).Scan(&droppedID)
+			tdb.Exec(t, fmt.Sprintf("DROP INDEX db.%s@idx", withIndex))
+			tdb.Exec(t, fmt.Sprintf("DROP TABLE db.%s", dropped))
+
+			details := &jobspb.SchemaChangeGCDetails{
+				ParentID: table.GetID(),
+				Indexes:  []jobspb.SchemaChangeGCDetails_DroppedIndex{{IndexID: idx.GetID()}},
+				Tables:   []jobspb.SchemaChangeGCDetails_DroppedID{{ID: droppedID}},
+			}
+			progress := &jobspb.SchemaChangeGCProgress{
+				Indexes: []jobspb.SchemaChangeGCProgress_IndexProgress{
+					{IndexID: idx.GetID(), Status: jobspb.SchemaChangeGCProgress_DELETING},
+				},
+				Tables: []jobspb.SchemaChangeGCProgress_TableProgress{
+					{ID: droppedID, Status: jobspb.SchemaChangeGCProgress_DELETING},
+				},
+			}
+			recCtx, getRecAndFinish := tracing.ContextWithRecordingSpan(ctx, tr, "perform-gc")
+			deferred, err := performGC(recCtx, &execCfg, details, progress)
+			require.NoError(t, err)
+			require.False(t, deferred)
+			require.True(t, isDoneGC(progress))
+
+			// Order the ClearRange requests by time, and record which of the
+			// dropped elements each of them cleared.
+			type clearRange struct {
+				time time.Time
+				id   descpb.ID
+			}
+			var clearRanges []clearRange
+			for _, sp := range getRecAndFinish() {
+				for _, l := range sp.Logs {
+					m := clearRangeRE.FindStringSubmatch(l.Msg().StripMarkers())
+					if m == nil {
+						continue
+					}
+					id, err := strconv.Atoi(m[1])
+					require.NoError(t, err)
+					clearRanges = append(clearRanges, clearRange{time: l.Time, id: descpb.ID(id)})
+				}
+			}
+			sort.SliceStable(clearRanges, func(i, j int) bool {
+				return clearRanges[i].time.Before(clearRanges[j].time)
+			})
+			var order []descpb.ID
+			for _, cr := range clearRanges {
+				if len(order) == 0 || order[len(order)-1] != cr.id {
+					order = append(order, cr.id)
+				}
+			}
+			expected := []descpb.ID{table.GetID(), droppedID}
+			if tablesFirst {
+				expected = []descpb.ID{droppedID, table.GetID()}
+			}
+			require.Equal(t, expected, order)
+		})
+	}
+}
@@ -40,6 +40,16 @@ func markTableGCed(
 	}
 }
 
+// tableDataID returns the ID under which the data of the specified dropped
+// table is stored. This differs from the ID of the table if the table's spans
+// were rekeyed, e.g. during a restore.
+func tableDataID(details *jobspb.SchemaChangeGCDetails, tableID descpb.ID) descpb.ID {
+	if dataID, ok := details.TableRekeys[tableID]; ok {
+		return dataID
+	}
+	return tableID
+}
+
 // markIndexGCed marks the index as GC'd.
 func markIndexGCed(
 	ctx context.Context,
@@ -291,3 +301,18 @@ func getDropTimes(
 	}
 	return tableDropTimes, indexDropTimes
 }
+
+// getTableExpirationBases returns, for each dropped table, the wall time from
+// which its GC TTL is measured. This is the drop time, unless the table was
+// referenced after it was dropped, in which case it is the time of the last
+// reference.
+func getTableExpirationBases(details *jobspb.SchemaChangeGCDetails) map[descpb.ID]int64 {
+	tableExpirationBases := make(map[descpb.ID]int64)
+	for _, table := range details.Tables {
+		tableExpirationBases[table.ID] = table.DropTime
+		if table.LastReferenceTime > table.DropTime {
+			tableExpirationBases[table.ID] = table.LastReferenceTime
+		}
+	}
+	return tableExpirationBases
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gcjob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTableExpirationBases ensures that the GC TTL of a dropped table is
+// measured from its last reference time, if one was recorded after the drop,
+// and from its drop time otherwise.
+func TestTableExpirationBases(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const ttlSeconds = 10
+	dropTime := time.Unix(100, 0).UnixNano()
+	lastReferenceTime := time.Unix(150, 0).UnixNano()
+
+	details := &jobspb.SchemaChangeGCDetails{
+		Tables: []jobspb.SchemaChangeGCDetails_DroppedID{
+			{ID: 1, DropTime: dropTime},
+			{ID: 2, DropTime: dropTime, LastReferenceTime: lastReferenceTime},
+			// A last reference preceding the drop does not shorten the TTL.
+			{ID: 3, DropTime: dropTime, LastReferenceTime: dropTime - 1},
+		},
+	}
+	tableDropTimes, _ := getDropTimes(details)
+	bases := getTableExpirationBases(details)
+
+	for _, tc := range []struct {
+		id       descpb.ID
+		deadline time.Time
+	}{
+		{id: 1, deadline: time.Unix(110, 0)},
+		{id: 2, deadline: time.Unix(160, 0)},
+		{id: 3, deadline: time.Unix(110, 0)},
+	} {
+		require.Equal(t, dropTime, tableDropTimes[tc.id])
+		require.True(t, tc.deadline.Equal(tableGCDeadline(bases[tc.id], ttlSeconds)),
+			"table %d: expected deadline %s, got %s",
+			tc.id, tc.deadline, tableGCDeadline(bases[tc.id], ttlSeconds))
+	}
+
+	// At a time after the drop-time based deadline but before the
+	// last-reference based deadline, only the former has expired.
+	now := time.Unix(120, 0)
+	require.True(t, tableGCDeadline(bases[1], ttlSeconds).Before(now))
+	require.False(t, tableGCDeadline(bases[2], ttlSeconds).Before(now))
+}
+
+// TestGCFailureReason ensures that permanent GC job errors are classified
+// into the expected reason codes.
+func TestGCFailureReason(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	for _, tc := range []struct {
+		err      error
+		expected string
+	}{
+		{
+			err:      errors.Wrapf(catalog.ErrDescriptorNotFound, "fetching table %d", 52),
+			expected: gcFailureReasonDescriptorGone,
+		},
+		{
+			err:      pgerror.Newf(pgcode.UndefinedTable, "relation %q does not exist", "foo"),
+			expected: gcFailureReasonDescriptorGone,
+		},
+		{
+			err:      pgerror.New(pgcode.InsufficientPrivilege, "user has no privileges"),
+			expected: gcFailureReasonPermission,
+		},
+		{
+			err:      errors.New("something went wrong"),
+			expected: gcFailureReasonUnknown,
+		},
+	} {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			require.Equal(t, tc.expected, gcFailureReason(tc.err))
+		})
+	}
+}
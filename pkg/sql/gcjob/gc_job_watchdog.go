@@ -0,0 +1,133 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gcjob
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+var gcStallTimeout = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.gc_job.stall_timeout",
+	"if positive, the GC job logs a warning when a GC pass makes no progress "+
+		"clearing data for longer than this duration",
+	0,
+	settings.NonNegativeDuration,
+)
+
+var gcStallFailJobEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.gc_job.stall_timeout.fail_job.enabled",
+	"if enabled, a GC pass which made no progress for longer than "+
+		"sql.gc_job.stall_timeout is canceled and the GC job is retried",
+	false,
+)
+
+// gcProgressHeartbeat records when a GC pass last made progress clearing
+// data. It is carried by the context of the pass.
+type gcProgressHeartbeat struct {
+	// lastProgress is the time of the last progress, in nanoseconds since the
+	// epoch. Accessed atomically.
+	lastProgress int64
+}
+
+type gcProgressHeartbeatKey struct{}
+
+// recordGCProgress records that the GC pass running with the given context
+// made progress, if the pass is being watched for stalls.
+func recordGCProgress(ctx context.Context) {
+	if hb, ok := ctx.Value(gcProgressHeartbeatKey{}).(*gcProgressHeartbeat); ok {
+		atomic.StoreInt64(&hb.lastProgress, timeutil.Now().UnixNano())
+	}
+}
+
+// performGCWithWatchdog runs performGC while watching it for stalls, e.g. a
+// ClearRange on a wedged range. If the pass makes no progress for longer than
+// sql.gc_job.stall_timeout, a warning is logged. If
+// sql.gc_job.stall_timeout.fail_job.enabled is set, the pass is also canceled
+// and a retryable error is returned, so that the job is restarted.
+func performGCWithWatchdog(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	jobID jobspb.JobID,
+	details *jobspb.SchemaChangeGCDetails,
+	progress *jobspb.SchemaChangeGCProgress,
+) (deferred bool, _ error) {
+	timeout := gcStallTimeout.Get(&execCfg.Settings.SV)
+	if timeout <= 0 {
+		return performGC(ctx, execCfg, details, progress)
+	}
+
+	hb := &gcProgressHeartbeat{lastProgress: timeutil.Now().UnixNano()}
+	gcCtx, cancel := context.WithCancel(context.WithValue(ctx, gcProgressHeartbeatKey{}, hb))
+	defer cancel()
+	done := make(chan struct{})
+	var stalled bool
+	var err error
+	g := ctxgroup.WithContext(ctx)
+	g.GoCtx(func(ctx context.Context) error {
+		timer := timeutil.NewTimer()
+		defer timer.Stop()
+		timer.Reset(timeout)
+		for {
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return nil
+			case <-timer.C:
+				timer.Read = true
+			}
+			sinceProgress := timeutil.Since(timeutil.Unix(0, atomic.LoadInt64(&hb.lastProgress)))
+			if sinceProgress < timeout {
+				timer.Reset(timeout - sinceProgress)
+				continue
+			}
+			log.Warningf(ctx, "GC job %d made no progress for %s", jobID, sinceProgress.Round(time.Millisecond))
+			if fn := execCfg.GCJobTestingKnobs.RunAfterStallDetected; fn != nil {
+				fn(jobID)
+			}
+			if gcStallFailJobEnabled.Get(&execCfg.Settings.SV) {
+				stalled = true
+				cancel()
+				return nil
+			}
+			timer.Reset(timeout)
+		}
+	})
+	g.GoCtx(func(context.Context) error {
+		defer close(done)
+		deferred, err = performGC(gcCtx, execCfg, details, progress)
+		return nil
+	})
+	_ = g.Wait()
+
+	if stalled {
+		// Save the progress made on the elements which were already GC'd
+		// before restarting the job.
+		persistProgress(ctx, execCfg, jobID, progress, runningStatusGC(progress))
+		return false, jobs.MarkAsRetryJobError(errors.Newf(
+			"GC job %d made no progress for %s", jobID, timeout,
+		))
+	}
+	return deferred, err
+}
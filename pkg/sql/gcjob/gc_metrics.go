@@ -0,0 +1,126 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gcjob
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+var metaOldestPendingDropAge = metric.Metadata{
+	Name: "sql.gc.oldest_pending_drop_age_seconds",
+	Help: "Age of the oldest dropped table or index which is still waiting " +
+		"for its GC TTL to expire, across the GC jobs running on this node",
+	Measurement: "Age",
+	Unit:        metric.Unit_SECONDS,
+}
+
+// Metrics are the metrics of the GC jobs running on a node.
+type Metrics struct {
+	OldestPendingDropAge *metric.Gauge
+
+	// now is the clock against which the age of the pending drops is
+	// measured. It is overridden in tests.
+	now func() time.Time
+
+	mu struct {
+		syncutil.Mutex
+		// oldestPendingDrop maps each GC job with elements waiting for GC to
+		// the drop time, in nanoseconds, of the oldest of them.
+		oldestPendingDrop map[jobspb.JobID]int64
+	}
+}
+
+// MetricStruct implements the metric.Struct interface.
+func (*Metrics) MetricStruct() {}
+
+func makeMetrics(time.Duration) metric.Struct {
+	m := &Metrics{now: timeutil.Now}
+	m.mu.oldestPendingDrop = make(map[jobspb.JobID]int64)
+	m.OldestPendingDropAge = metric.NewFunctionalGauge(metaOldestPendingDropAge, m.oldestPendingDropAge)
+	return m
+}
+
+// oldestPendingDropAge returns the age, in seconds, of the oldest element
+// waiting for GC across all of the GC jobs, or zero if there are none.
+func (m *Metrics) oldestPendingDropAge() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var oldest int64
+	for _, dropTime := range m.mu.oldestPendingDrop {
+		if oldest == 0 || dropTime < oldest {
+			oldest = dropTime
+		}
+	}
+	if oldest == 0 {
+		return 0
+	}
+	age := m.now().Sub(timeutil.Unix(0, oldest))
+	if age < 0 {
+		return 0
+	}
+	return int64(age / time.Second)
+}
+
+// setOldestPendingDrop records the drop time of the oldest element of the
+// given job which is waiting for GC. A zero drop time means that the job has no
+// elements waiting for GC anymore.
+func (m *Metrics) setOldestPendingDrop(jobID jobspb.JobID, dropTime int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if dropTime == 0 {
+		delete(m.mu.oldestPendingDrop, jobID)
+		return
+	}
+	m.mu.oldestPendingDrop[jobID] = dropTime
+}
+
+// gcMetrics returns the metrics of the GC jobs, or nil if they are not
+// registered.
+func gcMetrics(execCfg *sql.ExecutorConfig) *Metrics {
+	if execCfg.JobRegistry == nil {
+		return nil
+	}
+	m, _ := execCfg.JobRegistry.MetricsStruct().SchemaChangeGC.(*Metrics)
+	return m
+}
+
+// oldestPendingDropTime returns the drop time of the oldest table or index of
+// the job which is still waiting for GC, or zero if there are none.
+func oldestPendingDropTime(
+	progress *jobspb.SchemaChangeGCProgress,
+	tableDropTimes map[descpb.ID]int64,
+	indexDropTimes map[descpb.IndexID]int64,
+) int64 {
+	var oldest int64
+	update := func(dropTime int64) {
+		if dropTime != 0 && (oldest == 0 || dropTime < oldest) {
+			oldest = dropTime
+		}
+	}
+	for _, table := range progress.Tables {
+		if table.Status == jobspb.SchemaChangeGCProgress_WAITING_FOR_GC {
+			update(tableDropTimes[table.ID])
+		}
+	}
+	for _, index := range progress.Indexes {
+		if index.Status == jobspb.SchemaChangeGCProgress_WAITING_FOR_GC {
+			update(indexDropTimes[index.IndexID])
+		}
+	}
+	return oldest
+}
@@ -0,0 +1,105 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gcjob
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOldestPendingDropAge(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	m := makeMetrics(time.Minute).(*Metrics)
+	now := timeutil.Unix(1000, 0)
+	m.now = func() time.Time { return now }
+	require.Equal(t, int64(0), m.OldestPendingDropAge.Value())
+
+	m.setOldestPendingDrop(1, timeutil.Unix(900, 0).UnixNano())
+	require.Equal(t, int64(100), m.OldestPendingDropAge.Value())
+
+	// The age grows with time.
+	now = now.Add(time.Minute)
+	require.Equal(t, int64(160), m.OldestPendingDropAge.Value())
+
+	// The oldest element across all of the jobs is reported.
+	m.setOldestPendingDrop(2, timeutil.Unix(500, 0).UnixNano())
+	require.Equal(t, int64(560), m.OldestPendingDropAge.Value())
+	m.setOldestPendingDrop(2, 0)
+	require.Equal(t, int64(160), m.OldestPendingDropAge.Value())
+	m.setOldestPendingDrop(1, 0)
+	require.Equal(t, int64(0), m.OldestPendingDropAge.Value())
+
+	// Only the elements still waiting for GC count.
+	progress := &jobspb.SchemaChangeGCProgress{
+		Tables: []jobspb.SchemaChangeGCProgress_TableProgress{
+			{ID: 100, Status: jobspb.SchemaChangeGCProgress_DELETED},
+			{ID: 101, Status: jobspb.SchemaChangeGCProgress_WAITING_FOR_GC},
+		},
+		Indexes: []jobspb.SchemaChangeGCProgress_IndexProgress{
+			{IndexID: 2, Status: jobspb.SchemaChangeGCProgress_DELETING},
+			{IndexID: 3, Status: jobspb.SchemaChangeGCProgress_WAITING_FOR_GC},
+		},
+	}
+	require.Equal(t, int64(30), oldestPendingDropTime(
+		progress,
+		map[descpb.ID]int64{100: 10, 101: 40},
+		map[descpb.IndexID]int64{2: 20, 3: 30},
+	))
+}
+
+// TestOldestPendingDropAgeOfDroppedTable ensures that the GC job of a dropped
+// table which waits for its GC TTL reports the age of the drop.
+func TestOldestPendingDropAgeOfDroppedTable(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			JobsTestingKnobs: jobs.NewTestingKnobsWithShortIntervals(),
+		},
+	})
+	defer srv.Stopper().Stop(ctx)
+	execCfg := srv.ExecutorConfig().(sql.ExecutorConfig)
+	m := gcMetrics(&execCfg)
+	require.NotNil(t, m)
+	tdb := sqlutils.MakeSQLRunner(db)
+
+	tdb.Exec(t, "CREATE TABLE t (i INT PRIMARY KEY)")
+	beforeDrop := timeutil.Now()
+	tdb.Exec(t, "DROP TABLE t")
+
+	// The table waits for its GC TTL, and the age of its drop grows as time
+	// passes.
+	testutils.SucceedsSoon(t, func() error {
+		if age := m.OldestPendingDropAge.Value(); age < 2 {
+			return errors.Newf("expected the drop to be at least 2 seconds old, found %d", age)
+		}
+		return nil
+	})
+	require.LessOrEqual(t, m.OldestPendingDropAge.Value(), int64(timeutil.Since(beforeDrop)/time.Second))
+}
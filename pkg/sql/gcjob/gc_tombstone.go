@@ -0,0 +1,95 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gcjob
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+)
+
+// recordTombstonesEnabled controls whether the GC job records a tombstone
+// in the event log for each table it GCs.
+var recordTombstonesEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.gc_job.record_tombstones.enabled",
+	"if enabled, the GC job records a garbage_collection_tombstone event for each "+
+		"dropped table once its data and descriptor are removed",
+	false,
+)
+
+// tablesBeingDeleted returns the IDs of the tables of the job which are
+// ready to be GC'd, if tombstones are to be recorded for them.
+func tablesBeingDeleted(
+	execCfg *sql.ExecutorConfig, progress *jobspb.SchemaChangeGCProgress,
+) []descpb.ID {
+	if !recordTombstonesEnabled.Get(&execCfg.Settings.SV) {
+		return nil
+	}
+	var ids []descpb.ID
+	for _, table := range progress.Tables {
+		if table.Status == jobspb.SchemaChangeGCProgress_DELETING {
+			ids = append(ids, table.ID)
+		}
+	}
+	return ids
+}
+
+// recordGCTombstones records a tombstone in the event log for each of the
+// given tables which was GC'd, so that the fact that the table was GC'd,
+// when, and by which job, outlives its descriptor. Failing to record a
+// tombstone does not fail the job.
+func recordGCTombstones(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	jobID jobspb.JobID,
+	details *jobspb.SchemaChangeGCDetails,
+	progress *jobspb.SchemaChangeGCProgress,
+	tableIDs []descpb.ID,
+) {
+	var events []eventpb.GarbageCollectionTombstone
+	for _, id := range tableIDs {
+		if tp := tableProgress(progress, id); tp != nil && tp.Status == jobspb.SchemaChangeGCProgress_DELETED {
+			events = append(events, eventpb.GarbageCollectionTombstone{
+				TableID:  uint32(id),
+				ParentID: uint32(details.ParentID),
+			})
+		}
+	}
+	if len(events) == 0 {
+		return
+	}
+	job, err := execCfg.JobRegistry.LoadJob(ctx, jobID)
+	if err != nil {
+		log.Warningf(ctx, "failed to record GC tombstones: %v", err)
+		return
+	}
+	payload := job.Payload()
+	if err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		for i := range events {
+			if err := sql.LogEventForJobs(
+				ctx, execCfg, txn, &events[i], int64(jobID), payload, payload.UsernameProto.Decode(), jobs.StatusRunning,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		log.Warningf(ctx, "failed to record GC tombstones: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gcjob
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// excessiveTTLThreshold is the GC TTL beyond which the GC TTL of a dropped
+// table or index is considered a misconfiguration.
+var excessiveTTLThreshold = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.gc_job.excessive_ttl_warning_threshold",
+	"if positive, the GC job logs a warning when the GC TTL of a dropped table "+
+		"or index exceeds this duration, as its GC would appear to never make progress",
+	0,
+	settings.NonNegativeDuration,
+)
+
+// excessiveTTLWarningInterval is the minimum interval between two warnings
+// about the GC TTL of the same element.
+const excessiveTTLWarningInterval = 10 * time.Minute
+
+// gcElement identifies a dropped table, or a dropped index of a table.
+type gcElement struct {
+	tableID descpb.ID
+	indexID descpb.IndexID
+}
+
+// excessiveTTLWarnings records when a warning was last logged about the GC
+// TTL of each element, so that the warnings are rate limited per element.
+var excessiveTTLWarnings = struct {
+	syncutil.Mutex
+	lastWarned map[gcElement]time.Time
+}{lastWarned: make(map[gcElement]time.Time)}
+
+// maybeWarnExcessiveTTL logs a warning if the GC TTL of the given element
+// exceeds the sql.gc_job.excessive_ttl_warning_threshold setting, unless a
+// warning was logged about the element recently. The index ID is zero for
+// tables.
+func maybeWarnExcessiveTTL(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	tableID descpb.ID,
+	indexID descpb.IndexID,
+	ttlSeconds int64,
+) {
+	threshold := excessiveTTLThreshold.Get(&execCfg.Settings.SV)
+	ttl := time.Duration(ttlSeconds) * time.Second
+	if threshold <= 0 || ttl <= threshold {
+		return
+	}
+
+	now := timeutil.Now()
+	e := gcElement{tableID: tableID, indexID: indexID}
+	excessiveTTLWarnings.Lock()
+	for other, lastWarned := range excessiveTTLWarnings.lastWarned {
+		if now.Sub(lastWarned) >= excessiveTTLWarningInterval {
+			delete(excessiveTTLWarnings.lastWarned, other)
+		}
+	}
+	_, warned := excessiveTTLWarnings.lastWarned[e]
+	if !warned {
+		excessiveTTLWarnings.lastWarned[e] = now
+	}
+	excessiveTTLWarnings.Unlock()
+	if warned {
+		return
+	}
+
+	if indexID == 0 {
+		log.Warningf(ctx, "the GC TTL of dropped table %d is %s, which exceeds %s; "+
+			"its data will not be GC'd for that long", tableID, ttl, threshold)
+	} else {
+		log.Warningf(ctx, "the GC TTL of dropped index %d from table %d is %s, which exceeds %s; "+
+			"its data will not be GC'd for that long", indexID, tableID, ttl, threshold)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gcjob
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/redact"
+)
+
+// TestExcessiveTTLWarning ensures that the GC job logs a warning naming a
+// dropped table whose GC TTL exceeds the configured threshold.
+func TestExcessiveTTLWarning(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			JobsTestingKnobs: jobs.NewTestingKnobsWithShortIntervals(),
+		},
+	})
+	defer srv.Stopper().Stop(ctx)
+	tdb := sqlutils.MakeSQLRunner(db)
+
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.excessive_ttl_warning_threshold = '24h'")
+	tdb.Exec(t, "SET use_declarative_schema_changer = 'off'")
+	tdb.Exec(t, "CREATE TABLE t (i INT PRIMARY KEY)")
+	tdb.Exec(t, "ALTER TABLE t CONFIGURE ZONE USING gc.ttlseconds = 1000000000")
+	var tableID int
+	tdb.QueryRow(t, "SELECT 't'::REGCLASS::INT").Scan(&tableID)
+
+	buf, cleanup := log.TeeToBuffer()
+	defer cleanup()
+	tdb.Exec(t, "DROP TABLE t")
+
+	expected := fmt.Sprintf("the GC TTL of dropped table %d is 277777h46m40s, which exceeds 24h0m0s", tableID)
+	testutils.SucceedsSoon(t, func() error {
+		for _, line := range strings.Split(buf.String(), "\n") {
+			var entry logpb.Entry
+			if json.Unmarshal([]byte(line), &entry) != nil {
+				continue
+			}
+			if entry.Severity == severity.WARNING && strings.Contains(redact.RedactableString(entry.Message).StripMarkers(), expected) {
+				return nil
+			}
+		}
+		return errors.Newf("no warning about the GC TTL of table %d", tableID)
+	})
+}
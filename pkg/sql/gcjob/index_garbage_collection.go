@@ -12,10 +12,13 @@ package gcjob
 
 import (
 	"context"
+	"sort"
 
+	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
@@ -23,7 +26,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/logtags"
 )
 
 // gcIndexes find the indexes that need to be GC'd, GC's them, and then updates
@@ -32,9 +37,10 @@ import (
 func gcIndexes(
 	ctx context.Context,
 	execCfg *sql.ExecutorConfig,
-	parentID descpb.ID,
+	details *jobspb.SchemaChangeGCDetails,
 	progress *jobspb.SchemaChangeGCProgress,
-) error {
+) (deferred bool, _ error) {
+	parentID := details.ParentID
 	droppedIndexes := progress.Indexes
 	if log.V(2) {
 		log.Infof(ctx, "GC is being considered on table %d for indexes indexes: %+v", parentID, droppedIndexes)
@@ -45,54 +51,268 @@ func gcIndexes(
 	// schedule a GC Job in the transaction that commits the truncation.
 	parentDesc, err := sql.WaitToUpdateLeases(ctx, execCfg.LeaseManager, parentID)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	parentTable, isTable := parentDesc.(catalog.TableDescriptor)
 	if !isTable {
-		return errors.AssertionFailedf("expected descriptor %d to be a table, not %T", parentID, parentDesc)
+		return false, errors.AssertionFailedf("expected descriptor %d to be a table, not %T", parentID, parentDesc)
 	}
-	for _, index := range droppedIndexes {
+	var indexIDs []descpb.IndexID
+	for i := range droppedIndexes {
+		index := &droppedIndexes[i]
 		if index.Status != jobspb.SchemaChangeGCProgress_DELETING {
 			continue
 		}
+		if shouldDeferHotIndex(ctx, execCfg, parentTable, index) {
+			// Leave the index in the DELETING state so that it is picked up
+			// again on the next GC pass.
+			log.Infof(ctx, "deferring GC of hot index %d from table %d", index.IndexID, parentTable.GetID())
+			deferred = true
+			continue
+		}
+		indexIDs = append(indexIDs, index.IndexID)
+	}
+	// The indexes are sorted by ID first so that the order in which they are
+	// cleared is deterministic, which the sort by size below preserves among
+	// indexes of equal size.
+	if sortIndexesByIDEnabled.Get(&execCfg.Settings.SV) {
+		sort.Slice(indexIDs, func(i, j int) bool { return indexIDs[i] < indexIDs[j] })
+	}
+	if prioritizeLargeElementsEnabled.Get(&execCfg.Settings.SV) {
+		sortIndexIDsBySize(details, indexIDs)
+	}
 
-		if err := clearIndex(ctx, execCfg, parentTable, index.IndexID); err != nil {
-			return errors.Wrapf(err, "clearing index %d from table %d", index.IndexID, parentTable.GetID())
-		}
-
-		// All the data chunks have been removed. Now also removed the
-		// zone configs for the dropped indexes, if any.
-		removeIndexZoneConfigs := func(
-			ctx context.Context, txn *kv.Txn, descriptors *descs.Collection,
-		) error {
-			freshParentTableDesc, err := descriptors.GetMutableTableByID(
-				ctx, txn, parentID, tree.ObjectLookupFlags{
-					CommonLookupFlags: tree.CommonLookupFlags{
-						AvoidLeased:    true,
-						Required:       true,
-						IncludeDropped: true,
-						IncludeOffline: true,
-					},
-				})
-			if err != nil {
-				return err
+	// The indexes of regional by row tables are cleared one partition at a
+	// time, see clearIndex, so they are never coalesced.
+	var groups []indexSpanGroup
+	if coalesceIndexClearRangeEnabled.Get(&execCfg.Settings.SV) && !parentTable.IsLocalityRegionalByRow() {
+		groups = coalesceIndexSpans(execCfg.Codec, parentTable, indexIDs)
+	} else {
+		groups = make([]indexSpanGroup, len(indexIDs))
+		for i, indexID := range indexIDs {
+			groups[i] = indexSpanGroup{
+				indexIDs: []descpb.IndexID{indexID},
+				span:     parentTable.IndexSpan(execCfg.Codec, indexID),
 			}
-			return sql.RemoveIndexZoneConfigs(
-				ctx, txn, execCfg, freshParentTableDesc, []uint32{uint32(index.IndexID)},
-			)
 		}
-		if err := sql.DescsTxn(ctx, execCfg, removeIndexZoneConfigs); err != nil {
-			return errors.Wrapf(err, "removing index %d zone configs", index.IndexID)
+	}
+
+	for _, group := range groups {
+		// Stop promptly if the job was canceled or paused, keeping the
+		// progress made on the indexes which were already GC'd.
+		if err := ctx.Err(); err != nil {
+			return false, err
 		}
 
-		if err := completeDroppedIndex(
-			ctx, execCfg, parentTable, index.IndexID, progress,
-		); err != nil {
-			return err
+		ctx := withChunkClearedHook(ctx, func(endKey roachpb.Key) {
+			for i := range progress.Indexes {
+				if group.contains(progress.Indexes[i].IndexID) {
+					progress.Indexes[i].ClearedHighWaterKey = advanceHighWaterKey(
+						progress.Indexes[i].ClearedHighWaterKey, endKey,
+					)
+				}
+			}
+			if fn := execCfg.GCJobTestingKnobs.RunAfterChunkCleared; fn != nil {
+				fn(endKey)
+			}
+		})
+		if len(group.indexIDs) == 1 {
+			indexID := group.indexIDs[0]
+			if err := clearIndex(ctx, execCfg, parentTable, indexID); err != nil {
+				return false, errors.Wrapf(err, "clearing index %d from table %d", indexID, parentTable.GetID())
+			}
+		} else {
+			log.Infof(ctx, "clearing indexes %v from table %d", group.indexIDs, parentTable.GetID())
+			if err := clearIndexSpans(ctx, execCfg, []indexPartitionSpan{{span: group.span}}); err != nil {
+				return false, errors.Wrapf(err, "clearing indexes %v from table %d", group.indexIDs, parentTable.GetID())
+			}
+		}
+		if err := maybeVerifySpanCleared(ctx, execCfg, group.span); err != nil {
+			return false, errors.Wrapf(err, "verifying indexes %v were cleared from table %d", group.indexIDs, parentTable.GetID())
+		}
+
+		for _, indexID := range group.indexIDs {
+			// All the data chunks have been removed. Now also removed the
+			// zone configs for the dropped indexes, if any.
+			removeIndexZoneConfigs := func(
+				ctx context.Context, txn *kv.Txn, descriptors *descs.Collection,
+			) error {
+				freshParentTableDesc, err := descriptors.GetMutableTableByID(
+					ctx, txn, parentID, tree.ObjectLookupFlags{
+						CommonLookupFlags: tree.CommonLookupFlags{
+							AvoidLeased:    true,
+							Required:       true,
+							IncludeDropped: true,
+							IncludeOffline: true,
+						},
+					})
+				if err != nil {
+					return err
+				}
+				return sql.RemoveIndexZoneConfigs(
+					ctx, txn, execCfg, freshParentTableDesc, []uint32{uint32(indexID)},
+				)
+			}
+			if err := sql.DescsTxn(ctx, execCfg, removeIndexZoneConfigs); err != nil {
+				return false, errors.Wrapf(err, "removing index %d zone configs", indexID)
+			}
+
+			if err := completeDroppedIndex(
+				ctx, execCfg, parentTable, indexID, progress,
+			); err != nil {
+				return false, err
+			}
+			recordGCProgress(ctx)
+			if fn := execCfg.GCJobTestingKnobs.RunAfterGCElement; fn != nil {
+				fn(parentTable.GetID(), indexID)
+			}
 		}
 	}
-	return nil
+	return deferred, nil
+}
+
+// indexSpanGroup is a set of dropped indexes of a table whose spans are
+// adjacent, along with the span covering all of them.
+type indexSpanGroup struct {
+	indexIDs []descpb.IndexID
+	span     roachpb.Span
+}
+
+// contains returns whether the given index is part of the group.
+func (g indexSpanGroup) contains(indexID descpb.IndexID) bool {
+	for _, id := range g.indexIDs {
+		if id == indexID {
+			return true
+		}
+	}
+	return false
+}
+
+// coalesceIndexSpans groups the given indexes of the table, in key order, into
+// runs whose spans are adjacent, so that each run can be cleared with a single
+// ClearRange. Two indexes are only grouped if no key lies between the end of
+// the span of one and the start of the span of the other, so that the span of
+// a live index in between is never cleared.
+func coalesceIndexSpans(
+	codec keys.SQLCodec, table catalog.TableDescriptor, indexIDs []descpb.IndexID,
+) []indexSpanGroup {
+	sorted := append([]descpb.IndexID(nil), indexIDs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var groups []indexSpanGroup
+	for _, indexID := range sorted {
+		sp := table.IndexSpan(codec, indexID)
+		if n := len(groups); n > 0 && groups[n-1].span.EndKey.Equal(sp.Key) {
+			groups[n-1].indexIDs = append(groups[n-1].indexIDs, indexID)
+			groups[n-1].span.EndKey = sp.EndKey
+			continue
+		}
+		groups = append(groups, indexSpanGroup{indexIDs: []descpb.IndexID{indexID}, span: sp})
+	}
+	return groups
+}
+
+// shouldDeferHotIndex returns whether the GC of the given dropped index is to
+// be deferred because the index is hot, see isIndexHot. The GC of an index is
+// not deferred for longer than the sql.gc_job.hot_index_max_deferral setting,
+// measured from the first time it was deferred, which is recorded in the
+// progress of the index.
+func shouldDeferHotIndex(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	tableDesc catalog.TableDescriptor,
+	index *jobspb.SchemaChangeGCProgress_IndexProgress,
+) bool {
+	if !isIndexHot(ctx, execCfg, tableDesc, index.IndexID) {
+		return false
+	}
+	now := timeutil.Now()
+	if index.HotDeferredSince == 0 {
+		index.HotDeferredSince = now.UnixNano()
+	}
+	maxDeferral := hotIndexMaxDeferral.Get(&execCfg.Settings.SV)
+	if deferred := now.Sub(timeutil.Unix(0, index.HotDeferredSince)); maxDeferral > 0 && deferred >= maxDeferral {
+		log.Infof(ctx, "clearing hot index %d from table %d, as its GC was deferred for %s",
+			index.IndexID, tableDesc.GetID(), deferred)
+		return false
+	}
+	return true
+}
+
+// isIndexHot returns whether the dropped index is still serving more queries
+// per second than the sql.gc_job.hot_index_qps_threshold setting, in which case
+// its GC is deferred. The queries per second are those of the ranges of the
+// index, taken from their stats. Once a range of the index is merged with its
+// neighbors, e.g. after the ranges of the index were unsplit, its load cannot
+// be told apart from that of the live indexes of the table, so the index is
+// not considered hot. A failure to fetch the stats is logged and doesn't hold
+// up the GC of the index.
+func isIndexHot(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	tableDesc catalog.TableDescriptor,
+	indexID descpb.IndexID,
+) bool {
+	threshold := hotIndexQPSThreshold.Get(&execCfg.Settings.SV)
+	if threshold <= 0 {
+		return false
+	}
+	rSpan, err := keys.SpanAddr(tableDesc.IndexSpan(execCfg.Codec, indexID))
+	if err != nil {
+		log.Warningf(ctx, "addressing index %d from table %d, err = %+v",
+			indexID, tableDesc.GetID(), err)
+		return false
+	}
+	var qps float64
+	ri := kvcoord.MakeRangeIterator(execCfg.DistSender)
+	for ri.Seek(ctx, rSpan.Key, kvcoord.Ascending); ; ri.Next(ctx) {
+		if !ri.Valid() {
+			log.Warningf(ctx, "iterating over the ranges of index %d from table %d, err = %+v",
+				indexID, tableDesc.GetID(), ri.Error())
+			return false
+		}
+		desc := ri.Desc()
+		if desc.StartKey.Less(rSpan.Key) || rSpan.EndKey.Less(desc.EndKey) {
+			log.VEventf(ctx, 2, "index %d from table %d shares range %s with other data",
+				indexID, tableDesc.GetID(), desc)
+			return false
+		}
+		rangeQPS, err := rangeQueriesPerSecond(ctx, execCfg, desc.RSpan().AsRawSpanWithNoLocals())
+		if err != nil {
+			log.Warningf(ctx, "fetching range stats of index %d from table %d, err = %+v",
+				indexID, tableDesc.GetID(), err)
+			return false
+		}
+		// The queries per second are negative if the leaseholder hasn't
+		// recorded the load of the range for long enough, which is treated as
+		// not hot.
+		if rangeQPS > 0 {
+			qps += rangeQPS
+		}
+		if !ri.NeedAnother(rSpan) {
+			return qps > threshold
+		}
+	}
+}
+
+// rangeQueriesPerSecond returns the queries per second served by the range
+// with the given span, as per its stats.
+func rangeQueriesPerSecond(
+	ctx context.Context, execCfg *sql.ExecutorConfig, span roachpb.Span,
+) (float64, error) {
+	if fn := execCfg.GCJobTestingKnobs.IndexQueriesPerSecond; fn != nil {
+		return fn(span), nil
+	}
+	b := &kv.Batch{}
+	b.AddRawRequest(&roachpb.RangeStatsRequest{
+		RequestHeader: roachpb.RequestHeader{
+			Key: span.Key,
+		},
+	})
+	if err := execCfg.DB.Run(ctx, b); err != nil {
+		return 0, err
+	}
+	return b.RawResponse().Responses[0].GetInner().(*roachpb.RangeStatsResponse).MaxQueriesPerSecond, nil
 }
 
 // clearIndexes issues Clear Range requests over all specified indexes.
@@ -105,16 +325,108 @@ func clearIndex(
 	log.Infof(ctx, "clearing index %d from table %d", indexID, tableDesc.GetID())
 
 	sp := tableDesc.IndexSpan(execCfg.Codec, indexID)
-	start, err := keys.Addr(sp.Key)
-	if err != nil {
-		return errors.Wrap(err, "failed to addr index start")
+	spans := []indexPartitionSpan{{span: sp}}
+	// The partitions of the indexes of regional by row tables are cleared one
+	// at a time, so that the log lines can be tagged with their region.
+	if tableDesc.IsLocalityRegionalByRow() && execCfg.Codec.ForSystemTenant() {
+		if cfg := execCfg.SystemConfig.GetSystemConfig(); cfg != nil {
+			zone, err := cfg.GetZoneConfigForObject(execCfg.Codec, uint32(tableDesc.GetID()))
+			if err != nil {
+				log.Warningf(ctx, "zone config for table %d, err = %+v", tableDesc.GetID(), err)
+			} else {
+				spans = indexPartitionSpans(execCfg.Codec, tableDesc.GetID(), indexID, sp, zone)
+			}
+		}
 	}
-	end, err := keys.Addr(sp.EndKey)
-	if err != nil {
-		return errors.Wrap(err, "failed to addr index end")
+	return clearIndexSpans(ctx, execCfg, spans)
+}
+
+// indexPartitionSpan is a span of an index along with the region of the
+// regional by row partition it belongs to, if any.
+type indexPartitionSpan struct {
+	region string
+	span   roachpb.Span
+}
+
+// indexPartitionSpans splits the span of the given index into the spans of its
+// partitions, as recorded in the subzone spans of the table's zone config. The
+// parts of the index span which aren't covered by a partition are returned
+// without a region.
+func indexPartitionSpans(
+	codec keys.SQLCodec,
+	tableID descpb.ID,
+	indexID descpb.IndexID,
+	indexSpan roachpb.Span,
+	zone *zonepb.ZoneConfig,
+) []indexPartitionSpan {
+	tablePrefix := codec.TablePrefix(uint32(tableID))
+	withTablePrefix := func(suffix roachpb.Key) roachpb.Key {
+		return append(tablePrefix[:len(tablePrefix):len(tablePrefix)], suffix...)
+	}
+	var partitions []indexPartitionSpan
+	if zone != nil {
+		for _, subzoneSpan := range zone.SubzoneSpans {
+			subzone := zone.Subzones[subzoneSpan.SubzoneIndex]
+			if descpb.IndexID(subzone.IndexID) != indexID || subzone.PartitionName == "" {
+				continue
+			}
+			endKey := subzoneSpan.EndKey
+			if len(endKey) == 0 {
+				endKey = subzoneSpan.Key.PrefixEnd()
+			}
+			partitions = append(partitions, indexPartitionSpan{
+				region: subzone.PartitionName,
+				span:   roachpb.Span{Key: withTablePrefix(subzoneSpan.Key), EndKey: withTablePrefix(endKey)},
+			})
+		}
+	}
+	sort.Slice(partitions, func(i, j int) bool {
+		return partitions[i].span.Key.Compare(partitions[j].span.Key) < 0
+	})
+
+	// Fill in the gaps between the partitions, so that the whole index span is
+	// covered.
+	var ret []indexPartitionSpan
+	cur := indexSpan.Key
+	for _, partition := range partitions {
+		if cur.Compare(partition.span.Key) < 0 {
+			ret = append(ret, indexPartitionSpan{span: roachpb.Span{Key: cur, EndKey: partition.span.Key}})
+		}
+		ret = append(ret, partition)
+		cur = partition.span.EndKey
 	}
-	rSpan := roachpb.RSpan{Key: start, EndKey: end}
-	return clearSpanData(ctx, execCfg.DB, execCfg.DistSender, rSpan)
+	if cur.Compare(indexSpan.EndKey) < 0 {
+		ret = append(ret, indexPartitionSpan{span: roachpb.Span{Key: cur, EndKey: indexSpan.EndKey}})
+	}
+	return ret
+}
+
+// clearIndexSpans issues Clear Range requests over the given spans of an
+// index, tagging the logs of the spans of a partition with its region.
+func clearIndexSpans(
+	ctx context.Context, execCfg *sql.ExecutorConfig, spans []indexPartitionSpan,
+) error {
+	for _, sp := range spans {
+		ctx := ctx
+		if sp.region != "" {
+			ctx = logtags.AddTag(ctx, "region", sp.region)
+			log.Infof(ctx, "clearing partition %s", sp.span)
+		}
+		start, err := keys.Addr(sp.span.Key)
+		if err != nil {
+			return errors.Wrap(err, "failed to addr index start")
+		}
+		end, err := keys.Addr(sp.span.EndKey)
+		if err != nil {
+			return errors.Wrap(err, "failed to addr index end")
+		}
+		rSpan := roachpb.RSpan{Key: start, EndKey: end}
+		maxBytes := clearRangeMaxBytes.Get(&execCfg.Settings.SV)
+		if err := clearSpanData(ctx, execCfg.DB, execCfg.DistSender, rSpan, maxBytes); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // completeDroppedIndexes updates the mutations of the table descriptor to
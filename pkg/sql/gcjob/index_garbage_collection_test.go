@@ -0,0 +1,191 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gcjob
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/desctestutils"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+// regionTagInterceptor collects the region tags of the intercepted entries
+// which log the clearing of a partition.
+type regionTagInterceptor struct {
+	syncutil.Mutex
+	regions []string
+}
+
+var _ log.Interceptor = (*regionTagInterceptor)(nil)
+
+func (r *regionTagInterceptor) Intercept(message []byte) {
+	var entry struct {
+		Message string
+		Tags    map[string]string
+	}
+	if err := json.Unmarshal(message, &entry); err != nil {
+		return
+	}
+	if !strings.Contains(entry.Message, "clearing partition") {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.regions = append(r.regions, entry.Tags["region"])
+}
+
+// TestClearIndexPartitionsRegionTag ensures that the spans of the partitions
+// of an index are derived from the subzone spans of the table's zone config,
+// and that the logs of the clearing of each partition are tagged with its
+// region.
+func TestClearIndexPartitionsRegionTag(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	srv, db, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer srv.Stopper().Stop(ctx)
+	execCfg := srv.ExecutorConfig().(sql.ExecutorConfig)
+	tdb := sqlutils.MakeSQLRunner(db)
+
+	tdb.Exec(t, "CREATE TABLE t (k INT PRIMARY KEY, v INT, INDEX idx (v))")
+	tdb.Exec(t, "INSERT INTO t VALUES (1, 1), (2, 2), (3, 3)")
+	table := desctestutils.TestingGetPublicTableDescriptor(kvDB, execCfg.Codec, "defaultdb", "t")
+	idx, err := table.FindIndexWithName("idx")
+	require.NoError(t, err)
+	indexSpan := table.IndexSpan(execCfg.Codec, idx.GetID())
+	indexSuffix := indexSpan.Key[len(execCfg.Codec.TablePrefix(uint32(table.GetID()))):]
+
+	// A partition in the middle of the index span, and a subzone of another
+	// index which must be ignored.
+	partitionStart := append(indexSpan.Key[:len(indexSpan.Key):len(indexSpan.Key)], 0x89)
+	partitionEnd := partitionStart.PrefixEnd()
+	zone := &zonepb.ZoneConfig{
+		Subzones: []zonepb.Subzone{
+			{IndexID: uint32(idx.GetID()), PartitionName: "us-east1"},
+			{IndexID: uint32(idx.GetID()) + 1, PartitionName: "us-west1"},
+		},
+		SubzoneSpans: []zonepb.SubzoneSpan{
+			{Key: append(indexSuffix[:len(indexSuffix):len(indexSuffix)], 0x89), SubzoneIndex: 0},
+			{Key: indexSuffix.PrefixEnd(), SubzoneIndex: 1},
+		},
+	}
+	spans := indexPartitionSpans(execCfg.Codec, table.GetID(), idx.GetID(), indexSpan, zone)
+	require.Equal(t, []indexPartitionSpan{
+		{span: roachpb.Span{Key: indexSpan.Key, EndKey: partitionStart}},
+		{region: "us-east1", span: roachpb.Span{Key: partitionStart, EndKey: partitionEnd}},
+		{span: roachpb.Span{Key: partitionEnd, EndKey: indexSpan.EndKey}},
+	}, spans)
+
+	interceptor := &regionTagInterceptor{}
+	defer log.InterceptWith(ctx, interceptor)()
+	require.NoError(t, clearIndexSpans(ctx, &execCfg, spans))
+
+	kvs, err := kvDB.Scan(ctx, indexSpan.Key, indexSpan.EndKey, 0 /* maxRows */)
+	require.NoError(t, err)
+	require.Empty(t, kvs)
+	interceptor.Lock()
+	defer interceptor.Unlock()
+	require.Len(t, interceptor.regions, 1)
+	require.Contains(t, interceptor.regions[0], "us-east1")
+}
+
+func TestCoalesceIndexSpans(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	codec := keys.SystemSQLCodec
+	table := tabledesc.NewBuilder(&descpb.TableDescriptor{ID: 104, Name: "t"}).BuildImmutableTable()
+	indexSpan := func(start, end descpb.IndexID) roachpb.Span {
+		return roachpb.Span{
+			Key:    table.IndexSpan(codec, start).Key,
+			EndKey: table.IndexSpan(codec, end).EndKey,
+		}
+	}
+
+	// The dropped indexes 2 to 5 are adjacent, as are 7 and 8. The live index
+	// 6 separates 5 from 7, and the live index 9 separates 8 from 10.
+	groups := coalesceIndexSpans(codec, table, []descpb.IndexID{7, 3, 5, 2, 8, 4, 10})
+	require.Equal(t, []indexSpanGroup{
+		{indexIDs: []descpb.IndexID{2, 3, 4, 5}, span: indexSpan(2, 5)},
+		{indexIDs: []descpb.IndexID{7, 8}, span: indexSpan(7, 8)},
+		{indexIDs: []descpb.IndexID{10}, span: indexSpan(10, 10)},
+	}, groups)
+
+	// A single dropped index is left on its own.
+	require.Equal(t, []indexSpanGroup{
+		{indexIDs: []descpb.IndexID{3}, span: indexSpan(3, 3)},
+	}, coalesceIndexSpans(codec, table, []descpb.IndexID{3}))
+}
+
+// TestGCIndexesInIndexIDOrder ensures that the dropped indexes of a table are
+// cleared in ascending index ID order, regardless of the order in which they
+// appear in the progress of the job.
+func TestGCIndexesInIndexIDOrder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+
+	var clearedSpans []roachpb.Span
+	srv, db, kvDB := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			JobsTestingKnobs: jobs.NewTestingKnobsWithShortIntervals(),
+			GCJob: &sql.GCJobTestingKnobs{
+				RunAfterClearRange: func(span roachpb.Span) {
+					clearedSpans = append(clearedSpans, span)
+				},
+			},
+		},
+	})
+	defer srv.Stopper().Stop(ctx)
+	execCfg := srv.ExecutorConfig().(sql.ExecutorConfig)
+	tdb := sqlutils.MakeSQLRunner(db)
+
+	tdb.Exec(t, "CREATE TABLE t (k INT PRIMARY KEY, a INT, b INT, c INT, INDEX (a), INDEX (b), INDEX (c))")
+	table := desctestutils.TestingGetPublicTableDescriptor(kvDB, execCfg.Codec, "defaultdb", "t")
+
+	details := &jobspb.SchemaChangeGCDetails{ParentID: table.GetID()}
+	progress := &jobspb.SchemaChangeGCProgress{
+		Indexes: []jobspb.SchemaChangeGCProgress_IndexProgress{
+			{IndexID: 4, Status: jobspb.SchemaChangeGCProgress_DELETING},
+			{IndexID: 2, Status: jobspb.SchemaChangeGCProgress_DELETING},
+			{IndexID: 3, Status: jobspb.SchemaChangeGCProgress_DELETING},
+		},
+	}
+	deferred, err := gcIndexes(ctx, &execCfg, details, progress)
+	require.NoError(t, err)
+	require.False(t, deferred)
+	require.Equal(t, []roachpb.Span{
+		table.IndexSpan(execCfg.Codec, 2),
+		table.IndexSpan(execCfg.Codec, 3),
+		table.IndexSpan(execCfg.Codec, 4),
+	}, clearedSpans)
+	for _, index := range progress.Indexes {
+		require.Equal(t, jobspb.SchemaChangeGCProgress_DELETED, index.Status)
+	}
+}
@@ -27,6 +27,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -36,7 +38,9 @@ import (
 var maxDeadline = timeutil.Unix(0, math.MaxInt64)
 
 // refreshTables updates the status of tables/indexes that are waiting to be
-// GC'd.
+// GC'd. The expiration of a table is measured from its entry in
+// tableExpirationBases, which is its drop time unless a later reference to
+// the table was recorded.
 // It returns whether or not any index/table has expired and the duration until
 // the next index/table expires.
 func refreshTables(
@@ -44,6 +48,7 @@ func refreshTables(
 	execCfg *sql.ExecutorConfig,
 	tableIDs []descpb.ID,
 	tableDropTimes map[descpb.ID]int64,
+	tableExpirationBases map[descpb.ID]int64,
 	indexDropTimes map[descpb.IndexID]int64,
 	jobID jobspb.JobID,
 	progress *jobspb.SchemaChangeGCProgress,
@@ -56,7 +61,7 @@ func refreshTables(
 			execCfg,
 			jobID,
 			tableID,
-			tableDropTimes, indexDropTimes,
+			tableDropTimes, tableExpirationBases, indexDropTimes,
 			progress,
 		)
 		expired = expired || tableHasExpiredElem
@@ -69,6 +74,9 @@ func refreshTables(
 	if expired || haveAnyMissing {
 		persistProgress(ctx, execCfg, jobID, progress, sql.RunningStatusWaitingGC)
 	}
+	if m := gcMetrics(execCfg); m != nil {
+		m.setOldestPendingDrop(jobID, oldestPendingDropTime(progress, tableDropTimes, indexDropTimes))
+	}
 
 	return expired, earliestDeadline
 }
@@ -86,11 +94,10 @@ func updateStatusForGCElements(
 	jobID jobspb.JobID,
 	tableID descpb.ID,
 	tableDropTimes map[descpb.ID]int64,
+	tableExpirationBases map[descpb.ID]int64,
 	indexDropTimes map[descpb.IndexID]int64,
 	progress *jobspb.SchemaChangeGCProgress,
 ) (expired, missing bool, timeToNextTrigger time.Time) {
-	defTTL := execCfg.DefaultZoneConfig.GC.TTLSeconds
-	cfg := execCfg.SystemConfig.GetSystemConfig()
 	protectedtsCache := execCfg.ProtectedTimestampProvider
 
 	earliestDeadline := timeutil.Unix(0, int64(math.MaxInt64))
@@ -101,16 +108,18 @@ func updateStatusForGCElements(
 			return err
 		}
 
-		zoneCfg, err := cfg.GetZoneConfigForObject(execCfg.Codec, uint32(tableID))
+		zoneCfg, tableTTL, err := getTableZoneConfigAndTTL(execCfg, tableID)
 		if err != nil {
 			log.Errorf(ctx, "zone config for desc: %d, err = %+v", tableID, err)
 			return nil
 		}
-		tableTTL := getTableTTL(defTTL, zoneCfg)
 
 		// Update the status of the table if the table was dropped.
 		if table.Dropped() {
-			deadline := updateTableStatus(ctx, execCfg, jobID, int64(tableTTL), table, tableDropTimes, progress)
+			maybeWarnExcessiveTTL(ctx, execCfg, tableID, 0 /* indexID */, int64(tableTTL))
+			deadline := updateTableStatus(
+				ctx, execCfg, jobID, int64(tableTTL), table, tableDropTimes, tableExpirationBases, progress,
+			)
 			if timeutil.Until(deadline) < 0 {
 				expired = true
 			} else if deadline.Before(earliestDeadline) {
@@ -152,10 +161,10 @@ func updateTableStatus(
 	ttlSeconds int64,
 	table catalog.TableDescriptor,
 	tableDropTimes map[descpb.ID]int64,
+	tableExpirationBases map[descpb.ID]int64,
 	progress *jobspb.SchemaChangeGCProgress,
 ) time.Time {
 	deadline := timeutil.Unix(0, int64(math.MaxInt64))
-	sp := table.TableSpan(execCfg.Codec)
 
 	for i, t := range progress.Tables {
 		droppedTable := &progress.Tables[i]
@@ -163,16 +172,10 @@ func updateTableStatus(
 			continue
 		}
 
-		deadlineNanos := tableDropTimes[t.ID] + ttlSeconds*time.Second.Nanoseconds()
-		deadline = timeutil.Unix(0, deadlineNanos)
-		isProtected, err := isProtected(
-			ctx,
-			jobID,
-			tableDropTimes[t.ID],
-			execCfg,
-			execCfg.SpanConfigKVAccessor,
-			execCfg.ProtectedTimestampProvider,
-			sp,
+		var isProtected bool
+		var err error
+		deadline, isProtected, err = tableGCStatus(
+			ctx, execCfg, jobID, ttlSeconds, table, tableDropTimes, tableExpirationBases,
 		)
 		if err != nil {
 			log.Errorf(ctx, "error checking protection status %v", err)
@@ -184,6 +187,11 @@ func updateTableStatus(
 		}
 		if isProtected {
 			log.Infof(ctx, "a timestamp protection delayed GC of table %d", t.ID)
+			// The table is checked again on the next pass, once a released
+			// protection no longer applies.
+			if _, err := maybeReleaseStaleProtectedTimestamps(ctx, execCfg, t.ID); err != nil {
+				log.Errorf(ctx, "error releasing stale protected timestamps of table %d: %v", t.ID, err)
+			}
 			return maxDeadline
 		}
 
@@ -227,25 +235,14 @@ func updateIndexesStatus(
 			continue
 		}
 
-		sp := table.IndexSpan(execCfg.Codec, idxProgress.IndexID)
-
-		ttlSeconds := getIndexTTL(tableTTL, zoneCfg, idxProgress.IndexID)
-
-		deadlineNanos := indexDropTimes[idxProgress.IndexID] + int64(ttlSeconds)*time.Second.Nanoseconds()
-		deadline := timeutil.Unix(0, deadlineNanos)
-		isProtected, err := isProtected(
-			ctx,
-			jobID,
-			indexDropTimes[idxProgress.IndexID],
-			execCfg,
-			execCfg.SpanConfigKVAccessor,
-			protectedtsCache,
-			sp,
+		ttlSeconds, deadline, isProtected, err := indexGCStatus(
+			ctx, execCfg, jobID, tableTTL, table, protectedtsCache, zoneCfg, idxProgress.IndexID, indexDropTimes,
 		)
 		if err != nil {
 			log.Errorf(ctx, "error checking protection status %v", err)
 			continue
 		}
+		maybeWarnExcessiveTTL(ctx, execCfg, table.GetID(), idxProgress.IndexID, int64(ttlSeconds))
 		if isProtected {
 			log.Infof(ctx, "a timestamp protection delayed GC of index %d from table %d", idxProgress.IndexID, table.GetID())
 			continue
@@ -271,6 +268,78 @@ func updateIndexesStatus(
 
 // Helpers.
 
+// tableGCStatus returns the time at which the given dropped table becomes
+// eligible for GC, and whether a protected timestamp delays its GC.
+func tableGCStatus(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	jobID jobspb.JobID,
+	ttlSeconds int64,
+	table catalog.TableDescriptor,
+	tableDropTimes map[descpb.ID]int64,
+	tableExpirationBases map[descpb.ID]int64,
+) (deadline time.Time, protected bool, err error) {
+	deadline = tableGCDeadline(tableExpirationBases[table.GetID()], ttlSeconds)
+	protected, err = isProtected(
+		ctx,
+		jobID,
+		tableDropTimes[table.GetID()],
+		execCfg,
+		execCfg.SpanConfigKVAccessor,
+		execCfg.ProtectedTimestampProvider,
+		table.TableSpan(execCfg.Codec),
+	)
+	return deadline, protected, err
+}
+
+// indexGCStatus returns the GC TTL of the given dropped index of the table,
+// the time at which it becomes eligible for GC, and whether a protected
+// timestamp delays its GC.
+func indexGCStatus(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	jobID jobspb.JobID,
+	tableTTL int32,
+	table catalog.TableDescriptor,
+	protectedtsCache protectedts.Cache,
+	zoneCfg *zonepb.ZoneConfig,
+	indexID descpb.IndexID,
+	indexDropTimes map[descpb.IndexID]int64,
+) (ttlSeconds int32, deadline time.Time, protected bool, err error) {
+	ttlSeconds = getIndexTTL(tableTTL, zoneCfg, indexID)
+	deadlineNanos := indexDropTimes[indexID] + int64(ttlSeconds)*time.Second.Nanoseconds()
+	deadline = timeutil.Unix(0, deadlineNanos)
+	protected, err = isProtected(
+		ctx,
+		jobID,
+		indexDropTimes[indexID],
+		execCfg,
+		execCfg.SpanConfigKVAccessor,
+		protectedtsCache,
+		table.IndexSpan(execCfg.Codec, indexID),
+	)
+	return ttlSeconds, deadline, protected, err
+}
+
+// getTableZoneConfigAndTTL returns the zone config which applies to the given
+// table and its GC TTL.
+func getTableZoneConfigAndTTL(
+	execCfg *sql.ExecutorConfig, tableID descpb.ID,
+) (*zonepb.ZoneConfig, int32, error) {
+	cfg := execCfg.SystemConfig.GetSystemConfig()
+	zoneCfg, err := cfg.GetZoneConfigForObject(execCfg.Codec, uint32(tableID))
+	if err != nil {
+		return nil, 0, err
+	}
+	return zoneCfg, getTableTTL(execCfg.DefaultZoneConfig.GC.TTLSeconds, zoneCfg), nil
+}
+
+// tableGCDeadline returns the time at which a table whose GC TTL is measured
+// from expirationBaseNanos becomes eligible for GC.
+func tableGCDeadline(expirationBaseNanos int64, ttlSeconds int64) time.Time {
+	return timeutil.Unix(0, expirationBaseNanos+ttlSeconds*time.Second.Nanoseconds())
+}
+
 func getIndexTTL(tableTTL int32, placeholder *zonepb.ZoneConfig, indexID descpb.IndexID) int32 {
 	ttlSeconds := tableTTL
 	if placeholder != nil {
@@ -484,6 +553,17 @@ func refreshTenant(
 			return false, deadlineUnix, nil
 		}
 
+		// The tenant may have been resurrected since the GC job was created. A
+		// missing tenant record is handled when performing the GC.
+		info, err := sql.GetTenantRecord(ctx, execCfg, nil /* txn */, tenID)
+		if err != nil && pgerror.GetPGCode(err) != pgcode.UndefinedObject {
+			return false, time.Time{}, errors.Wrapf(err, "fetching tenant %d", tenID)
+		}
+		if err == nil && info.State != descpb.TenantInfo_DROP {
+			log.Warningf(ctx, "tenant %d is no longer in state DROP, skipping its GC", tenID)
+			return false, time.Time{}, newTenantNotDroppedError(tenID)
+		}
+
 		// At this point, the tenant's keyspace is ready for GC.
 		progress.Tenant.Status = jobspb.SchemaChangeGCProgress_DELETING
 		return true, deadlineUnix, nil
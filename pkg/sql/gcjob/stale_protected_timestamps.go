@@ -0,0 +1,103 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gcjob
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts/ptpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+var staleProtectedTimestampThreshold = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.gc_job.stale_protected_timestamp_threshold",
+	"if positive, the GC job reports the protected timestamp records which "+
+		"delay the GC of a dropped table and protect a timestamp older than "+
+		"this duration as stale",
+	0,
+	settings.NonNegativeDuration,
+)
+
+var releaseStaleProtectedTimestampsEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.gc_job.stale_protected_timestamp_threshold.release.enabled",
+	"if enabled, the GC job releases the stale protected timestamp records "+
+		"which target nothing but the dropped table whose GC they delay; the "+
+		"data they protect, e.g. for a backup, is lost",
+	false,
+)
+
+// maybeReleaseStaleProtectedTimestamps reports the protected timestamp records
+// which delay the GC of the given dropped table and are stale, i.e. protect a
+// timestamp older than sql.gc_job.stale_protected_timestamp_threshold, as they
+// may have been forgotten by the external systems which wrote them. If
+// explicitly enabled, the stale records are released. Only the records whose
+// target is nothing but the dropped table are considered: the records which
+// also protect live schema objects, tenants or the cluster are never touched.
+//
+// It returns whether any record was released.
+func maybeReleaseStaleProtectedTimestamps(
+	ctx context.Context, execCfg *sql.ExecutorConfig, tableID descpb.ID,
+) (released bool, _ error) {
+	threshold := staleProtectedTimestampThreshold.Get(&execCfg.Settings.SV)
+	if threshold <= 0 {
+		return false, nil
+	}
+	release := releaseStaleProtectedTimestampsEnabled.Get(&execCfg.Settings.SV)
+	staleBefore := timeutil.Now().Add(-threshold).UnixNano()
+	err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		released = false
+		state, err := execCfg.ProtectedTimestampProvider.GetState(ctx, txn)
+		if err != nil {
+			return errors.Wrap(err, "failed to get protectedts State")
+		}
+		for i := range state.Records {
+			rec := &state.Records[i]
+			if !targetsOnlyTable(rec, tableID) || rec.Timestamp.WallTime >= staleBefore {
+				continue
+			}
+			if !release {
+				log.Ops.Warningf(ctx,
+					"protected timestamp record %s at %s delays the GC of dropped table %d "+
+						"and is older than %s; it may have been forgotten",
+					rec.ID, rec.Timestamp, tableID, threshold)
+				continue
+			}
+			log.Ops.Warningf(ctx,
+				"releasing protected timestamp record %s at %s which delays the GC of dropped table %d "+
+					"and is older than %s",
+				rec.ID, rec.Timestamp, tableID, threshold)
+			if err := execCfg.ProtectedTimestampProvider.Release(ctx, txn, rec.ID.GetUUID()); err != nil {
+				return errors.Wrapf(err, "releasing protected timestamp record %s", rec.ID)
+			}
+			released = true
+		}
+		return nil
+	})
+	return released, err
+}
+
+// targetsOnlyTable returns true if the protected timestamp record targets
+// nothing but the given table.
+func targetsOnlyTable(rec *ptpb.Record, tableID descpb.ID) bool {
+	if rec.Target == nil {
+		return false
+	}
+	schemaObjects := rec.Target.GetSchemaObjects()
+	return schemaObjects != nil && len(schemaObjects.IDs) == 1 && schemaObjects.IDs[0] == tableID
+}
@@ -11,9 +11,11 @@
 package gcjob
 
 import (
+	"bytes"
 	"context"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
@@ -22,26 +24,63 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 )
 
+// verifyClearRangeEnabled controls whether the GC job verifies that no keys
+// remain in the span of a dropped table or index after clearing it, before
+// marking the element as GC'd.
+var verifyClearRangeEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.gc_job.verify_clear_range.enabled",
+	"if enabled, the GC job scans the span of a dropped table or index after "+
+		"clearing it and only marks it as garbage collected once no keys remain",
+	false,
+)
+
+// clearRangeMaxBytes bounds the amount of data cleared by each ClearRange
+// request issued by the GC job.
+var clearRangeMaxBytes = settings.RegisterByteSizeSetting(
+	settings.TenantWritable,
+	"sql.gc_job.clear_range.max_bytes",
+	"the maximum number of bytes of data cleared by each ClearRange request "+
+		"issued by the GC job, estimated from the stats of the ranges spanned by "+
+		"the request; a range is never split across requests (0 disables the limit)",
+	0,
+	settings.NonNegativeInt,
+)
+
 // gcTables drops the table data and descriptor of tables that have an expired
 // deadline and updates the job details to mark the work it did.
 // The job progress is updated in place, but needs to be persisted to the job.
 func gcTables(
-	ctx context.Context, execCfg *sql.ExecutorConfig, progress *jobspb.SchemaChangeGCProgress,
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	details *jobspb.SchemaChangeGCDetails,
+	progress *jobspb.SchemaChangeGCProgress,
 ) error {
 	if log.V(2) {
 		log.Infof(ctx, "GC is being considered for tables: %+v", progress.Tables)
 	}
-	for _, droppedTable := range progress.Tables {
+	droppedTables := progress.Tables
+	if prioritizeLargeElementsEnabled.Get(&execCfg.Settings.SV) {
+		droppedTables = tablesBySize(details, droppedTables)
+	}
+	for _, droppedTable := range droppedTables {
 		if droppedTable.Status != jobspb.SchemaChangeGCProgress_DELETING {
 			// Table is not ready to be dropped, or has already been dropped.
 			continue
 		}
+		// Stop promptly if the job was canceled or paused, keeping the
+		// progress made on the tables which were already GC'd.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
 		var table catalog.TableDescriptor
 		if err := sql.DescsTxn(ctx, execCfg, func(ctx context.Context, txn *kv.Txn, col *descs.Collection) (err error) {
@@ -64,26 +103,124 @@ func gcTables(
 			continue
 		}
 
-		// First, delete all the table data.
-		if err := ClearTableData(
-			ctx, execCfg.DB, execCfg.DistSender, execCfg.Codec, &execCfg.Settings.SV, table,
-		); err != nil {
-			return errors.Wrapf(err, "clearing data for table %d", table.GetID())
-		}
+		dataID := tableDataID(details, table.GetID())
+		ctx := withChunkClearedHook(ctx, func(endKey roachpb.Key) {
+			if tp := tableProgress(progress, table.GetID()); tp != nil {
+				tp.ClearedHighWaterKey = advanceHighWaterKey(tp.ClearedHighWaterKey, endKey)
+			}
+			if fn := execCfg.GCJobTestingKnobs.RunAfterChunkCleared; fn != nil {
+				fn(endKey)
+			}
+		})
+		if lockDescriptorForDeleteEnabled.Get(&execCfg.Settings.SV) {
+			if err := clearTableWithDescriptorLock(ctx, execCfg, table, dataID); err != nil {
+				return err
+			}
+		} else {
+			// First, delete all the table data.
+			if err := clearAndVerifyTableData(ctx, execCfg, table, dataID); err != nil {
+				return err
+			}
 
-		// Finished deleting all the table data, now delete the table meta data.
-		if err := sql.DeleteTableDescAndZoneConfig(
-			ctx, execCfg.DB, execCfg.Settings, execCfg.Codec, table,
-		); err != nil {
-			return errors.Wrapf(err, "dropping table descriptor for table %d", table.GetID())
+			// Finished deleting all the table data, now delete the table meta data.
+			if err := sql.DeleteTableDescAndZoneConfig(
+				ctx, execCfg.DB, execCfg.Settings, execCfg.Codec, table,
+			); err != nil {
+				return errors.Wrapf(err, "dropping table descriptor for table %d", table.GetID())
+			}
 		}
 
 		// Update the details payload to indicate that the table was dropped.
 		markTableGCed(ctx, table.GetID(), progress)
+		recordGCProgress(ctx)
+		if fn := execCfg.GCJobTestingKnobs.RunAfterGCElement; fn != nil {
+			fn(table.GetID(), 0 /* indexID */)
+		}
 	}
 	return nil
 }
 
+// clearAndVerifyTableData deletes all of the data in the specified table,
+// which is stored under the prefix of dataID, and optionally verifies that no
+// data remains.
+func clearAndVerifyTableData(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	table catalog.TableDescriptor,
+	dataID descpb.ID,
+) error {
+	if err := clearTableData(
+		ctx, execCfg.DB, execCfg.DistSender, execCfg.Codec, &execCfg.Settings.SV, table, dataID,
+	); err != nil {
+		return errors.Wrapf(err, "clearing data for table %d", table.GetID())
+	}
+	dataPrefix := execCfg.Codec.TablePrefix(uint32(dataID))
+	dataSpan := roachpb.Span{Key: dataPrefix, EndKey: dataPrefix.PrefixEnd()}
+	if err := maybeVerifySpanCleared(ctx, execCfg, dataSpan); err != nil {
+		return errors.Wrapf(err, "verifying data was cleared for table %d", table.GetID())
+	}
+	return nil
+}
+
+// clearTableWithDescriptorLock deletes all of the data in the specified
+// table, and then its descriptor and zone config in a short transaction
+// holding an exclusive lock on the descriptor. The descriptor read before the
+// data is cleared serves as a marker: it is only deleted if it is unchanged,
+// so that a descriptor written under the ID of the table in the meantime, e.g.
+// by a restore re-creating a table with the same ID, is left in place. The
+// data is cleared outside of any transaction, so that a retry of the
+// transaction does not clear it again.
+func clearTableWithDescriptorLock(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	table catalog.TableDescriptor,
+	dataID descpb.ID,
+) error {
+	descKey := catalogkeys.MakeDescMetadataKey(execCfg.Codec, table.GetID())
+	marker, err := execCfg.DB.Get(ctx, descKey)
+	if err != nil {
+		return errors.Wrapf(err, "reading descriptor of table %d", table.GetID())
+	}
+	if err := clearAndVerifyTableData(ctx, execCfg, table, dataID); err != nil {
+		return err
+	}
+	return execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		// The system config trigger, if needed, anchors the transaction on the
+		// system config range, which must happen before the lock is acquired.
+		if !execCfg.Settings.Version.IsActive(
+			ctx, clusterversion.DisableSystemConfigGossipTrigger,
+		) {
+			if err := txn.DeprecatedSetSystemConfigTrigger(execCfg.Codec.ForSystemTenant()); err != nil {
+				return err
+			}
+		}
+		cur, err := txn.GetForUpdate(ctx, descKey)
+		if err != nil {
+			return errors.Wrapf(err, "locking descriptor of table %d", table.GetID())
+		}
+		if !descriptorValueEqual(marker, cur) {
+			log.Warningf(ctx,
+				"descriptor of table %d was rewritten while its data was cleared, leaving it in place",
+				table.GetID())
+			return nil
+		}
+		log.Infof(ctx, "removing table descriptor and zone config for table %d", table.GetID())
+		return errors.Wrapf(
+			sql.DeleteTableDescAndZoneConfigInTxn(ctx, txn, execCfg.Settings, execCfg.Codec, table),
+			"dropping table descriptor for table %d", table.GetID(),
+		)
+	})
+}
+
+// descriptorValueEqual returns whether the two reads of a descriptor key
+// returned the same descriptor, or both found none.
+func descriptorValueEqual(a, b kv.KeyValue) bool {
+	if !a.Exists() || !b.Exists() {
+		return a.Exists() == b.Exists()
+	}
+	return bytes.Equal(a.Value.TagAndDataBytes(), b.Value.TagAndDataBytes())
+}
+
 // ClearTableData deletes all of the data in the specified table.
 func ClearTableData(
 	ctx context.Context,
@@ -92,6 +229,20 @@ func ClearTableData(
 	codec keys.SQLCodec,
 	sv *settings.Values,
 	table catalog.TableDescriptor,
+) error {
+	return clearTableData(ctx, db, distSender, codec, sv, table, table.GetID())
+}
+
+// clearTableData deletes all of the data in the specified table, which is
+// stored under the prefix of dataID.
+func clearTableData(
+	ctx context.Context,
+	db *kv.DB,
+	distSender *kvcoord.DistSender,
+	codec keys.SQLCodec,
+	sv *settings.Values,
+	table catalog.TableDescriptor,
+	dataID descpb.ID,
 ) error {
 	// If DropTime isn't set, assume this drop request is from a version
 	// 1.1 server and invoke legacy code that uses DeleteRange and range GC.
@@ -101,13 +252,20 @@ func ClearTableData(
 	}
 	log.Infof(ctx, "clearing data for table %d", table.GetID())
 
-	tableKey := roachpb.RKey(codec.TablePrefix(uint32(table.GetID())))
+	tableKey := roachpb.RKey(codec.TablePrefix(uint32(dataID)))
 	tableSpan := roachpb.RSpan{Key: tableKey, EndKey: tableKey.PrefixEnd()}
-	return clearSpanData(ctx, db, distSender, tableSpan)
+	return clearSpanData(ctx, db, distSender, tableSpan, clearRangeMaxBytes.Get(sv))
 }
 
+// clearSpanData issues ClearRange requests over the given span, one per batch
+// of ranges. If maxBytes is positive, a batch is also cut once the ranges in
+// it hold at least maxBytes of data.
 func clearSpanData(
-	ctx context.Context, db *kv.DB, distSender *kvcoord.DistSender, span roachpb.RSpan,
+	ctx context.Context,
+	db *kv.DB,
+	distSender *kvcoord.DistSender,
+	span roachpb.RSpan,
+	maxBytes int64,
 ) error {
 
 	// ClearRange requests lays down RocksDB range deletion tombstones that have
@@ -134,6 +292,7 @@ func clearSpanData(
 	const waitTime = 500 * time.Millisecond
 
 	var n int
+	var batchBytes int64
 	lastKey := span.Key
 	ri := kvcoord.MakeRangeIterator(distSender)
 	timer := timeutil.NewTimer()
@@ -144,7 +303,14 @@ func clearSpanData(
 			return ri.Error()
 		}
 
-		if n++; n >= batchSize || !ri.NeedAnother(span) {
+		if maxBytes > 0 {
+			rangeBytes, err := rangeSizeBytes(ctx, db, ri.Desc(), span)
+			if err != nil {
+				return err
+			}
+			batchBytes += rangeBytes
+		}
+		if n++; n >= batchSize || (maxBytes > 0 && batchBytes >= maxBytes) || !ri.NeedAnother(span) {
 			endKey := ri.Desc().EndKey
 			if span.EndKey.Less(endKey) {
 				endKey = span.EndKey
@@ -160,7 +326,10 @@ func clearSpanData(
 			if err := db.Run(ctx, &b); err != nil {
 				return errors.Wrapf(err, "clear range %s - %s", lastKey, endKey)
 			}
+			recordGCProgress(ctx)
+			recordChunkCleared(ctx, endKey.AsRawKey())
 			n = 0
+			batchBytes = 0
 			lastKey = endKey
 			timer.Reset(waitTime)
 			select {
@@ -178,3 +347,117 @@ func clearSpanData(
 
 	return nil
 }
+
+type chunkClearedHookKey struct{}
+
+// withChunkClearedHook returns a context under which clearSpanData passes the
+// end key of every chunk it clears to fn.
+func withChunkClearedHook(ctx context.Context, fn func(endKey roachpb.Key)) context.Context {
+	return context.WithValue(ctx, chunkClearedHookKey{}, fn)
+}
+
+// recordChunkCleared reports that the span up to endKey was cleared, if the
+// context carries a hook installed by withChunkClearedHook.
+func recordChunkCleared(ctx context.Context, endKey roachpb.Key) {
+	if fn, ok := ctx.Value(chunkClearedHookKey{}).(func(roachpb.Key)); ok {
+		fn(endKey)
+	}
+}
+
+// advanceHighWaterKey returns the greater of the two keys. The span of an
+// element can be cleared again from its start, e.g. when the transaction
+// holding the lock on the descriptor of a table is retried, in which case the
+// high-water key recorded in the progress is kept.
+func advanceHighWaterKey(highWater, endKey roachpb.Key) roachpb.Key {
+	if highWater.Compare(endKey) >= 0 {
+		return highWater
+	}
+	return endKey
+}
+
+// tableProgress returns the progress entry of the given table, or nil if
+// there is none.
+func tableProgress(
+	progress *jobspb.SchemaChangeGCProgress, id descpb.ID,
+) *jobspb.SchemaChangeGCProgress_TableProgress {
+	for i := range progress.Tables {
+		if progress.Tables[i].ID == id {
+			return &progress.Tables[i]
+		}
+	}
+	return nil
+}
+
+// rangeSizeBytes returns the total size of the data in the given range, as
+// per its stats. The range may extend beyond the span being cleared, in which
+// case the amount of data cleared from it is overestimated.
+func rangeSizeBytes(
+	ctx context.Context, db *kv.DB, desc *roachpb.RangeDescriptor, span roachpb.RSpan,
+) (int64, error) {
+	key := desc.StartKey
+	if key.Less(span.Key) {
+		key = span.Key
+	}
+	var b kv.Batch
+	b.AddRawRequest(&roachpb.RangeStatsRequest{
+		RequestHeader: roachpb.RequestHeader{
+			Key: key.AsRawKey(),
+		},
+	})
+	if err := db.Run(ctx, &b); err != nil {
+		return 0, errors.Wrapf(err, "fetching stats of range %s", desc.RSpan())
+	}
+	stats := b.RawResponse().Responses[0].GetInner().(*roachpb.RangeStatsResponse).MVCCStats
+	return stats.Total(), nil
+}
+
+// spanSizeBytes returns the total size of the data in the ranges overlapping
+// the given span, as per their stats. Ranges which extend beyond the span are
+// counted whole, so the size may be overestimated.
+func spanSizeBytes(ctx context.Context, execCfg *sql.ExecutorConfig, span roachpb.Span) (int64, error) {
+	start, err := keys.Addr(span.Key)
+	if err != nil {
+		return 0, err
+	}
+	end, err := keys.Addr(span.EndKey)
+	if err != nil {
+		return 0, err
+	}
+	rSpan := roachpb.RSpan{Key: start, EndKey: end}
+	var sizeBytes int64
+	ri := kvcoord.MakeRangeIterator(execCfg.DistSender)
+	for ri.Seek(ctx, rSpan.Key, kvcoord.Ascending); ; ri.Next(ctx) {
+		if !ri.Valid() {
+			return 0, ri.Error()
+		}
+		rangeBytes, err := rangeSizeBytes(ctx, execCfg.DB, ri.Desc(), rSpan)
+		if err != nil {
+			return 0, err
+		}
+		sizeBytes += rangeBytes
+		if !ri.NeedAnother(rSpan) {
+			return sizeBytes, nil
+		}
+	}
+}
+
+// maybeVerifySpanCleared ensures, if enabled by the corresponding cluster
+// setting, that no keys remain in the given span after it has been cleared.
+// An error is returned if any are found, so that the element is not marked as
+// GC'd and the clearing is retried.
+func maybeVerifySpanCleared(ctx context.Context, execCfg *sql.ExecutorConfig, span roachpb.Span) error {
+	if fn := execCfg.GCJobTestingKnobs.RunAfterClearRange; fn != nil {
+		fn(span)
+	}
+	if !verifyClearRangeEnabled.Get(&execCfg.Settings.SV) {
+		return nil
+	}
+	kvs, err := execCfg.DB.Scan(ctx, span.Key, span.EndKey, 1 /* maxRows */)
+	if err != nil {
+		return errors.Wrapf(err, "scanning span %s", span)
+	}
+	if len(kvs) > 0 {
+		return errors.Errorf("found residual key %s in cleared span %s", kvs[0].Key, span)
+	}
+	return nil
+}
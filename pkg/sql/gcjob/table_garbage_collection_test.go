@@ -0,0 +1,247 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gcjob
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGCTablesCanceledBetweenTables ensures that gcTables stops between two
+// tables when its context is canceled, and that the progress reflects the
+// tables which were GC'd before the cancellation.
+func TestGCTablesCanceledBetweenTables(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var gcElements int
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			JobsTestingKnobs: jobs.NewTestingKnobsWithShortIntervals(),
+			GCJob: &sql.GCJobTestingKnobs{
+				RunAfterGCElement: func(descpb.ID, descpb.IndexID) {
+					// Cancel the pass once the first table has been GC'd.
+					gcElements++
+					cancel()
+				},
+			},
+		},
+	})
+	defer srv.Stopper().Stop(context.Background())
+	execCfg := srv.ExecutorConfig().(sql.ExecutorConfig)
+	tdb := sqlutils.MakeSQLRunner(db)
+
+	tdb.Exec(t, "SET use_declarative_schema_changer = 'off'")
+	tdb.Exec(t, "CREATE DATABASE db")
+	tdb.Exec(t, "CREATE TABLE db.t1 (i INT PRIMARY KEY)")
+	tdb.Exec(t, "CREATE TABLE db.t2 (i INT PRIMARY KEY)")
+	var t1ID, t2ID descpb.ID
+	tdb.QueryRow(t, "SELECT 'db.t1'::REGCLASS::INT").Scan(&t1ID)
+	tdb.QueryRow(t, "SELECT 'db.t2'::REGCLASS::INT").Scan(&t2ID)
+	// The tables are left in the DROP state, since the GC TTL of the job
+	// created by the DROP has not expired.
+	tdb.Exec(t, "DROP DATABASE db CASCADE")
+
+	progress := &jobspb.SchemaChangeGCProgress{
+		Tables: []jobspb.SchemaChangeGCProgress_TableProgress{
+			{ID: t1ID, Status: jobspb.SchemaChangeGCProgress_DELETING},
+			{ID: t2ID, Status: jobspb.SchemaChangeGCProgress_DELETING},
+		},
+	}
+	err := gcTables(ctx, &execCfg, &jobspb.SchemaChangeGCDetails{}, progress)
+	require.True(t, errors.Is(err, context.Canceled), "unexpected error: %v", err)
+	require.Equal(t, 1, gcElements)
+	require.Equal(t, jobspb.SchemaChangeGCProgress_DELETED, progress.Tables[0].Status)
+	require.Equal(t, jobspb.SchemaChangeGCProgress_DELETING, progress.Tables[1].Status)
+}
+
+// TestGCTablesRekeyed ensures that gcTables clears the rekeyed span of a
+// dropped table, rather than the span derived from the table's own ID, when
+// the job details contain a rekey for the table.
+func TestGCTablesRekeyed(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+
+	var clearedSpans []roachpb.Span
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			JobsTestingKnobs: jobs.NewTestingKnobsWithShortIntervals(),
+			GCJob: &sql.GCJobTestingKnobs{
+				RunAfterClearRange: func(span roachpb.Span) {
+					clearedSpans = append(clearedSpans, span)
+				},
+			},
+		},
+	})
+	defer srv.Stopper().Stop(ctx)
+	execCfg := srv.ExecutorConfig().(sql.ExecutorConfig)
+	tdb := sqlutils.MakeSQLRunner(db)
+
+	tdb.Exec(t, "SET use_declarative_schema_changer = 'off'")
+	tdb.Exec(t, "CREATE DATABASE db")
+	tdb.Exec(t, "CREATE TABLE db.dropped (i INT PRIMARY KEY)")
+	// The rekeyed table stands in for the span which the dropped table's data
+	// was restored into.
+	tdb.Exec(t, "CREATE TABLE db.rekeyed (i INT PRIMARY KEY)")
+	tdb.Exec(t, "INSERT INTO db.rekeyed VALUES (1), (2), (3)")
+	var droppedID, rekeyedID descpb.ID
+	tdb.QueryRow(t, "SELECT 'db.dropped'::REGCLASS::INT").Scan(&droppedID)
+	tdb.QueryRow(t, "SELECT 'db.rekeyed'::REGCLASS::INT").Scan(&rekeyedID)
+	tdb.Exec(t, "DROP TABLE db.dropped")
+
+	details := &jobspb.SchemaChangeGCDetails{
+		TableRekeys: map[descpb.ID]descpb.ID{droppedID: rekeyedID},
+	}
+	progress := &jobspb.SchemaChangeGCProgress{
+		Tables: []jobspb.SchemaChangeGCProgress_TableProgress{
+			{ID: droppedID, Status: jobspb.SchemaChangeGCProgress_DELETING},
+		},
+	}
+	require.NoError(t, gcTables(ctx, &execCfg, details, progress))
+	require.Equal(t, jobspb.SchemaChangeGCProgress_DELETED, progress.Tables[0].Status)
+
+	rekeyedPrefix := execCfg.Codec.TablePrefix(uint32(rekeyedID))
+	require.Equal(t, []roachpb.Span{
+		{Key: rekeyedPrefix, EndKey: rekeyedPrefix.PrefixEnd()},
+	}, clearedSpans)
+	tdb.CheckQueryResults(t, "SELECT count(*) FROM db.rekeyed", [][]string{{"0"}})
+}
+
+// TestGCTablesClearRangeMaxBytes ensures that, when the size of the ClearRange
+// requests issued by the GC job is limited, the span of a dropped table is
+// cleared with one request per batch of ranges holding that much data.
+func TestGCTablesClearRangeMaxBytes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			JobsTestingKnobs: jobs.NewTestingKnobsWithShortIntervals(),
+		},
+	})
+	defer srv.Stopper().Stop(ctx)
+	execCfg := srv.ExecutorConfig().(sql.ExecutorConfig)
+	tdb := sqlutils.MakeSQLRunner(db)
+
+	tdb.Exec(t, "SET use_declarative_schema_changer = 'off'")
+	tdb.Exec(t, "CREATE DATABASE db")
+	tdb.Exec(t, "CREATE TABLE db.t (i INT PRIMARY KEY)")
+	tdb.Exec(t, "INSERT INTO db.t SELECT generate_series(1, 10)")
+	// Split the table into 5 ranges, each of which holds some data.
+	tdb.Exec(t, "ALTER TABLE db.t SPLIT AT VALUES (3), (5), (7), (9)")
+	var tableID descpb.ID
+	tdb.QueryRow(t, "SELECT 'db.t'::REGCLASS::INT").Scan(&tableID)
+	tdb.Exec(t, "DROP TABLE db.t")
+	// Every range holds more than a byte of data, so each of them is cleared
+	// by its own request.
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.clear_range.max_bytes = '1B'")
+
+	progress := &jobspb.SchemaChangeGCProgress{
+		Tables: []jobspb.SchemaChangeGCProgress_TableProgress{
+			{ID: tableID, Status: jobspb.SchemaChangeGCProgress_DELETING},
+		},
+	}
+	tr := srv.TracerI().(*tracing.Tracer)
+	recCtx, getRecAndFinish := tracing.ContextWithRecordingSpan(ctx, tr, "gc-tables")
+	require.NoError(t, gcTables(recCtx, &execCfg, &jobspb.SchemaChangeGCDetails{}, progress))
+	require.Equal(t, jobspb.SchemaChangeGCProgress_DELETED, progress.Tables[0].Status)
+
+	clearRangeRE := regexp.MustCompile(`^ClearRange /Table/`)
+	var numRequests int
+	for _, sp := range getRecAndFinish() {
+		for _, l := range sp.Logs {
+			if clearRangeRE.MatchString(l.Msg().StripMarkers()) {
+				numRequests++
+			}
+		}
+	}
+	require.Equal(t, 5, numRequests)
+}
+
+// TestGCTablesClearedHighWaterKey ensures that the high-water key recorded in
+// the progress of a dropped table advances with every chunk of its span which
+// is cleared, up to the end of the span.
+func TestGCTablesClearedHighWaterKey(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	var progress *jobspb.SchemaChangeGCProgress
+	var highWaterKeys []roachpb.Key
+	ctx := context.Background()
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			JobsTestingKnobs: jobs.NewTestingKnobsWithShortIntervals(),
+			GCJob: &sql.GCJobTestingKnobs{
+				RunAfterChunkCleared: func(endKey roachpb.Key) {
+					if progress == nil {
+						return
+					}
+					highWater := progress.Tables[0].ClearedHighWaterKey
+					require.Equal(t, endKey, highWater)
+					highWaterKeys = append(highWaterKeys, highWater)
+				},
+			},
+		},
+	})
+	defer srv.Stopper().Stop(ctx)
+	execCfg := srv.ExecutorConfig().(sql.ExecutorConfig)
+	tdb := sqlutils.MakeSQLRunner(db)
+
+	tdb.Exec(t, "SET use_declarative_schema_changer = 'off'")
+	tdb.Exec(t, "CREATE DATABASE db")
+	tdb.Exec(t, "CREATE TABLE db.t (i INT PRIMARY KEY)")
+	tdb.Exec(t, "INSERT INTO db.t SELECT generate_series(1, 10)")
+	// Split the table into 5 ranges, each of which is cleared as its own
+	// chunk.
+	tdb.Exec(t, "ALTER TABLE db.t SPLIT AT VALUES (3), (5), (7), (9)")
+	var tableID descpb.ID
+	tdb.QueryRow(t, "SELECT 'db.t'::REGCLASS::INT").Scan(&tableID)
+	tdb.Exec(t, "DROP TABLE db.t")
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.clear_range.max_bytes = '1B'")
+
+	progress = &jobspb.SchemaChangeGCProgress{
+		Tables: []jobspb.SchemaChangeGCProgress_TableProgress{
+			{ID: tableID, Status: jobspb.SchemaChangeGCProgress_DELETING},
+		},
+	}
+	require.NoError(t, gcTables(ctx, &execCfg, &jobspb.SchemaChangeGCDetails{}, progress))
+	require.Equal(t, jobspb.SchemaChangeGCProgress_DELETED, progress.Tables[0].Status)
+
+	require.Len(t, highWaterKeys, 5)
+	for i := 1; i < len(highWaterKeys); i++ {
+		require.Less(t, highWaterKeys[i-1].Compare(highWaterKeys[i]), 0,
+			"high-water key %s does not advance past %s", highWaterKeys[i], highWaterKeys[i-1])
+	}
+	tablePrefix := execCfg.Codec.TablePrefix(uint32(tableID))
+	require.Equal(t, tablePrefix.PrefixEnd(), highWaterKeys[len(highWaterKeys)-1])
+	require.Equal(t, tablePrefix.PrefixEnd(), progress.Tables[0].ClearedHighWaterKey)
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -63,6 +64,13 @@ func gcTenant(
 		return errors.AssertionFailedf("GC state for tenant %+v is DELETED yet the tenant row still exists", info)
 	}
 
+	// Re-check the state of the tenant immediately before clearing its data,
+	// as it may have been resurrected since the GC job was created.
+	if info.State != descpb.TenantInfo_DROP {
+		log.Warningf(ctx, "tenant %d is no longer in state DROP, skipping its GC", tenID)
+		return newTenantNotDroppedError(tenID)
+	}
+
 	if err := sql.GCTenantSync(ctx, execCfg, info); err != nil {
 		return errors.Wrapf(err, "gc tenant %d", info.ID)
 	}
@@ -70,3 +78,11 @@ func gcTenant(
 	progress.Tenant.Status = jobspb.SchemaChangeGCProgress_DELETED
 	return nil
 }
+
+// newTenantNotDroppedError returns the error which aborts the GC of a tenant
+// that is no longer in state DROP. The GC job fails permanently, as the data
+// of a live tenant must never be cleared.
+func newTenantNotDroppedError(tenID uint64) error {
+	return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+		"tenant %d is no longer in state DROP, aborting its GC", tenID)
+}
@@ -24,6 +24,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts/ptpb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql"
@@ -31,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/desctestutils"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/gcjob"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
@@ -41,7 +44,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
 	"github.com/stretchr/testify/require"
 )
@@ -341,6 +346,32 @@ SELECT job_id, status, running_status
 	})
 }
 
+// dropWithShortGCTTL sets the GC TTL of the object of the given kind, e.g.
+// TABLE, and name to a second, and drops it with the legacy schema changer.
+// It returns the ID of the GC job created by the drop.
+func dropWithShortGCTTL(t *testing.T, tdb *sqlutils.SQLRunner, kind, name string) (jobID int64) {
+	t.Helper()
+	tdb.Exec(t, "SET CLUSTER SETTING sql.defaults.use_declarative_schema_changer = 'off'")
+	tdb.Exec(t, "SET use_declarative_schema_changer = 'off'")
+	tdb.Exec(t, fmt.Sprintf("ALTER %s %s CONFIGURE ZONE USING gc.ttlseconds = 1", kind, name))
+	tdb.Exec(t, fmt.Sprintf("DROP %s %s CASCADE", kind, name))
+	tdb.QueryRow(t, `
+SELECT job_id
+  FROM [SHOW JOBS]
+ WHERE job_type = 'SCHEMA CHANGE GC' AND description LIKE '%' || $1 || '%'`, name,
+	).Scan(&jobID)
+	return jobID
+}
+
+// requireGCJobSucceeds waits for the GC job with the given ID to complete,
+// and checks that it succeeded.
+func requireGCJobSucceeds(t *testing.T, tdb *sqlutils.SQLRunner, jobID int64) {
+	t.Helper()
+	var status jobs.Status
+	tdb.QueryRow(t, "SELECT status FROM [SHOW JOB WHEN COMPLETE $1]", jobID).Scan(&status)
+	require.Equal(t, jobs.StatusSucceeded, status)
+}
+
 func TestGCJobRetry(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -368,22 +399,853 @@ func TestGCJobRetry(t *testing.T) {
 	s, db, _ := serverutils.StartServer(t, params)
 	defer s.Stopper().Stop(ctx)
 	tdb := sqlutils.MakeSQLRunner(db)
-	tdb.Exec(t, "SET CLUSTER SETTING sql.defaults.use_declarative_schema_changer = 'off';")
-	tdb.Exec(t, "SET use_declarative_schema_changer = 'off';")
 	tdb.Exec(t, "CREATE TABLE foo (i INT PRIMARY KEY)")
-	tdb.Exec(t, "ALTER TABLE foo CONFIGURE ZONE USING gc.ttlseconds = 1;")
-	tdb.Exec(t, "DROP TABLE foo CASCADE;")
+	requireGCJobSucceeds(t, tdb, dropWithShortGCTTL(t, tdb, "TABLE", "foo"))
+}
+
+// TestGCJobRunAfterPerformGC ensures that the RunAfterPerformGC testing knob
+// is invoked once the GC job completes a pass and goes back to waiting.
+func TestGCJobRunAfterPerformGC(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	var performedGC, afterPerformGC int32
+	params := base.TestServerArgs{}
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+	params.Knobs.GCJob = &sql.GCJobTestingKnobs{
+		RunBeforePerformGC: func(_ jobspb.JobID) error {
+			atomic.AddInt32(&performedGC, 1)
+			return nil
+		},
+		RunAfterPerformGC: func(_ jobspb.JobID) error {
+			// The callback must only ever follow a GC pass.
+			if atomic.LoadInt32(&performedGC) <= atomic.LoadInt32(&afterPerformGC) {
+				return errors.New("RunAfterPerformGC called without a preceding GC pass")
+			}
+			atomic.AddInt32(&afterPerformGC, 1)
+			return nil
+		},
+	}
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+	tdb := sqlutils.MakeSQLRunner(db)
+	tdb.Exec(t, "CREATE TABLE foo (i INT PRIMARY KEY)")
+	requireGCJobSucceeds(t, tdb, dropWithShortGCTTL(t, tdb, "TABLE", "foo"))
+	require.Equal(t, atomic.LoadInt32(&performedGC), atomic.LoadInt32(&afterPerformGC))
+	require.Less(t, int32(0), atomic.LoadInt32(&afterPerformGC))
+}
+
+// TestGCJobVerifyClearRange ensures that, when verification of cleared spans
+// is enabled, a dropped table is not marked as GC'd while keys remain in its
+// span.
+func TestGCJobVerifyClearRange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	var kvDB *kv.DB
+	var clearRanges int32
+	params := base.TestServerArgs{}
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+	params.Knobs.GCJob = &sql.GCJobTestingKnobs{
+		RunAfterClearRange: func(span roachpb.Span) {
+			// Inject a residual key in the span after it is first cleared. The
+			// verification must fail, leaving the table to be cleared again.
+			if atomic.AddInt32(&clearRanges, 1) == 1 {
+				require.NoError(t, kvDB.Put(ctx, span.Key.Next(), "residual"))
+			}
+		},
+	}
+	s, db, kvDBFromServer := serverutils.StartServer(t, params)
+	kvDB = kvDBFromServer
+	defer s.Stopper().Stop(ctx)
+	tdb := sqlutils.MakeSQLRunner(db)
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.verify_clear_range.enabled = true")
+	tdb.Exec(t, "CREATE TABLE foo (i INT PRIMARY KEY)")
+	tdb.Exec(t, "INSERT INTO foo VALUES (1), (2), (3)")
+	requireGCJobSucceeds(t, tdb, dropWithShortGCTTL(t, tdb, "TABLE", "foo"))
+	// The table was only marked as GC'd once the residual key was cleared by a
+	// second pass.
+	require.Equal(t, int32(2), atomic.LoadInt32(&clearRanges))
+}
+
+// TestGCJobUnsplitOnly ensures that a GC job with the UnsplitOnly flag set
+// unsplits the ranges of its tables and succeeds without clearing their data.
+func TestGCJobUnsplitOnly(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	var clearRanges int32
+	params := base.TestServerArgs{}
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+	params.Knobs.GCJob = &sql.GCJobTestingKnobs{
+		RunAfterClearRange: func(_ roachpb.Span) {
+			atomic.AddInt32(&clearRanges, 1)
+		},
+	}
+	s, db, kvDB := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+	tdb := sqlutils.MakeSQLRunner(db)
+	tdb.Exec(t, "CREATE TABLE foo (i INT PRIMARY KEY)")
+	tdb.Exec(t, "INSERT INTO foo VALUES (1), (2), (3)")
+	tdb.Exec(t, "ALTER TABLE foo SPLIT AT VALUES (2)")
+	var tableID descpb.ID
+	tdb.QueryRow(t, "SELECT 'foo'::REGCLASS::INT").Scan(&tableID)
+	countManualSplits := func() (count int) {
+		tdb.QueryRow(t, `
+SELECT count(*)
+  FROM crdb_internal.ranges_no_leases
+ WHERE table_id = $1 AND split_enforced_until IS NOT NULL`, tableID,
+		).Scan(&count)
+		return count
+	}
+	require.Equal(t, 1, countManualSplits())
+
+	record := jobs.Record{
+		Description:   "GC test",
+		Username:      security.TestUserName(),
+		DescriptorIDs: descpb.IDs{tableID},
+		Details: jobspb.SchemaChangeGCDetails{
+			Tables: []jobspb.SchemaChangeGCDetails_DroppedID{
+				{ID: tableID, DropTime: 1},
+			},
+			UnsplitOnly: true,
+		},
+		Progress:      jobspb.SchemaChangeGCProgress{},
+		RunningStatus: sql.RunningStatusWaitingGC,
+		NonCancelable: true,
+	}
+	jobRegistry := s.JobRegistry().(*jobs.Registry)
+	sj, err := jobs.TestingCreateAndStartJob(ctx, jobRegistry, kvDB, record)
+	require.NoError(t, err)
+	require.NoError(t, sj.AwaitCompletion(ctx))
+	job, err := jobRegistry.LoadJob(ctx, sj.ID())
+	require.NoError(t, err)
+	require.Equal(t, jobs.StatusSucceeded, job.Status())
+
+	require.Equal(t, 0, countManualSplits())
+	tdb.CheckQueryResults(t, "SELECT count(*) FROM foo", [][]string{{"3"}})
+	require.Equal(t, int32(0), atomic.LoadInt32(&clearRanges))
+}
+
+// TestGCJobUnsplitOrder ensures that a GC job unsplits the ranges of its
+// tables either before or after clearing their data, depending on the
+// sql.gc_job.unsplit_after_clear.enabled setting, and completes in both cases.
+func TestGCJobUnsplitOrder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	type event struct {
+		unsplitJobID jobspb.JobID
+		clearedSpan  roachpb.Span
+	}
+	var mu syncutil.Mutex
+	var events []event
+	params := base.TestServerArgs{}
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+	params.Knobs.GCJob = &sql.GCJobTestingKnobs{
+		RunAfterUnsplitRanges: func(jobID jobspb.JobID) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event{unsplitJobID: jobID})
+		},
+		RunAfterClearRange: func(span roachpb.Span) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event{clearedSpan: span})
+		},
+	}
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+	codec := s.ExecutorConfig().(sql.ExecutorConfig).Codec
+	tdb := sqlutils.MakeSQLRunner(db)
+
+	for _, unsplitAfterClear := range []bool{false, true} {
+		t.Run(fmt.Sprintf("unsplit-after-clear=%t", unsplitAfterClear), func(t *testing.T) {
+			tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.unsplit_after_clear.enabled = $1", unsplitAfterClear)
+			tableName := fmt.Sprintf("foo_%t", unsplitAfterClear)
+			tdb.Exec(t, fmt.Sprintf("CREATE TABLE %s (i INT PRIMARY KEY)", tableName))
+			tdb.Exec(t, fmt.Sprintf("INSERT INTO %s VALUES (1), (2), (3)", tableName))
+			tdb.Exec(t, fmt.Sprintf("ALTER TABLE %s SPLIT AT VALUES (2)", tableName))
+			var tableID descpb.ID
+			tdb.QueryRow(t, "SELECT $1::REGCLASS::INT", tableName).Scan(&tableID)
+			countManualSplits := func() (count int) {
+				tdb.QueryRow(t, `
+SELECT count(*)
+  FROM crdb_internal.ranges_no_leases
+ WHERE table_id = $1 AND split_enforced_until IS NOT NULL`, tableID,
+				).Scan(&count)
+				return count
+			}
+			require.Equal(t, 1, countManualSplits())
+
+			jobID := dropWithShortGCTTL(t, tdb, "TABLE", tableName)
+			requireGCJobSucceeds(t, tdb, jobID)
+			require.Equal(t, 0, countManualSplits())
+
+			// Keep the events of this job, and check their order.
+			tablePrefix := codec.TablePrefix(uint32(tableID))
+			var order []string
+			mu.Lock()
+			for _, e := range events {
+				if e.unsplitJobID == jobspb.JobID(jobID) {
+					order = append(order, "unsplit")
+				} else if e.clearedSpan.Key.Equal(tablePrefix) {
+					order = append(order, "clear")
+				}
+			}
+			mu.Unlock()
+			expected := []string{"unsplit", "clear"}
+			if unsplitAfterClear {
+				expected = []string{"clear", "unsplit"}
+			}
+			require.Equal(t, expected, order)
+		})
+	}
+}
+
+// TestGCJobFinishEvent ensures that a GC job records an event in the event log
+// once it has finished clearing the data of its tables.
+func TestGCJobFinishEvent(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	params := base.TestServerArgs{}
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+	tdb := sqlutils.MakeSQLRunner(db)
+	tdb.Exec(t, "CREATE TABLE foo (i INT PRIMARY KEY)")
+	var tableID descpb.ID
+	tdb.QueryRow(t, "SELECT 'foo'::REGCLASS::INT").Scan(&tableID)
+	jobID := dropWithShortGCTTL(t, tdb, "TABLE", "foo")
+	requireGCJobSucceeds(t, tdb, jobID)
+
+	var info string
+	tdb.QueryRow(t, `
+SELECT info
+  FROM system.eventlog
+ WHERE "eventType" = 'finish_garbage_collection' AND info::JSONB->>'JobID' = $1`,
+		strconv.FormatInt(jobID, 10),
+	).Scan(&info)
+	require.Contains(t, info, fmt.Sprintf(`"TableIDs":[%d]`, tableID))
+	require.Contains(t, info, `"Status":"succeeded"`)
+}
+
+// TestGCJobTombstone ensures that a GC job records a tombstone in the event
+// log for each table it GCs if sql.gc_job.record_tombstones.enabled is set,
+// which outlives the descriptor of the table.
+func TestGCJobTombstone(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	params := base.TestServerArgs{}
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+	tdb := sqlutils.MakeSQLRunner(db)
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.record_tombstones.enabled = true;")
+	tdb.Exec(t, "CREATE TABLE foo (i INT PRIMARY KEY)")
+	var tableID descpb.ID
+	tdb.QueryRow(t, "SELECT 'foo'::REGCLASS::INT").Scan(&tableID)
+	jobID := dropWithShortGCTTL(t, tdb, "TABLE", "foo")
+	requireGCJobSucceeds(t, tdb, jobID)
+
+	// The descriptor is gone, but the tombstone remains.
+	tdb.CheckQueryResults(t,
+		fmt.Sprintf("SELECT count(*) FROM system.descriptor WHERE id = %d", tableID), [][]string{{"0"}},
+	)
+	var info string
+	tdb.QueryRow(t, `
+SELECT info
+  FROM system.eventlog
+ WHERE "eventType" = 'garbage_collection_tombstone' AND (info::JSONB->>'TableID')::INT = $1`,
+		tableID,
+	).Scan(&info)
+	require.Contains(t, info, fmt.Sprintf(`"JobID":%d`, jobID))
+}
+
+// TestGCJobElementGCedCallback ensures that the RunAfterGCElement knob is
+// called once for each table of a GC job, in the order in which their data
+// was cleared.
+func TestGCJobElementGCedCallback(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	var mu syncutil.Mutex
+	var clearedSpans []roachpb.Span
+	var gcedTableIDs []descpb.ID
+	params := base.TestServerArgs{}
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+	params.Knobs.GCJob = &sql.GCJobTestingKnobs{
+		RunAfterClearRange: func(span roachpb.Span) {
+			mu.Lock()
+			defer mu.Unlock()
+			clearedSpans = append(clearedSpans, span)
+		},
+		RunAfterGCElement: func(tableID descpb.ID, indexID descpb.IndexID) {
+			mu.Lock()
+			defer mu.Unlock()
+			if indexID == 0 {
+				gcedTableIDs = append(gcedTableIDs, tableID)
+			}
+		},
+	}
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+	codec := s.ExecutorConfig().(sql.ExecutorConfig).Codec
+	tdb := sqlutils.MakeSQLRunner(db)
+	tdb.Exec(t, "CREATE DATABASE foo")
+	tableIDs := make(map[descpb.ID]struct{})
+	for _, name := range []string{"t1", "t2", "t3"} {
+		tdb.Exec(t, fmt.Sprintf("CREATE TABLE foo.%s (i INT PRIMARY KEY)", name))
+		var tableID descpb.ID
+		tdb.QueryRow(t, "SELECT $1::REGCLASS::INT", "foo."+name).Scan(&tableID)
+		tableIDs[tableID] = struct{}{}
+	}
+	requireGCJobSucceeds(t, tdb, dropWithShortGCTTL(t, tdb, "DATABASE", "foo"))
+
+	// Keep the tables of the dropped database, in the order in which their
+	// data was cleared.
+	mu.Lock()
+	defer mu.Unlock()
+	var clearedTableIDs []descpb.ID
+	for _, span := range clearedSpans {
+		for tableID := range tableIDs {
+			if span.Key.Equal(codec.TablePrefix(uint32(tableID))) {
+				clearedTableIDs = append(clearedTableIDs, tableID)
+			}
+		}
+	}
+	var gced []descpb.ID
+	for _, tableID := range gcedTableIDs {
+		if _, ok := tableIDs[tableID]; ok {
+			gced = append(gced, tableID)
+		}
+	}
+	require.Len(t, gced, len(tableIDs))
+	require.Equal(t, clearedTableIDs, gced)
+}
+
+// TestGCJobReleasesStaleProtectedTimestamp ensures that the GC job releases a
+// stale protected timestamp record which delays the GC of a dropped table
+// when explicitly enabled.
+func TestGCJobReleasesStaleProtectedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	params := base.TestServerArgs{}
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+	execCfg := s.ExecutorConfig().(sql.ExecutorConfig)
+	tdb := sqlutils.MakeSQLRunner(db)
+	tdb.Exec(t, "SET CLUSTER SETTING kv.protectedts.poll_interval = '10ms'")
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.stale_protected_timestamp_threshold = '1h'")
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.stale_protected_timestamp_threshold.release.enabled = true")
+	tdb.Exec(t, "CREATE TABLE foo (i INT PRIMARY KEY)")
+	var tableID descpb.ID
+	tdb.QueryRow(t, "SELECT 'foo'::REGCLASS::INT").Scan(&tableID)
+
+	// Protect the table at a timestamp older than the threshold, as an
+	// external system which never released its record would have.
+	tablePrefix := execCfg.Codec.TablePrefix(uint32(tableID))
+	rec := &ptpb.Record{
+		ID:              uuid.MakeV4().GetBytes(),
+		Timestamp:       hlc.Timestamp{WallTime: timeutil.Now().Add(-2 * time.Hour).UnixNano()},
+		Mode:            ptpb.PROTECT_AFTER,
+		MetaType:        "test",
+		DeprecatedSpans: []roachpb.Span{{Key: tablePrefix, EndKey: tablePrefix.PrefixEnd()}},
+		Target:          ptpb.MakeSchemaObjectsTarget(descpb.IDs{tableID}),
+	}
+	require.NoError(t, execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		return execCfg.ProtectedTimestampProvider.Protect(ctx, txn, rec)
+	}))
+
+	requireGCJobSucceeds(t, tdb, dropWithShortGCTTL(t, tdb, "TABLE", "foo"))
+
+	require.NoError(t, execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		_, err := execCfg.ProtectedTimestampProvider.GetRecord(ctx, txn, rec.ID.GetUUID())
+		require.True(t, errors.Is(err, protectedts.ErrNotExists), "expected the record to be released, got %v", err)
+		return nil
+	}))
+}
+
+// TestGCJobLocksDescriptorForDelete ensures that, when enabled, the GC job
+// leaves in place a descriptor written under the ID of a dropped table while
+// the data of the table was being cleared.
+func TestGCJobLocksDescriptorForDelete(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	var tableID, blocked int32
+	clearingC := make(chan struct{})
+	unblockC := make(chan struct{})
+	params := base.TestServerArgs{}
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+	params.Knobs.GCJob = &sql.GCJobTestingKnobs{
+		RunAfterClearRange: func(span roachpb.Span) {
+			// Block the GC job once the data of the table is cleared, before it
+			// deletes the descriptor.
+			id := atomic.LoadInt32(&tableID)
+			if id == 0 || !span.Key.Equal(keys.SystemSQLCodec.TablePrefix(uint32(id))) {
+				return
+			}
+			if atomic.CompareAndSwapInt32(&blocked, 0, 1) {
+				close(clearingC)
+				<-unblockC
+			}
+		},
+	}
+	s, db, kvDB := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+	tdb := sqlutils.MakeSQLRunner(db)
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.lock_descriptor_for_delete.enabled = true")
+	tdb.Exec(t, "CREATE TABLE foo (i INT PRIMARY KEY)")
+	tdb.Exec(t, "INSERT INTO foo VALUES (1), (2), (3)")
+	var id int32
+	tdb.QueryRow(t, "SELECT 'foo'::REGCLASS::INT").Scan(&id)
+
+	// Keep a copy of the descriptor of the table, to re-create it later.
+	descKey := catalogkeys.MakeDescMetadataKey(keys.SystemSQLCodec, descpb.ID(id))
+	var desc descpb.Descriptor
+	require.NoError(t, kvDB.GetProto(ctx, descKey, &desc))
+	atomic.StoreInt32(&tableID, id)
+
+	jobID := dropWithShortGCTTL(t, tdb, "TABLE", "foo")
+	<-clearingC
+
+	// Write a descriptor under the ID of the table being GC'd.
+	require.NoError(t, kvDB.Put(ctx, descKey, &desc))
+	close(unblockC)
+	requireGCJobSucceeds(t, tdb, jobID)
+
+	// The re-created descriptor was written after the GC job read the old one,
+	// so it was not deleted in its place.
+	var reused descpb.Descriptor
+	require.NoError(t, kvDB.GetProto(ctx, descKey, &reused))
+	require.NotNil(t, reused.GetTable())
+	require.Equal(t, descpb.ID(id), reused.GetTable().ID)
+	require.False(t, reused.GetTable().Dropped())
+	require.NoError(t, kvDB.Del(ctx, descKey))
+}
+
+// TestGCJobLocksDescriptorForDeleteRetry ensures that a retry of the
+// transaction deleting the descriptor of a dropped table, when
+// sql.gc_job.lock_descriptor_for_delete.enabled is set, does not clear the
+// data of the table again.
+func TestGCJobLocksDescriptorForDeleteRetry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	var tableID, injectedRetries, clearRanges int32
+	params := base.TestServerArgs{}
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+	params.Knobs.Store = &kvserver.StoreTestingKnobs{
+		TestingRequestFilter: func(ctx context.Context, ba roachpb.BatchRequest) *roachpb.Error {
+			id := atomic.LoadInt32(&tableID)
+			if id == 0 || ba.Txn == nil {
+				return nil
+			}
+			descKey := catalogkeys.MakeDescMetadataKey(keys.SystemSQLCodec, descpb.ID(id))
+			for _, ru := range ba.Requests {
+				if del, ok := ru.GetInner().(*roachpb.DeleteRequest); ok && del.Key.Equal(descKey) &&
+					atomic.CompareAndSwapInt32(&injectedRetries, 0, 1) {
+					// Force a retry of the transaction the first time.
+					return roachpb.NewError(roachpb.NewTransactionRetryError(roachpb.RETRY_REASON_UNKNOWN, "injected error"))
+				}
+			}
+			return nil
+		},
+	}
+	params.Knobs.GCJob = &sql.GCJobTestingKnobs{
+		RunAfterClearRange: func(span roachpb.Span) {
+			id := atomic.LoadInt32(&tableID)
+			if id != 0 && span.Key.Equal(keys.SystemSQLCodec.TablePrefix(uint32(id))) {
+				atomic.AddInt32(&clearRanges, 1)
+			}
+		},
+	}
+	s, db, kvDB := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+	tdb := sqlutils.MakeSQLRunner(db)
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.lock_descriptor_for_delete.enabled = true")
+	tdb.Exec(t, "CREATE TABLE foo (i INT PRIMARY KEY)")
+	tdb.Exec(t, "INSERT INTO foo VALUES (1), (2), (3)")
+	var id int32
+	tdb.QueryRow(t, "SELECT 'foo'::REGCLASS::INT").Scan(&id)
+	atomic.StoreInt32(&tableID, id)
+
+	requireGCJobSucceeds(t, tdb, dropWithShortGCTTL(t, tdb, "TABLE", "foo"))
+	require.Equal(t, int32(1), atomic.LoadInt32(&injectedRetries))
+	require.Equal(t, int32(1), atomic.LoadInt32(&clearRanges))
+
+	// The descriptor was deleted once the transaction was retried.
+	descKey := catalogkeys.MakeDescMetadataKey(keys.SystemSQLCodec, descpb.ID(id))
+	descKV, err := kvDB.Get(ctx, descKey)
+	require.NoError(t, err)
+	require.False(t, descKV.Exists())
+}
+
+// TestGCJobKeepsDatabaseZoneConfig ensures that the zone config of a dropped
+// database is left in place once its tables are GC'd when the deletion of
+// database zone configs is disabled.
+func TestGCJobKeepsDatabaseZoneConfig(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	params := base.TestServerArgs{}
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+	tdb := sqlutils.MakeSQLRunner(db)
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.delete_database_zone_config.enabled = false")
+	tdb.Exec(t, "CREATE DATABASE foo")
+	tdb.Exec(t, "CREATE TABLE foo.t (i INT PRIMARY KEY)")
+	var dbID descpb.ID
+	tdb.QueryRow(t, "SELECT id FROM system.namespace WHERE name = 'foo' AND \"parentID\" = 0").Scan(&dbID)
+	requireGCJobSucceeds(t, tdb, dropWithShortGCTTL(t, tdb, "DATABASE", "foo"))
+
+	tdb.CheckQueryResults(t,
+		fmt.Sprintf("SELECT count(*) FROM system.zones WHERE id = %d", dbID),
+		[][]string{{"1"}},
+	)
+}
+
+// TestGCJobDefersHotIndex ensures that the GC of a dropped index is deferred
+// while the index is hot, and that it is GC'd once it has cooled down.
+func TestGCJobDefersHotIndex(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	defer gcjob.SetSmallMaxGCIntervalForTest()()
+	ctx := context.Background()
+
+	var hot int32 = 1
+	var hotChecks, clearRanges int32
+	params := base.TestServerArgs{}
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+	params.Knobs.GCJob = &sql.GCJobTestingKnobs{
+		IndexQueriesPerSecond: func(span roachpb.Span) float64 {
+			atomic.AddInt32(&hotChecks, 1)
+			if atomic.LoadInt32(&hot) == 1 {
+				return 1000
+			}
+			return 0
+		},
+		RunAfterClearRange: func(span roachpb.Span) {
+			atomic.AddInt32(&clearRanges, 1)
+		},
+	}
+	s, db, kvDB := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+	tdb := sqlutils.MakeSQLRunner(db)
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.hot_index_qps_threshold = 100")
+	tdb.Exec(t, "CREATE TABLE foo (i INT PRIMARY KEY, j INT, INDEX foo_j (j))")
+	tdb.Exec(t, "INSERT INTO foo VALUES (1, 1), (2, 2), (3, 3)")
+	splitOffIndex(t, tdb, kvDB, "foo", "foo_j")
+	jobID := dropWithShortGCTTL(t, tdb, "INDEX", "foo@foo_j")
+
+	// Wait for the index to be found hot on a few GC passes, and check that
+	// it was never cleared.
+	testutils.SucceedsSoon(t, func() error {
+		if n := atomic.LoadInt32(&hotChecks); n < 3 {
+			return errors.Newf("index checked %d times", n)
+		}
+		return nil
+	})
+	require.Equal(t, int32(0), atomic.LoadInt32(&clearRanges))
+	tdb.CheckQueryResults(t,
+		fmt.Sprintf("SELECT status FROM [SHOW JOBS] WHERE job_id = %d", jobID),
+		[][]string{{string(jobs.StatusRunning)}},
+	)
+
+	// Once the index cools down it is GC'd.
+	atomic.StoreInt32(&hot, 0)
+	requireGCJobSucceeds(t, tdb, jobID)
+	require.Equal(t, int32(1), atomic.LoadInt32(&clearRanges))
+}
+
+// TestGCJobClearsHotIndexAfterMaxDeferral ensures that the GC of a dropped
+// index which remains hot is only deferred for as long as
+// sql.gc_job.hot_index_max_deferral, after which the index is GC'd anyway.
+func TestGCJobClearsHotIndexAfterMaxDeferral(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	defer gcjob.SetSmallMaxGCIntervalForTest()()
+	ctx := context.Background()
+
+	var hotChecks, clearRanges int32
+	params := base.TestServerArgs{}
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+	params.Knobs.GCJob = &sql.GCJobTestingKnobs{
+		IndexQueriesPerSecond: func(span roachpb.Span) float64 {
+			// The index never cools down.
+			atomic.AddInt32(&hotChecks, 1)
+			return 1000
+		},
+		RunAfterClearRange: func(span roachpb.Span) {
+			atomic.AddInt32(&clearRanges, 1)
+		},
+	}
+	s, db, kvDB := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+	tdb := sqlutils.MakeSQLRunner(db)
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.hot_index_qps_threshold = 100")
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.hot_index_max_deferral = '100ms'")
+	tdb.Exec(t, "CREATE TABLE foo (i INT PRIMARY KEY, j INT, INDEX foo_j (j))")
+	tdb.Exec(t, "INSERT INTO foo VALUES (1, 1), (2, 2), (3, 3)")
+	splitOffIndex(t, tdb, kvDB, "foo", "foo_j")
+	requireGCJobSucceeds(t, tdb, dropWithShortGCTTL(t, tdb, "INDEX", "foo@foo_j"))
+	// The index was deferred at least once before being cleared.
+	require.GreaterOrEqual(t, atomic.LoadInt32(&hotChecks), int32(2))
+	require.Equal(t, int32(1), atomic.LoadInt32(&clearRanges))
+}
+
+// TestGCJobDoesNotDeferIndexSharingRange ensures that the GC of a dropped
+// index whose range also holds the live indexes of the table is not deferred,
+// as the load of the range cannot be attributed to the dropped index.
+func TestGCJobDoesNotDeferIndexSharingRange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	defer gcjob.SetSmallMaxGCIntervalForTest()()
+	ctx := context.Background()
+
+	var hotChecks int32
+	params := base.TestServerArgs{}
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+	params.Knobs.GCJob = &sql.GCJobTestingKnobs{
+		IndexQueriesPerSecond: func(span roachpb.Span) float64 {
+			atomic.AddInt32(&hotChecks, 1)
+			return 1000
+		},
+	}
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+	tdb := sqlutils.MakeSQLRunner(db)
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.hot_index_qps_threshold = 100")
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.hot_index_max_deferral = '0s'")
+	tdb.Exec(t, "CREATE TABLE foo (i INT PRIMARY KEY, j INT, INDEX foo_j (j))")
+	tdb.Exec(t, "INSERT INTO foo VALUES (1, 1), (2, 2), (3, 3)")
+	// Configure the GC TTL on the table rather than on the index, which would
+	// split the index off from the rest of the table.
+	tdb.Exec(t, "ALTER TABLE foo CONFIGURE ZONE USING gc.ttlseconds = 1")
+	tdb.Exec(t, "DROP INDEX foo@foo_j")
 	var jobID int64
 	tdb.QueryRow(t, `
 SELECT job_id
   FROM [SHOW JOBS]
- WHERE job_type = 'SCHEMA CHANGE GC' AND description LIKE '%foo%';`,
+ WHERE job_type = 'SCHEMA CHANGE GC' AND description LIKE '%foo_j%'`,
 	).Scan(&jobID)
-	var status jobs.Status
-	tdb.QueryRow(t,
-		"SELECT status FROM [SHOW JOB WHEN COMPLETE $1]", jobID,
-	).Scan(&status)
-	require.Equal(t, jobs.StatusSucceeded, status)
+	requireGCJobSucceeds(t, tdb, jobID)
+	require.Equal(t, int32(0), atomic.LoadInt32(&hotChecks))
+}
+
+// splitOffIndex splits the ranges of the given index of a table in defaultdb
+// off from the rest of the table, and disables range merges so that they stay
+// split off once the GC job unsplits them.
+func splitOffIndex(t *testing.T, tdb *sqlutils.SQLRunner, kvDB *kv.DB, table, index string) {
+	t.Helper()
+	tdb.Exec(t, "SET CLUSTER SETTING kv.range_merge.queue_enabled = false")
+	desc := desctestutils.TestingGetPublicTableDescriptor(kvDB, keys.SystemSQLCodec, "defaultdb", table)
+	idx, err := desc.FindIndexWithName(index)
+	require.NoError(t, err)
+	sp := desc.IndexSpan(keys.SystemSQLCodec, idx.GetID())
+	for _, key := range []roachpb.Key{sp.Key, sp.EndKey} {
+		require.NoError(t, kvDB.AdminSplit(context.Background(), key, hlc.MaxTimestamp))
+	}
+}
+
+// TestGCJobRecordsEstimatedSizes ensures that, when large elements are
+// prioritized, the GC jobs record the estimated size of the dropped indexes
+// and tables, including those created by the declarative schema changer.
+func TestGCJobRecordsEstimatedSizes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	params := base.TestServerArgs{}
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+	execCfg := s.ExecutorConfig().(sql.ExecutorConfig)
+	tdb := sqlutils.MakeSQLRunner(db)
+	for _, name := range []string{"foo", "bar", "baz", "qux"} {
+		tdb.Exec(t, fmt.Sprintf("CREATE TABLE %[1]s (i INT PRIMARY KEY, j STRING, INDEX %[1]s_j (j))", name))
+		tdb.Exec(t, fmt.Sprintf(
+			"INSERT INTO %s SELECT i, repeat('a', 100) FROM generate_series(1, 1000) AS g(i)", name,
+		))
+	}
+
+	gcDetails := func(jobID int64) jobspb.SchemaChangeGCDetails {
+		job, err := execCfg.JobRegistry.LoadJob(ctx, jobspb.JobID(jobID))
+		require.NoError(t, err)
+		return job.Details().(jobspb.SchemaChangeGCDetails)
+	}
+
+	// The sizes are not estimated unless large elements are prioritized.
+	jobID := dropWithShortGCTTL(t, tdb, "TABLE", "qux")
+	requireGCJobSucceeds(t, tdb, jobID)
+	details := gcDetails(jobID)
+	require.Len(t, details.Tables, 1)
+	require.Zero(t, details.Tables[0].EstimatedSizeBytes)
+
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.prioritize_large_elements.enabled = true")
+	jobID = dropWithShortGCTTL(t, tdb, "INDEX", "foo@foo_j")
+	requireGCJobSucceeds(t, tdb, jobID)
+	details = gcDetails(jobID)
+	require.Len(t, details.Indexes, 1)
+	require.Positive(t, details.Indexes[0].EstimatedSizeBytes)
+
+	jobID = dropWithShortGCTTL(t, tdb, "TABLE", "bar")
+	requireGCJobSucceeds(t, tdb, jobID)
+	details = gcDetails(jobID)
+	require.Len(t, details.Tables, 1)
+	require.Positive(t, details.Tables[0].EstimatedSizeBytes)
+
+	tdb.Exec(t, "SET CLUSTER SETTING sql.defaults.use_declarative_schema_changer = 'on'")
+	tdb.Exec(t, "SET use_declarative_schema_changer = 'on'")
+	tdb.Exec(t, "ALTER TABLE baz CONFIGURE ZONE USING gc.ttlseconds = 1")
+	tdb.Exec(t, "DROP TABLE baz")
+	tdb.QueryRow(t, `
+SELECT job_id
+  FROM [SHOW JOBS]
+ WHERE job_type = 'SCHEMA CHANGE GC' AND description LIKE '%baz%'`,
+	).Scan(&jobID)
+	requireGCJobSucceeds(t, tdb, jobID)
+	details = gcDetails(jobID)
+	require.Len(t, details.Tables, 1)
+	require.Positive(t, details.Tables[0].EstimatedSizeBytes)
+}
+
+// TestGCJobDestructiveOpsInterlock ensures that a GC job neither clears nor
+// unsplits the ranges of a dropped table while the
+// kv.background_gc.destructive_ops.enabled interlock is engaged, and that it
+// completes once the interlock is released.
+func TestGCJobDestructiveOpsInterlock(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	defer gcjob.SetSmallMaxGCIntervalForTest()()
+	ctx := context.Background()
+
+	var passes, clearRanges, unsplits int32
+	params := base.TestServerArgs{}
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+	params.Knobs.GCJob = &sql.GCJobTestingKnobs{
+		RunAfterPerformGC: func(jobID jobspb.JobID) error {
+			atomic.AddInt32(&passes, 1)
+			return nil
+		},
+		RunAfterClearRange: func(span roachpb.Span) {
+			atomic.AddInt32(&clearRanges, 1)
+		},
+		RunAfterUnsplitRanges: func(jobID jobspb.JobID) {
+			atomic.AddInt32(&unsplits, 1)
+		},
+	}
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+	tdb := sqlutils.MakeSQLRunner(db)
+	tdb.Exec(t, "SET CLUSTER SETTING kv.background_gc.destructive_ops.enabled = false")
+	// Unsplit after clearing, so that the GC passes are not held up by the
+	// unsplit of the ranges.
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.unsplit_after_clear.enabled = true")
+	tdb.Exec(t, "CREATE TABLE foo (i INT PRIMARY KEY)")
+	tdb.Exec(t, "INSERT INTO foo VALUES (1), (2), (3)")
+	tdb.Exec(t, "ALTER TABLE foo SPLIT AT VALUES (2)")
+	jobID := dropWithShortGCTTL(t, tdb, "TABLE", "foo")
+
+	// Wait for a few GC passes, and check that none of them cleared or
+	// unsplit anything.
+	testutils.SucceedsSoon(t, func() error {
+		if n := atomic.LoadInt32(&passes); n < 3 {
+			return errors.Newf("%d GC passes", n)
+		}
+		return nil
+	})
+	require.Equal(t, int32(0), atomic.LoadInt32(&clearRanges))
+	require.Equal(t, int32(0), atomic.LoadInt32(&unsplits))
+	tdb.CheckQueryResults(t,
+		fmt.Sprintf("SELECT status, running_status FROM [SHOW JOBS] WHERE job_id = %d", jobID),
+		[][]string{{string(jobs.StatusRunning), string(sql.RunningStatusWaitingForDestructiveOps)}},
+	)
+
+	// Once the interlock is released, the table is GC'd.
+	tdb.Exec(t, "SET CLUSTER SETTING kv.background_gc.destructive_ops.enabled = true")
+	requireGCJobSucceeds(t, tdb, jobID)
+	require.Equal(t, int32(1), atomic.LoadInt32(&clearRanges))
+	require.Equal(t, int32(1), atomic.LoadInt32(&unsplits))
+}
+
+// TestGCJobStallWatchdog ensures that a GC pass which stalls while clearing
+// data is detected by the watchdog, and that the job is retried when
+// configured to fail stalled passes.
+func TestGCJobStallWatchdog(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	var stalls int32
+	stallDetected := make(chan struct{})
+	params := base.TestServerArgs{}
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+	params.Knobs.GCJob = &sql.GCJobTestingKnobs{
+		RunAfterClearRange: func(span roachpb.Span) {
+			// Simulate a wedged ClearRange on the first pass, until the
+			// watchdog notices.
+			<-stallDetected
+		},
+		RunAfterStallDetected: func(jobID jobspb.JobID) {
+			if atomic.AddInt32(&stalls, 1) == 1 {
+				close(stallDetected)
+			}
+		},
+	}
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+	tdb := sqlutils.MakeSQLRunner(db)
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.stall_timeout = '100ms'")
+	tdb.Exec(t, "SET CLUSTER SETTING sql.gc_job.stall_timeout.fail_job.enabled = true")
+	tdb.Exec(t, "CREATE TABLE foo (i INT PRIMARY KEY)")
+	tdb.Exec(t, "INSERT INTO foo VALUES (1), (2), (3)")
+	requireGCJobSucceeds(t, tdb, dropWithShortGCTTL(t, tdb, "TABLE", "foo"))
+	require.GreaterOrEqual(t, atomic.LoadInt32(&stalls), int32(1))
+}
+
+// runGCJobToCompletion creates a GC job with the given details, and waits
+// until the job has finished. It returns the final progress of the job, or
+// the error the job failed with. The caller is expected to have lowered the
+// GC interval with gcjob.SetSmallMaxGCIntervalForTest before starting the
+// server.
+func runGCJobToCompletion(
+	t testing.TB, execCfg *sql.ExecutorConfig, details jobspb.SchemaChangeGCDetails,
+) (*jobspb.SchemaChangeGCProgress, error) {
+	t.Helper()
+	ctx := context.Background()
+	record := jobs.Record{
+		Description:   "GC test job",
+		Details:       details,
+		Progress:      jobspb.SchemaChangeGCProgress{},
+		RunningStatus: sql.RunningStatusWaitingGC,
+		NonCancelable: true,
+	}
+	sj, err := jobs.TestingCreateAndStartJob(ctx, execCfg.JobRegistry, execCfg.DB, record)
+	require.NoError(t, err)
+	if err := sj.AwaitCompletion(ctx); err != nil {
+		return nil, err
+	}
+	job, err := execCfg.JobRegistry.LoadJob(ctx, sj.ID())
+	require.NoError(t, err)
+	return job.Progress().GetSchemaChangeGC(), nil
 }
 
 // TestGCTenant is lightweight test that tests the branching logic in Resume
@@ -403,26 +1265,16 @@ func TestGCResumer(t *testing.T) {
 
 	t.Run("tenant GC job past", func(t *testing.T) {
 		const tenID = 10
-		record := jobs.Record{
-			Details: jobspb.SchemaChangeGCDetails{
-				Tenant: &jobspb.SchemaChangeGCDetails_DroppedTenant{
-					ID:       tenID,
-					DropTime: 1, // guarantees the tenant will expire immediately.
-				},
+		progress, err := runGCJobToCompletion(t, &execCfg, jobspb.SchemaChangeGCDetails{
+			Tenant: &jobspb.SchemaChangeGCDetails_DroppedTenant{
+				ID:       tenID,
+				DropTime: 1, // guarantees the tenant will expire immediately.
 			},
-			Progress: jobspb.SchemaChangeGCProgress{},
-		}
-
-		sj, err := jobs.TestingCreateAndStartJob(ctx, jobRegistry, kvDB, record)
-		require.NoError(t, err)
-		require.NoError(t, sj.AwaitCompletion(ctx))
-		job, err := jobRegistry.LoadJob(ctx, sj.ID())
+		})
 		require.NoError(t, err)
-		require.Equal(t, jobs.StatusSucceeded, job.Status())
 		_, err = sql.GetTenantRecord(ctx, &execCfg, nil /* txn */, tenID)
 		require.EqualError(t, err, `tenant "10" does not exist`)
-		progress := job.Progress()
-		require.Equal(t, jobspb.SchemaChangeGCProgress_DELETED, progress.GetSchemaChangeGC().Tenant.Status)
+		require.Equal(t, jobspb.SchemaChangeGCProgress_DELETED, progress.Tenant.Status)
 	})
 
 	t.Run("tenant GC job soon", func(t *testing.T) {
@@ -453,6 +1305,42 @@ func TestGCResumer(t *testing.T) {
 		require.Equal(t, jobspb.SchemaChangeGCProgress_DELETED, progress.GetSchemaChangeGC().Tenant.Status)
 	})
 
+	t.Run("resurrected tenant GC job", func(t *testing.T) {
+		const tenID = 11
+		require.NoError(t, sql.CreateTenantRecord(
+			ctx, &execCfg, nil, /* txn */
+			&descpb.TenantInfoWithUsage{
+				TenantInfo: descpb.TenantInfo{ID: tenID, State: descpb.TenantInfo_DROP},
+			}),
+		)
+		descKey := catalogkeys.MakeDescMetadataKey(
+			keys.MakeSQLCodec(roachpb.MakeTenantID(tenID)), keys.NamespaceTableID,
+		)
+		require.NoError(t, kvDB.Put(ctx, descKey, "foo"))
+
+		// The tenant is resurrected before its GC job runs.
+		require.NoError(t, sql.ActivateTenant(ctx, &execCfg, nil /* txn */, tenID))
+
+		_, err := runGCJobToCompletion(t, &execCfg, jobspb.SchemaChangeGCDetails{
+			Tenant: &jobspb.SchemaChangeGCDetails_DroppedTenant{
+				ID:       tenID,
+				DropTime: 1, // guarantees the tenant will expire immediately.
+			},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "tenant 11 is no longer in state DROP")
+
+		// Neither the tenant record nor its data were cleared.
+		info, err := sql.GetTenantRecord(ctx, &execCfg, nil /* txn */, tenID)
+		require.NoError(t, err)
+		require.Equal(t, descpb.TenantInfo_ACTIVE, info.State)
+		r, err := kvDB.Get(ctx, descKey)
+		require.NoError(t, err)
+		val, err := r.Value.GetBytes()
+		require.NoError(t, err)
+		require.Equal(t, []byte("foo"), val)
+	})
+
 	t.Run("no tenant and tables in same GC job", func(t *testing.T) {
 		gcDetails := jobspb.SchemaChangeGCDetails{
 			Tenant: &jobspb.SchemaChangeGCDetails_DroppedTenant{
@@ -464,14 +1352,8 @@ func TestGCResumer(t *testing.T) {
 			ID:       100,
 			DropTime: 1,
 		})
-		record := jobs.Record{
-			Details:  gcDetails,
-			Progress: jobspb.SchemaChangeGCProgress{},
-		}
-
-		sj, err := jobs.TestingCreateAndStartJob(ctx, jobRegistry, kvDB, record)
-		require.NoError(t, err)
-		require.Error(t, sj.AwaitCompletion(ctx))
+		_, err := runGCJobToCompletion(t, &execCfg, gcDetails)
+		require.Error(t, err)
 	})
 }
 
@@ -555,7 +1437,7 @@ func TestGCTenant(t *testing.T) {
 		require.EqualError(
 			t,
 			gcClosure(activeTenID, progress),
-			"gc tenant 10: clear tenant: tenant 10 is not in state DROP", activeTenID,
+			"tenant 10 is no longer in state DROP, aborting its GC",
 		)
 	})
 
@@ -889,7 +889,7 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 %token <str> TABLE TABLES TABLESPACE TEMP TEMPLATE TEMPORARY TENANT TENANTS TESTING_RELOCATE TEXT THEN
 %token <str> TIES TIME TIMETZ TIMESTAMP TIMESTAMPTZ TO THROTTLING TRAILING TRACE
 %token <str> TRANSACTION TRANSACTIONS TRANSFER TREAT TRIGGER TRIM TRUE
-%token <str> TRUNCATE TRUSTED TYPE TYPES
+%token <str> TRUNCATE TRUSTED TWO TYPE TYPES
 %token <str> TRACING
 
 %token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLOGGED UNSPLIT
@@ -9520,6 +9520,10 @@ survival_goal_clause:
   {
     $$.val = tree.SurvivalGoalZoneFailure
   }
+| SURVIVE opt_equal TWO REGION FAILURE
+  {
+    $$.val = tree.SurvivalGoalTwoRegionFailure
+  }
 | SURVIVE opt_equal AVAILABILITY ZONE FAILURE
   {
     /* SKIP DOC */
@@ -14309,6 +14313,7 @@ unreserved_keyword:
 | TRIGGER
 | TRUNCATE
 | TRUSTED
+| TWO
 | TYPE
 | TYPES
 | THROTTLING
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
@@ -27,15 +28,40 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/multiregion"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/buildutil"
+	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/errors"
 	"github.com/gogo/protobuf/proto"
 )
 
+// validateGeneratedZoneConfigs, if set, makes the generators of the zone
+// configs of multi-region databases, tables and partitions run their output
+// through the zone config validator before returning it, so that an invalid
+// construction is caught where it is made rather than when it is applied. It
+// is enabled in test builds by default.
+var validateGeneratedZoneConfigs = envutil.EnvOrDefaultBool(
+	"COCKROACH_VALIDATE_GENERATED_MULTI_REGION_ZONE_CONFIGS", buildutil.CrdbTestBuild,
+)
+
+// maybeValidateGeneratedZoneConfig validates the given generated zone config
+// if validateGeneratedZoneConfigs is set. An invalid zone config is a bug in
+// its generator, so an assertion failure is returned.
+func maybeValidateGeneratedZoneConfig(zc *zonepb.ZoneConfig) error {
+	if !validateGeneratedZoneConfigs {
+		return nil
+	}
+	if err := zc.Validate(); err != nil {
+		return errors.NewAssertionErrorWithWrappedErrf(err, "generated invalid zone config %s", zc)
+	}
+	return nil
+}
+
 // LiveClusterRegions is a set representing regions that are live in
 // a given cluster.
 type LiveClusterRegions map[catpb.RegionName]struct{}
@@ -143,7 +169,25 @@ func zoneConfigForMultiRegionDatabase(
 ) (zonepb.ZoneConfig, error) {
 	numVoters, numReplicas := getNumVotersAndNumReplicasForDefaultDatabaseRegions(regionConfig)
 	var constraints []zonepb.ConstraintsConjunction
-	if regionConfig.IsPlacementRestricted() {
+	if regionConfig.IsPrimaryRegionOnlyVoters() {
+		if err := multiregion.ValidatePrimaryRegionOnlyVoters(regionConfig); err != nil {
+			return zonepb.ZoneConfig{}, err
+		}
+		// All replicas in the primary region are voters, so we spell out that the
+		// primary region holds exactly numVoters replicas. This leaves every other
+		// region with a single non-voting replica.
+		constraints = make([]zonepb.ConstraintsConjunction, len(regionConfig.Regions()))
+		for i, region := range regionConfig.Regions() {
+			n := int32(1)
+			if region == regionConfig.PrimaryRegion() {
+				n = numVoters
+			}
+			constraints[i] = zonepb.ConstraintsConjunction{
+				NumReplicas: n,
+				Constraints: []zonepb.Constraint{makeRequiredConstraintForRegion(region)},
+			}
+		}
+	} else if regionConfig.IsPlacementRestricted() {
 		// In a RESTRICTED placement policy, the database zone config has no
 		// non-voters so that REGIONAL BY [TABLE | ROW] can inherit the RESTRICTED
 		// placement. Voter placement will be set at the table/partition level to
@@ -157,8 +201,11 @@ func zoneConfigForMultiRegionDatabase(
 		// builtins.
 		constraints = nil
 	} else {
-		constraints = make([]zonepb.ConstraintsConjunction, len(regionConfig.Regions()))
-		for i, region := range regionConfig.Regions() {
+		// Regions with restricted placement other than the primary region, as
+		// well as prohibited regions, are left out, so that they hold no
+		// non-voting replica.
+		constraints = make([]zonepb.ConstraintsConjunction, len(regionConfig.PlacedRegions()))
+		for i, region := range regionConfig.PlacedRegions() {
 			// Constrain at least 1 (voting or non-voting) replica per region.
 			constraints[i] = zonepb.ConstraintsConjunction{
 				NumReplicas: 1,
@@ -167,21 +214,1238 @@ func zoneConfigForMultiRegionDatabase(
 		}
 	}
 
-	voterConstraints, err := synthesizeVoterConstraints(regionConfig.PrimaryRegion(), regionConfig)
-	if err != nil {
-		return zonepb.ZoneConfig{}, err
+	var voterConstraints []zonepb.ConstraintsConjunction
+	var voterWeights map[catpb.RegionName]int32
+	if regionConfig.HasVoterWeights() {
+		if err := validateVoterWeights(regionConfig, numVoters); err != nil {
+			return zonepb.ZoneConfig{}, err
+		}
+		voterWeights = regionConfig.VoterWeights()
+	} else if regionConfig.HasVoterRegions() {
+		// The voters are spread across the voter regions, while the lease
+		// preference below remains pinned to the primary region.
+		voterWeights = voterWeightsForVoterRegions(regionConfig, numVoters)
+	} else if (regionConfig.HasNonVotingRegions() || regionConfig.HasStandbyRegion()) &&
+		regionConfig.SurvivalGoal() == descpb.SurvivalGoal_REGION_FAILURE {
+		// Under zone survivability all voters are in the primary region, which
+		// is always a voting region. Under region survivability the voters need
+		// to be explicitly kept out of the non-voting and standby regions.
+		var err error
+		voterWeights, err = voterWeightsForVotingRegions(regionConfig, numVoters)
+		if err != nil {
+			return zonepb.ZoneConfig{}, err
+		}
+	} else if regionConfig.HasAvailabilityZones() &&
+		regionConfig.SurvivalGoal() == descpb.SurvivalGoal_ZONE_FAILURE &&
+		!regionConfig.IsPrimaryRegionOnlyVoters() && !regionConfig.IsPlacementRestricted() {
+		// Under zone survivability all voters are in the primary region, unless
+		// it has too few availability zones to survive a zone failure.
+		voterWeights = voterWeightsForAvailabilityZones(regionConfig, numVoters)
+	}
+	if voterWeights != nil {
+		voterConstraints = synthesizeWeightedVoterConstraints(regionConfig.Regions(), voterWeights)
+		// Every region is guaranteed at least one replica, so regions with more
+		// than one voter may require replicas beyond the default count.
+		var minReplicas int32
+		for _, region := range regionConfig.PlacedRegions() {
+			if w := voterWeights[region]; w > 1 {
+				minReplicas += w
+			} else {
+				minReplicas++
+			}
+		}
+		if numReplicas < minReplicas {
+			numReplicas = minReplicas
+		}
+	} else {
+		var err error
+		voterConstraints, err = synthesizeVoterConstraints(regionConfig.PrimaryRegion(), regionConfig)
+		if err != nil {
+			return zonepb.ZoneConfig{}, err
+		}
+	}
+	if regionConfig.HasTieBreakerVoter() {
+		var err error
+		voterConstraints, err = addTieBreakerVoterConstraint(regionConfig, voterConstraints, numVoters)
+		if err != nil {
+			return zonepb.ZoneConfig{}, err
+		}
 	}
 
-	return zonepb.ZoneConfig{
-		NumReplicas: &numReplicas,
-		NumVoters:   &numVoters,
-		LeasePreferences: []zonepb.LeasePreference{
-			{Constraints: []zonepb.Constraint{makeRequiredConstraintForRegion(regionConfig.PrimaryRegion())}},
-		},
+	if extra := regionConfig.ExtraPrimaryRegionNonVoters(); extra > 0 {
+		if regionConfig.IsPlacementRestricted() {
+			return zonepb.ZoneConfig{}, errors.AssertionFailedf(
+				"cannot place extra non-voters in the primary region under restricted placement",
+			)
+		}
+		// The primary region must hold its voters in addition to the extra
+		// non-voters. The voter constraints are left untouched.
+		primaryVoters := numVoters
+		for _, c := range voterConstraints {
+			if region, ok := regionFromConstraints(c.Constraints); ok &&
+				region == regionConfig.PrimaryRegion() && c.NumReplicas > 0 {
+				primaryVoters = c.NumReplicas
+			}
+		}
+		for i := range constraints {
+			if region, ok := regionFromConstraints(constraints[i].Constraints); ok &&
+				region == regionConfig.PrimaryRegion() {
+				constraints[i].NumReplicas = primaryVoters + extra
+			}
+		}
+		numReplicas += extra
+	}
+
+	if regionConfig.HasProhibitedRegions() {
+		// The replicas which are not constrained to a region could be placed in
+		// a prohibited region. Prohibitive constraints cannot be combined with
+		// the per-region conjunctions, so the remaining replicas are pinned to
+		// the other regions instead.
+		pinReplicasToPlacedRegions(regionConfig, constraints, voterConstraints, numVoters, numReplicas)
+	}
+
+	if regionConfig.HasNonVoterStorageClass() {
+		// The storage class can only be required of the replicas of the regions
+		// which hold no voter, as a conjunction applies to voters and
+		// non-voters alike.
+		nonVoterRegions := nonVoterOnlyRegions(regionConfig, voterConstraints, numVoters)
+		for i := range constraints {
+			if region, ok := regionFromConstraints(constraints[i].Constraints); ok && nonVoterRegions[region] {
+				constraints[i].Constraints = append(constraints[i].Constraints, zonepb.Constraint{
+					Type:  zonepb.Constraint_REQUIRED,
+					Value: regionConfig.NonVoterStorageClass(),
+				})
+			}
+		}
+	}
+
+	if regionConfig.HasPrimaryRegionAvailabilityZones() {
+		// This is done last, as the computations above rely on every voter
+		// conjunction holding a single region constraint.
+		voterConstraints = spreadVotersAcrossAvailabilityZones(regionConfig, voterConstraints, numVoters)
+	}
+
+	leasePreferences := []zonepb.LeasePreference{
+		{Constraints: []zonepb.Constraint{makeRequiredConstraintForRegion(regionConfig.PrimaryRegion())}},
+	}
+
+	// The constraints are keyed on the default locality tier up to this
+	// point, as the computations above rely on it to find the region of each
+	// conjunction.
+	for _, c := range constraints {
+		rekeyRegionConstraints(c.Constraints, regionConfig.ConstraintKey(multiregion.ReplicaConstraintKind))
+	}
+	for _, c := range voterConstraints {
+		rekeyRegionConstraints(c.Constraints, regionConfig.ConstraintKey(multiregion.VoterConstraintKind))
+	}
+	for _, p := range leasePreferences {
+		rekeyRegionConstraints(p.Constraints, regionConfig.ConstraintKey(multiregion.LeaseConstraintKind))
+	}
+
+	zc := zonepb.ZoneConfig{
+		NumReplicas:                 &numReplicas,
+		NumVoters:                   &numVoters,
+		LeasePreferences:            leasePreferences,
 		NullVoterConstraintsIsEmpty: true,
 		VoterConstraints:            voterConstraints,
 		Constraints:                 constraints,
-	}, nil
+	}
+	if err := maybeValidateGeneratedZoneConfig(&zc); err != nil {
+		return zonepb.ZoneConfig{}, err
+	}
+	return zc, nil
+}
+
+// nonVoterOnlyRegions returns the regions of the database in which the given
+// voter constraints place no voter: every region but the one all of the voters
+// are constrained to, or the regions left out of voter constraints which pin
+// all numVoters voters, as well as the non-voting regions.
+func nonVoterOnlyRegions(
+	regionConfig multiregion.RegionConfig,
+	voterConstraints []zonepb.ConstraintsConjunction,
+	numVoters int32,
+) map[catpb.RegionName]bool {
+	ret := make(map[catpb.RegionName]bool)
+	for _, region := range regionConfig.NonVotingRegions() {
+		ret[region] = true
+	}
+	votersPinned := false
+	var numPinned int32
+	voterRegions := make(map[catpb.RegionName]bool)
+	for _, c := range voterConstraints {
+		if c.NumReplicas == 0 {
+			votersPinned = true
+		}
+		numPinned += c.NumReplicas
+		if region, ok := regionFromConstraints(c.Constraints); ok {
+			voterRegions[region] = true
+		}
+	}
+	if !votersPinned && numPinned < numVoters {
+		// Some voters float across the regions.
+		return ret
+	}
+	for _, region := range regionConfig.Regions() {
+		if !voterRegions[region] {
+			ret[region] = true
+		}
+	}
+	return ret
+}
+
+// zoneConfigForSystemDatabaseMultiRegion generates a ZoneConfig stub for the
+// system database of a multi-region cluster whose regions are described by
+// the given `regionConfig`. The system database holds ranges which are
+// critical to the whole cluster, so as soon as there are enough regions its
+// voters are spread so as to survive a region failure, even if the survival
+// goal of `regionConfig` only requires surviving zone failures. A stronger
+// survival goal is preserved. The placement options of `regionConfig` which
+// only make sense for user data, such as restricted placement, super regions
+// or voter weights, are disregarded: every region holds a replica, and the
+// leases are preferred in the primary region.
+func zoneConfigForSystemDatabaseMultiRegion(
+	regionConfig multiregion.RegionConfig,
+) (zonepb.ZoneConfig, error) {
+	survivalGoal := regionConfig.SurvivalGoal()
+	if survivalGoal == descpb.SurvivalGoal_ZONE_FAILURE &&
+		multiregion.CanSatisfySurvivalGoal(
+			descpb.SurvivalGoal_REGION_FAILURE, len(regionConfig.Regions()),
+		) == nil {
+		survivalGoal = descpb.SurvivalGoal_REGION_FAILURE
+	}
+	systemRegionConfig := multiregion.MakeRegionConfig(
+		regionConfig.Regions(),
+		regionConfig.PrimaryRegion(),
+		survivalGoal,
+		regionConfig.RegionEnumID(),
+		descpb.DataPlacement_DEFAULT,
+		nil, /* superRegions */
+		// The constraints must still match the locality tiers of the cluster.
+		multiregion.WithConstraintKey(
+			multiregion.ReplicaConstraintKind,
+			regionConfig.ConstraintKey(multiregion.ReplicaConstraintKind),
+		),
+		multiregion.WithConstraintKey(
+			multiregion.VoterConstraintKind,
+			regionConfig.ConstraintKey(multiregion.VoterConstraintKind),
+		),
+		multiregion.WithConstraintKey(
+			multiregion.LeaseConstraintKind,
+			regionConfig.ConstraintKey(multiregion.LeaseConstraintKind),
+		),
+	)
+	return zoneConfigForMultiRegionDatabase(systemRegionConfig)
+}
+
+// transitionalZoneConfigForPrimaryChange generates the zone config of a
+// multi-region database while ALTER DATABASE ... SET PRIMARY REGION changes its
+// primary region to newPrimaryRegion. It is the zone config of the database
+// with the new primary region, with the old primary region kept as a second
+// lease preference, so that the leases drain to the new primary region without
+// a window in which no lease preference applies.
+func transitionalZoneConfigForPrimaryChange(
+	regionConfig multiregion.RegionConfig, newPrimaryRegion catpb.RegionName,
+) (zonepb.ZoneConfig, error) {
+	if !regionConfig.IsValidRegionNameString(string(newPrimaryRegion)) {
+		return zonepb.ZoneConfig{}, pgerror.Newf(
+			pgcode.InvalidName,
+			"region %q has not been added to the database",
+			newPrimaryRegion,
+		)
+	}
+	oldPrimaryRegion := regionConfig.PrimaryRegion()
+	zc, err := zoneConfigForMultiRegionDatabase(regionConfig.WithPrimaryRegion(newPrimaryRegion))
+	if err != nil {
+		return zonepb.ZoneConfig{}, err
+	}
+	if newPrimaryRegion == oldPrimaryRegion {
+		return zc, nil
+	}
+	oldPreference := zonepb.LeasePreference{
+		Constraints: []zonepb.Constraint{makeRequiredConstraintForRegion(oldPrimaryRegion)},
+	}
+	rekeyRegionConstraints(oldPreference.Constraints, regionConfig.ConstraintKey(multiregion.LeaseConstraintKind))
+	zc.LeasePreferences = append(zc.LeasePreferences, oldPreference)
+	return zc, nil
+}
+
+// rekeyRegionConstraints keys the required region constraints in place on the
+// given locality tier key. Other constraints, e.g. the one of the tie-breaker
+// voter, are left untouched.
+func rekeyRegionConstraints(constraints []zonepb.Constraint, key string) {
+	for i := range constraints {
+		if constraints[i].Type == zonepb.Constraint_REQUIRED &&
+			constraints[i].Key == multiregion.DefaultConstraintKey {
+			constraints[i].Key = key
+		}
+	}
+}
+
+// spreadVotersAcrossAvailabilityZones returns the voter constraints with the
+// voters spread across the availability zones of the primary region, as evenly
+// as possible, if the voter constraints place all of the voters in the primary
+// region. Otherwise, the voter constraints are returned unchanged.
+func spreadVotersAcrossAvailabilityZones(
+	regionConfig multiregion.RegionConfig,
+	voterConstraints []zonepb.ConstraintsConjunction,
+	numVoters int32,
+) []zonepb.ConstraintsConjunction {
+	if len(voterConstraints) != 1 || voterConstraints[0].NumReplicas != 0 {
+		return voterConstraints
+	}
+	if region, ok := regionFromConstraints(voterConstraints[0].Constraints); !ok ||
+		region != regionConfig.PrimaryRegion() {
+		return voterConstraints
+	}
+	zones := regionConfig.PrimaryRegionAvailabilityZones()
+	ret := make([]zonepb.ConstraintsConjunction, 0, len(zones))
+	for i, zone := range zones {
+		n := numVoters / int32(len(zones))
+		if int32(i) < numVoters%int32(len(zones)) {
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		ret = append(ret, zonepb.ConstraintsConjunction{
+			NumReplicas: n,
+			Constraints: []zonepb.Constraint{
+				makeRequiredConstraintForRegion(regionConfig.PrimaryRegion()),
+				{Type: zonepb.Constraint_REQUIRED, Key: multiregion.AvailabilityZoneConstraintKey, Value: zone},
+			},
+		})
+	}
+	return ret
+}
+
+// addTieBreakerVoterConstraint returns the voter constraints with one of the
+// voters constrained to the tie-breaker locality tier of the RegionConfig,
+// outside of its regions. The number of voters is unchanged: if the voter
+// constraints pin all of the voters, one of those pinned to the primary region
+// is given up. Otherwise, one fewer voter floats across the regions.
+func addTieBreakerVoterConstraint(
+	regionConfig multiregion.RegionConfig,
+	voterConstraints []zonepb.ConstraintsConjunction,
+	numVoters int32,
+) ([]zonepb.ConstraintsConjunction, error) {
+	// An odd number of voters is kept so that the tie-breaker voter can
+	// actually break ties between the voters placed in the regions.
+	if numVoters%2 == 0 {
+		return nil, errors.AssertionFailedf(
+			"cannot place a tie-breaker voter with an even number of voters %d", numVoters)
+	}
+	ret := make([]zonepb.ConstraintsConjunction, len(voterConstraints), len(voterConstraints)+1)
+	copy(ret, voterConstraints)
+	var pinned int32
+	for i := range ret {
+		if ret[i].NumReplicas == 0 {
+			ret[i].NumReplicas = numVoters
+		}
+		pinned += ret[i].NumReplicas
+	}
+	if pinned >= numVoters {
+		for i := range ret {
+			if region, ok := regionFromConstraints(ret[i].Constraints); ok &&
+				region == regionConfig.PrimaryRegion() && ret[i].NumReplicas > 1 {
+				ret[i].NumReplicas--
+				pinned--
+				break
+			}
+		}
+	}
+	if pinned >= numVoters {
+		return nil, errors.AssertionFailedf(
+			"no voter left to place in the tie-breaker locality among %d voters", numVoters)
+	}
+	key, value := regionConfig.TieBreakerVoter()
+	ret = append(ret, zonepb.ConstraintsConjunction{
+		NumReplicas: 1,
+		Constraints: []zonepb.Constraint{{Type: zonepb.Constraint_REQUIRED, Key: key, Value: value}},
+	})
+	return ret, nil
+}
+
+// InferRegionConfig performs the inverse of zoneConfigForMultiRegionDatabase:
+// given a database-level zone config, it reconstructs on a best-effort basis
+// the RegionConfig that would have generated it. The primary region is derived
+// from the lease preferences, the survival goal from the number of voters, the
+// regions from the replica constraints and the placement policy from the
+// presence of non-voting replicas.
+//
+// The returned RegionConfig has no region enum ID nor super regions, as these
+// cannot be recovered from a zone config. An error is returned if the zone
+// config is not recognizably one generated for a multi-region database.
+func InferRegionConfig(zc zonepb.ZoneConfig) (multiregion.RegionConfig, error) {
+	notMultiRegionErr := func(format string, args ...interface{}) error {
+		return errors.Wrapf(
+			pgerror.Newf(pgcode.InvalidParameterValue, "zone config is not a multi-region zone config"),
+			format, args...,
+		)
+	}
+
+	if len(zc.LeasePreferences) != 1 {
+		return multiregion.RegionConfig{}, notMultiRegionErr(
+			"expected exactly one lease preference, found %d", len(zc.LeasePreferences),
+		)
+	}
+	primaryRegion, ok := regionFromConstraints(zc.LeasePreferences[0].Constraints)
+	if !ok {
+		return multiregion.RegionConfig{}, notMultiRegionErr("lease preference is not a single region")
+	}
+
+	if zc.NumVoters == nil || zc.NumReplicas == nil {
+		return multiregion.RegionConfig{}, notMultiRegionErr("num_voters and num_replicas must be set")
+	}
+	var survivalGoal descpb.SurvivalGoal
+	switch numVoters := *zc.NumVoters; numVoters {
+	case 3:
+		survivalGoal = descpb.SurvivalGoal_ZONE_FAILURE
+	case 5:
+		survivalGoal = descpb.SurvivalGoal_REGION_FAILURE
+		// Surviving two region failures also uses 5 voters, but only constrains
+		// a single one of them to the primary region.
+		if len(zc.VoterConstraints) == 1 && zc.VoterConstraints[0].NumReplicas == 1 {
+			survivalGoal = descpb.SurvivalGoal_TWO_REGION_FAILURE
+		}
+	default:
+		return multiregion.RegionConfig{}, notMultiRegionErr("unexpected number of voters %d", numVoters)
+	}
+
+	var opts []multiregion.MakeRegionConfigOption
+	placement := descpb.DataPlacement_DEFAULT
+	var regions catpb.RegionNames
+	if len(zc.Constraints) == 0 {
+		// Only RESTRICTED placement omits the per-region replica constraints, in
+		// which case the other regions of the database cannot be recovered.
+		if survivalGoal != descpb.SurvivalGoal_ZONE_FAILURE || *zc.NumReplicas != *zc.NumVoters {
+			return multiregion.RegionConfig{}, notMultiRegionErr("missing per-region replica constraints")
+		}
+		placement = descpb.DataPlacement_RESTRICTED
+		regions = catpb.RegionNames{primaryRegion}
+	} else {
+		foundPrimary := false
+		for _, conjunction := range zc.Constraints {
+			region, ok := regionFromConstraints(conjunction.Constraints)
+			if !ok {
+				return multiregion.RegionConfig{}, notMultiRegionErr("replica constraint is not a single region")
+			}
+			if region == primaryRegion {
+				foundPrimary = true
+				if conjunction.NumReplicas == *zc.NumVoters && conjunction.NumReplicas > 1 {
+					opts = append(opts, multiregion.WithPrimaryRegionOnlyVoters())
+				}
+			}
+			regions = append(regions, region)
+		}
+		if !foundPrimary {
+			return multiregion.RegionConfig{}, notMultiRegionErr(
+				"primary region %s has no replica constraint", primaryRegion,
+			)
+		}
+	}
+
+	if len(zc.VoterConstraints) > 1 {
+		voterWeights := make(map[catpb.RegionName]int32, len(zc.VoterConstraints))
+		for _, conjunction := range zc.VoterConstraints {
+			region, ok := regionFromConstraints(conjunction.Constraints)
+			if !ok {
+				return multiregion.RegionConfig{}, notMultiRegionErr("voter constraint is not a single region")
+			}
+			voterWeights[region] = conjunction.NumReplicas
+		}
+		opts = append(opts, multiregion.WithVoterWeights(voterWeights))
+	}
+
+	return multiregion.MakeRegionConfig(
+		regions, primaryRegion, survivalGoal, descpb.InvalidID, placement, nil /* superRegions */, opts...,
+	), nil
+}
+
+// pinReplicasToPlacedRegions raises the number of replicas of the per-region
+// conjunctions in place until they add up to numReplicas, so that no replica
+// is left free to be placed in a prohibited region. The primary region is
+// topped up to its number of voters first, then the remaining replicas are
+// spread across the other regions in turn.
+func pinReplicasToPlacedRegions(
+	regionConfig multiregion.RegionConfig,
+	constraints, voterConstraints []zonepb.ConstraintsConjunction,
+	numVoters, numReplicas int32,
+) {
+	primaryVoters := numVoters
+	for _, c := range voterConstraints {
+		if region, ok := regionFromConstraints(c.Constraints); ok &&
+			region == regionConfig.PrimaryRegion() && c.NumReplicas > 0 {
+			primaryVoters = c.NumReplicas
+		}
+	}
+	surplus := numReplicas
+	for _, c := range constraints {
+		surplus -= c.NumReplicas
+	}
+	var others []int
+	for i := range constraints {
+		region, ok := regionFromConstraints(constraints[i].Constraints)
+		if !ok {
+			continue
+		}
+		if region != regionConfig.PrimaryRegion() {
+			others = append(others, i)
+			continue
+		}
+		if n := primaryVoters - constraints[i].NumReplicas; n > 0 && surplus > 0 {
+			if n > surplus {
+				n = surplus
+			}
+			constraints[i].NumReplicas += n
+			surplus -= n
+		}
+	}
+	for i := 0; surplus > 0 && len(others) > 0; i++ {
+		constraints[others[i%len(others)]].NumReplicas++
+		surplus--
+	}
+}
+
+// regionFromConstraints returns the region referenced by the given
+// constraints if they consist of a single required region constraint.
+func regionFromConstraints(constraints []zonepb.Constraint) (catpb.RegionName, bool) {
+	if len(constraints) != 1 {
+		return "", false
+	}
+	c := constraints[0]
+	if c.Type != zonepb.Constraint_REQUIRED || c.Key != "region" {
+		return "", false
+	}
+	return catpb.RegionName(c.Value), true
+}
+
+// LeaseMigrationEstimate describes the impact on lease placement of moving a
+// multi-region database from one RegionConfig to another.
+type LeaseMigrationEstimate struct {
+	// NumChangedLeasePreferences is the number of lease preferences of the
+	// database zone config which differ between the two configs.
+	NumChangedLeasePreferences int
+	// GainedRegions are the regions which gain lease preference.
+	GainedRegions catpb.RegionNames
+	// LostRegions are the regions which lose lease preference.
+	LostRegions catpb.RegionNames
+}
+
+// EstimateLeaseMigration estimates how leases will move when a database's
+// RegionConfig changes from oldConfig to newConfig, e.g. when the primary
+// region is changed. It compares the lease preferences of the database zone
+// configs generated for both configs.
+func EstimateLeaseMigration(
+	oldConfig, newConfig multiregion.RegionConfig,
+) (LeaseMigrationEstimate, error) {
+	oldZoneConfig, err := zoneConfigForMultiRegionDatabase(oldConfig)
+	if err != nil {
+		return LeaseMigrationEstimate{}, err
+	}
+	newZoneConfig, err := zoneConfigForMultiRegionDatabase(newConfig)
+	if err != nil {
+		return LeaseMigrationEstimate{}, err
+	}
+
+	var ret LeaseMigrationEstimate
+	oldPrefs, newPrefs := oldZoneConfig.LeasePreferences, newZoneConfig.LeasePreferences
+	for i := 0; i < len(oldPrefs) || i < len(newPrefs); i++ {
+		if i >= len(oldPrefs) || i >= len(newPrefs) || !oldPrefs[i].Equal(&newPrefs[i]) {
+			ret.NumChangedLeasePreferences++
+		}
+	}
+
+	leaseRegions := func(prefs []zonepb.LeasePreference) map[catpb.RegionName]struct{} {
+		regions := make(map[catpb.RegionName]struct{})
+		for _, pref := range prefs {
+			for _, c := range pref.Constraints {
+				if c.Type == zonepb.Constraint_REQUIRED && c.Key == "region" {
+					regions[catpb.RegionName(c.Value)] = struct{}{}
+				}
+			}
+		}
+		return regions
+	}
+	oldRegions, newRegions := leaseRegions(oldPrefs), leaseRegions(newPrefs)
+	for region := range newRegions {
+		if _, ok := oldRegions[region]; !ok {
+			ret.GainedRegions = append(ret.GainedRegions, region)
+		}
+	}
+	for region := range oldRegions {
+		if _, ok := newRegions[region]; !ok {
+			ret.LostRegions = append(ret.LostRegions, region)
+		}
+	}
+	for _, regions := range []catpb.RegionNames{ret.GainedRegions, ret.LostRegions} {
+		sort.Slice(regions, func(i, j int) bool {
+			return regions[i] < regions[j]
+		})
+	}
+	return ret, nil
+}
+
+// PreviewAddRegion returns the zone config of a multi-region database before
+// and after adding the given region to it, so that the change made by
+// ALTER DATABASE ... ADD REGION can be inspected before running it.
+func PreviewAddRegion(
+	cfg multiregion.RegionConfig, newRegion catpb.RegionName,
+) (before, after zonepb.ZoneConfig, err error) {
+	for _, region := range cfg.Regions() {
+		if region == newRegion {
+			return zonepb.ZoneConfig{}, zonepb.ZoneConfig{}, pgerror.Newf(
+				pgcode.DuplicateObject,
+				"region %q already added to database",
+				newRegion,
+			)
+		}
+	}
+	before, err = zoneConfigForMultiRegionDatabase(cfg)
+	if err != nil {
+		return zonepb.ZoneConfig{}, zonepb.ZoneConfig{}, err
+	}
+	after, err = zoneConfigForMultiRegionDatabase(cfg.WithAddedRegion(newRegion))
+	if err != nil {
+		return zonepb.ZoneConfig{}, zonepb.ZoneConfig{}, err
+	}
+	return before, after, nil
+}
+
+// SurvivalGoalChangeSummary summarizes the changes made to the zone config of
+// a multi-region database by a change of its survival goal.
+type SurvivalGoalChangeSummary struct {
+	// OldNumVoters and NewNumVoters are the number of voting replicas before
+	// and after the change.
+	OldNumVoters, NewNumVoters int32
+	// OldNumReplicas and NewNumReplicas are the number of replicas before and
+	// after the change.
+	OldNumReplicas, NewNumReplicas int32
+	// AddedVoterConstraints and RemovedVoterConstraints are the voter
+	// constraints which only exist after and before the change, respectively.
+	AddedVoterConstraints, RemovedVoterConstraints []zonepb.ConstraintsConjunction
+	// AddedConstraints and RemovedConstraints are the replica constraints
+	// which only exist after and before the change, respectively.
+	AddedConstraints, RemovedConstraints []zonepb.ConstraintsConjunction
+}
+
+// SurvivalGoalChangeDelta returns the zone config of a multi-region database
+// before and after changing its survival goal to newGoal, along with a summary
+// of the differences, so that the change made by ALTER DATABASE ... SURVIVE
+// can be inspected before running it.
+func SurvivalGoalChangeDelta(
+	cfg multiregion.RegionConfig, newGoal descpb.SurvivalGoal,
+) (before, after zonepb.ZoneConfig, summary SurvivalGoalChangeSummary, err error) {
+	if err := multiregion.CanSatisfySurvivalGoal(newGoal, len(cfg.Regions())); err != nil {
+		return zonepb.ZoneConfig{}, zonepb.ZoneConfig{}, SurvivalGoalChangeSummary{}, err
+	}
+	before, err = zoneConfigForMultiRegionDatabase(cfg)
+	if err != nil {
+		return zonepb.ZoneConfig{}, zonepb.ZoneConfig{}, SurvivalGoalChangeSummary{}, err
+	}
+	after, err = zoneConfigForMultiRegionDatabase(cfg.WithSurvivalGoal(newGoal))
+	if err != nil {
+		return zonepb.ZoneConfig{}, zonepb.ZoneConfig{}, SurvivalGoalChangeSummary{}, err
+	}
+	summary = SurvivalGoalChangeSummary{
+		OldNumVoters:            *before.NumVoters,
+		NewNumVoters:            *after.NumVoters,
+		OldNumReplicas:          *before.NumReplicas,
+		NewNumReplicas:          *after.NumReplicas,
+		AddedVoterConstraints:   subtractConstraintsConjunctions(after.VoterConstraints, before.VoterConstraints),
+		RemovedVoterConstraints: subtractConstraintsConjunctions(before.VoterConstraints, after.VoterConstraints),
+		AddedConstraints:        subtractConstraintsConjunctions(after.Constraints, before.Constraints),
+		RemovedConstraints:      subtractConstraintsConjunctions(before.Constraints, after.Constraints),
+	}
+	return before, after, summary, nil
+}
+
+// SurvivalCost is the number of replicas, and of voting replicas among them,
+// of the ranges of a multi-region database under a given survival goal.
+type SurvivalCost struct {
+	NumReplicas int32
+	NumVoters   int32
+}
+
+// SurvivalCostComparison returns the cost of surviving zone failures and of
+// surviving region failures for a multi-region database with the given
+// regions, so that the cost of upgrading its survival goal can be reported.
+// The costs are derived from the database zone config generated for each
+// goal, with the default placement. They do not depend on the primary region.
+func SurvivalCostComparison(regions catpb.RegionNames) (zone, region SurvivalCost, err error) {
+	if len(regions) == 0 {
+		return SurvivalCost{}, SurvivalCost{}, pgerror.New(
+			pgcode.InvalidParameterValue, "at least one region is required to compare survival costs",
+		)
+	}
+	costFor := func(goal descpb.SurvivalGoal) (SurvivalCost, error) {
+		if err := multiregion.CanSatisfySurvivalGoal(goal, len(regions)); err != nil {
+			return SurvivalCost{}, err
+		}
+		regionConfig := multiregion.MakeRegionConfig(
+			regions, regions[0], goal, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+		)
+		zc, err := zoneConfigForMultiRegionDatabase(regionConfig)
+		if err != nil {
+			return SurvivalCost{}, err
+		}
+		return SurvivalCost{NumReplicas: *zc.NumReplicas, NumVoters: *zc.NumVoters}, nil
+	}
+	if zone, err = costFor(descpb.SurvivalGoal_ZONE_FAILURE); err != nil {
+		return SurvivalCost{}, SurvivalCost{}, err
+	}
+	if region, err = costFor(descpb.SurvivalGoal_REGION_FAILURE); err != nil {
+		return SurvivalCost{}, SurvivalCost{}, err
+	}
+	return zone, region, nil
+}
+
+// UnderReplicatedAfterDrop returns a description of the constraint
+// conjunctions generated for a multi-region database which place replicas in
+// the given region. Once the region is dropped, and until the zone configs are
+// regenerated without it and the replicas are moved elsewhere, the ranges
+// subject to these conjunctions are under-replicated.
+//
+// The conjunctions of the database zone config come first, followed by those
+// set by the zone config of the partitions homed in each of the other regions,
+// which the REGIONAL BY TABLE tables homed in these regions share. The
+// partitions homed in the dropped region are left out, as they are dropped
+// along with it.
+func UnderReplicatedAfterDrop(
+	cfg multiregion.RegionConfig, region catpb.RegionName,
+) ([]string, error) {
+	found := false
+	for _, r := range cfg.Regions() {
+		if r == region {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, pgerror.Newf(
+			pgcode.UndefinedObject,
+			"region %q has not been added to the database",
+			region,
+		)
+	}
+	if region == cfg.PrimaryRegion() && len(cfg.Regions()) > 1 {
+		return nil, pgerror.Newf(
+			pgcode.InvalidDatabaseDefinition,
+			"cannot drop primary region %q",
+			region,
+		)
+	}
+
+	var ret []string
+	describe := func(scope string, zc zonepb.ZoneConfig) {
+		for _, kind := range []struct {
+			name         string
+			key          string
+			conjunctions []zonepb.ConstraintsConjunction
+		}{
+			{"voter constraint", cfg.ConstraintKey(multiregion.VoterConstraintKind), zc.VoterConstraints},
+			{"constraint", cfg.ConstraintKey(multiregion.ReplicaConstraintKind), zc.Constraints},
+		} {
+			for _, c := range kind.conjunctions {
+				for _, constraint := range c.Constraints {
+					if constraint.Type == zonepb.Constraint_REQUIRED &&
+						constraint.Key == kind.key && constraint.Value == string(region) {
+						ret = append(ret, fmt.Sprintf("%s: %s %s", scope, kind.name, c.String()))
+						break
+					}
+				}
+			}
+		}
+	}
+
+	zc, err := zoneConfigForMultiRegionDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+	describe("database", zc)
+	for _, partitionRegion := range cfg.Regions() {
+		if partitionRegion == region {
+			continue
+		}
+		// Only the constraints are described, which the home region of a
+		// table does not affect.
+		zc, err := zoneConfigForMultiRegionPartition(partitionRegion, "" /* homeRegion */, cfg)
+		if err != nil {
+			return nil, err
+		}
+		describe(fmt.Sprintf("region %q", partitionRegion), zc)
+	}
+	return ret, nil
+}
+
+// subtractConstraintsConjunctions returns the conjunctions of a which are not
+// in b, regardless of their order.
+func subtractConstraintsConjunctions(
+	a, b []zonepb.ConstraintsConjunction,
+) []zonepb.ConstraintsConjunction {
+	var ret []zonepb.ConstraintsConjunction
+	for i := range a {
+		found := false
+		for j := range b {
+			if a[i].Equal(&b[j]) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ret = append(ret, a[i])
+		}
+	}
+	return ret
+}
+
+// VoterRegions returns the regions of a multi-region database which hold its
+// voting replicas, in the order of the regions of the RegionConfig. It is
+// derived from the voter constraints of the zone config generated by
+// zoneConfigForMultiRegionDatabase: if they pin all the voters, e.g. to the
+// primary region under zone survivability, only the constrained regions are
+// returned. Otherwise, e.g. under region survivability, the unconstrained
+// voters float across the voting regions, restricted to the super region of
+// the primary region if it has one, and all of these are returned.
+func VoterRegions(cfg multiregion.RegionConfig) (catpb.RegionNames, error) {
+	zc, err := zoneConfigForMultiRegionDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+	numVoters := *zc.NumVoters
+	var pinned int32
+	constrained := make(map[catpb.RegionName]struct{}, len(zc.VoterConstraints))
+	for _, c := range zc.VoterConstraints {
+		n := c.NumReplicas
+		if n == 0 {
+			n = numVoters
+		}
+		pinned += n
+		region, ok := regionFromConstraints(c.Constraints)
+		if !ok {
+			// The tie-breaker voter is placed outside of the regions.
+			key, value := cfg.TieBreakerVoter()
+			if cfg.HasTieBreakerVoter() && len(c.Constraints) == 1 &&
+				c.Constraints[0].Key == key && c.Constraints[0].Value == value {
+				continue
+			}
+			return nil, errors.AssertionFailedf("unexpected voter constraints %v", c.Constraints)
+		}
+		constrained[region] = struct{}{}
+	}
+
+	floating := make(map[catpb.RegionName]struct{})
+	if pinned < numVoters {
+		for _, region := range cfg.GetSuperRegionRegionsForRegion(cfg.PrimaryRegion()) {
+			if cfg.IsVotingRegion(region) && !cfg.IsProhibitedRegion(region) {
+				floating[region] = struct{}{}
+			}
+		}
+	}
+	var ret catpb.RegionNames
+	for _, region := range cfg.Regions() {
+		_, isConstrained := constrained[region]
+		_, isFloating := floating[region]
+		if isConstrained || isFloating {
+			ret = append(ret, region)
+		}
+	}
+	return ret, nil
+}
+
+// QuorumRegions returns the minimal sets of regions of a multi-region database
+// whose simultaneous availability guarantees a quorum of its voting replicas,
+// i.e. the sets whose voters form a quorum while those of any of their subsets
+// do not. The sets are ordered by size, then by the order of the regions of
+// the RegionConfig.
+//
+// The voters are distributed as described by the voter constraints of the zone
+// config generated by zoneConfigForMultiRegionDatabase. The voters which are
+// not pinned float across the regions returned by VoterRegions, and are
+// assumed to be spread by the allocator's diversity heuristic: each of them is
+// placed in the region holding the fewest voters. The tie-breaker voter, which
+// is placed outside of the regions, never counts towards the quorum.
+func QuorumRegions(cfg multiregion.RegionConfig) ([]catpb.RegionNames, error) {
+	zc, err := zoneConfigForMultiRegionDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+	voterRegions, err := VoterRegions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	numVoters := *zc.NumVoters
+	voters := make(map[catpb.RegionName]int32, len(voterRegions))
+	floating := numVoters
+	for _, c := range zc.VoterConstraints {
+		n := c.NumReplicas
+		if n == 0 {
+			n = numVoters
+		}
+		floating -= n
+		if region, ok := regionFromConstraints(c.Constraints); ok {
+			voters[region] += n
+		}
+	}
+	for ; floating > 0; floating-- {
+		var fewest catpb.RegionName
+		for _, region := range voterRegions {
+			if fewest == "" || voters[region] < voters[fewest] {
+				fewest = region
+			}
+		}
+		voters[fewest]++
+	}
+
+	// Enumerate the subsets of the regions holding voters, in order.
+	var regions catpb.RegionNames
+	for _, region := range voterRegions {
+		if voters[region] > 0 {
+			regions = append(regions, region)
+		}
+	}
+	quorum := numVoters/2 + 1
+	var ret []catpb.RegionNames
+	for size := 1; size <= len(regions); size++ {
+		var subsets func(start int, subset catpb.RegionNames)
+		subsets = func(start int, subset catpb.RegionNames) {
+			if len(subset) == size {
+				var sum, fewest int32
+				for i, region := range subset {
+					sum += voters[region]
+					if i == 0 || voters[region] < fewest {
+						fewest = voters[region]
+					}
+				}
+				// The set is minimal if removing the region holding the fewest
+				// voters loses the quorum.
+				if sum >= quorum && sum-fewest < quorum {
+					ret = append(ret, append(catpb.RegionNames(nil), subset...))
+				}
+				return
+			}
+			for i := start; i < len(regions); i++ {
+				subsets(i+1, append(subset, regions[i]))
+			}
+		}
+		subsets(0, make(catpb.RegionNames, 0, size))
+	}
+	return ret, nil
+}
+
+// RequiresCrossRegionQuorum returns whether the writes to a multi-region
+// database must reach voters outside of its primary region, which holds the
+// leaseholders, to achieve quorum. This is the case under region
+// survivability, but not under zone survivability unless the voters are
+// spread beyond the primary region, e.g. with voter regions. It is derived
+// from the distribution of the voters described by QuorumRegions.
+func RequiresCrossRegionQuorum(cfg multiregion.RegionConfig) (bool, error) {
+	quorumRegions, err := QuorumRegions(cfg)
+	if err != nil {
+		return false, err
+	}
+	for _, regions := range quorumRegions {
+		if len(regions) == 1 && regions[0] == cfg.PrimaryRegion() {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ZoneConfigsEquivalent returns whether the two zone configs are semantically
+// equivalent. Unlike a direct comparison, it ignores the order of constraints,
+// of constraint conjunctions and of the constraints within each lease
+// preference, and treats nil and empty slices alike. The order of the lease
+// preferences themselves is significant, as it defines which preference is
+// favored, and is preserved.
+func ZoneConfigsEquivalent(a, b zonepb.ZoneConfig) bool {
+	a, b = normalizeZoneConfig(a), normalizeZoneConfig(b)
+	return a.Equal(&b)
+}
+
+// normalizeZoneConfig returns a copy of the zone config with its constraints
+// and the constraints of its subzones sorted in a canonical order.
+func normalizeZoneConfig(zc zonepb.ZoneConfig) zonepb.ZoneConfig {
+	zc.Constraints = normalizeConstraintsConjunctions(zc.Constraints)
+	zc.VoterConstraints = normalizeConstraintsConjunctions(zc.VoterConstraints)
+	if len(zc.LeasePreferences) > 0 {
+		prefs := make([]zonepb.LeasePreference, len(zc.LeasePreferences))
+		for i, pref := range zc.LeasePreferences {
+			prefs[i] = zonepb.LeasePreference{Constraints: sortedConstraints(pref.Constraints)}
+		}
+		zc.LeasePreferences = prefs
+	}
+	if len(zc.Subzones) > 0 {
+		subzones := make([]zonepb.Subzone, len(zc.Subzones))
+		for i, subzone := range zc.Subzones {
+			subzone.Config = normalizeZoneConfig(subzone.Config)
+			subzones[i] = subzone
+		}
+		zc.Subzones = subzones
+	}
+	return zc
+}
+
+func normalizeConstraintsConjunctions(
+	conjunctions []zonepb.ConstraintsConjunction,
+) []zonepb.ConstraintsConjunction {
+	if len(conjunctions) == 0 {
+		return nil
+	}
+	ret := make([]zonepb.ConstraintsConjunction, len(conjunctions))
+	for i, conjunction := range conjunctions {
+		ret[i] = zonepb.ConstraintsConjunction{
+			NumReplicas: conjunction.NumReplicas,
+			Constraints: sortedConstraints(conjunction.Constraints),
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].NumReplicas != ret[j].NumReplicas {
+			return ret[i].NumReplicas < ret[j].NumReplicas
+		}
+		return constraintsKey(ret[i].Constraints) < constraintsKey(ret[j].Constraints)
+	})
+	return ret
+}
+
+func sortedConstraints(constraints []zonepb.Constraint) []zonepb.Constraint {
+	if len(constraints) == 0 {
+		return nil
+	}
+	ret := append([]zonepb.Constraint(nil), constraints...)
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].String() < ret[j].String()
+	})
+	return ret
+}
+
+func constraintsKey(constraints []zonepb.Constraint) string {
+	parts := make([]string, len(constraints))
+	for i, c := range constraints {
+		parts[i] = c.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// ValidateReplicaFeasibility returns an error if the replicas required by the
+// zone config cannot be placed given the number of nodes in each region. Each
+// replica of a range must be placed on a distinct node, so a region needs at
+// least as many nodes as the number of replicas (or voters) that the
+// constraints of the zone config require in it, and the cluster needs at least
+// num_replicas nodes overall. Regions missing from nodesPerRegion are assumed
+// to have no nodes.
+func ValidateReplicaFeasibility(
+	zc zonepb.ZoneConfig, nodesPerRegion map[catpb.RegionName]int,
+) error {
+	var numReplicas int32
+	if zc.NumReplicas != nil {
+		numReplicas = *zc.NumReplicas
+	}
+	numVoters := numReplicas
+	if zc.NumVoters != nil && *zc.NumVoters > 0 {
+		numVoters = *zc.NumVoters
+	}
+
+	// Voters are a subset of the replicas, so the number of nodes a region
+	// needs is the larger of the replicas and the voters required in it.
+	required := make(map[catpb.RegionName]int32)
+	for _, c := range []struct {
+		conjunctions []zonepb.ConstraintsConjunction
+		total        int32
+	}{
+		{conjunctions: zc.Constraints, total: numReplicas},
+		{conjunctions: zc.VoterConstraints, total: numVoters},
+	} {
+		for region, n := range requiredReplicasPerRegion(c.conjunctions, c.total) {
+			if n > required[region] {
+				required[region] = n
+			}
+		}
+	}
+
+	var infeasible []string
+	for region, n := range required {
+		if nodes := nodesPerRegion[region]; int(n) > nodes {
+			infeasible = append(infeasible, fmt.Sprintf("%s (%d required, %d available)", region, n, nodes))
+		}
+	}
+	if len(infeasible) > 0 {
+		sort.Strings(infeasible)
+		return pgerror.Newf(
+			pgcode.InvalidParameterValue,
+			"not enough nodes to place the required replicas in regions: %s",
+			strings.Join(infeasible, ", "),
+		)
+	}
+
+	var totalNodes int
+	for _, nodes := range nodesPerRegion {
+		totalNodes += nodes
+	}
+	if int(numReplicas) > totalNodes {
+		return pgerror.Newf(
+			pgcode.InvalidParameterValue,
+			"num_replicas = %d but only %d nodes are available",
+			numReplicas,
+			totalNodes,
+		)
+	}
+	return nil
+}
+
+// ValidateAvailabilityZonePlacement returns an error if the voting replicas of
+// a multi-region database with the given RegionConfig cannot be spread across
+// the availability zones of their regions such that a zone failure leaves a
+// quorum. The zone config remains applicable, so callers are expected to
+// surface the error as a warning rather than reject the RegionConfig.
+func ValidateAvailabilityZonePlacement(regionConfig multiregion.RegionConfig) error {
+	if !regionConfig.HasAvailabilityZones() {
+		return nil
+	}
+	zc, err := zoneConfigForMultiRegionDatabase(regionConfig)
+	if err != nil {
+		return err
+	}
+	numVoters := *zc.NumVoters
+	maxVotersPerZone := maxFailuresBeforeUnavailability(numVoters)
+	var unsatisfiable []string
+	for region, n := range requiredReplicasPerRegion(zc.VoterConstraints, numVoters) {
+		if zones := regionConfig.AvailabilityZones(region); zones > 0 && n > zones*maxVotersPerZone {
+			unsatisfiable = append(unsatisfiable, fmt.Sprintf("%s (%d voters, %d zones)", region, n, zones))
+		}
+	}
+	if len(unsatisfiable) > 0 {
+		sort.Strings(unsatisfiable)
+		return pgerror.Newf(
+			pgcode.InvalidParameterValue,
+			"not enough availability zones to survive a zone failure in regions: %s",
+			strings.Join(unsatisfiable, ", "),
+		)
+	}
+	return nil
+}
+
+// ValidateFullMultiRegionLayout generates the zone configs of the multi-region
+// database with the given ID, of its tables with the given locality configs
+// and of the partitions of its REGIONAL BY ROW tables, and cross-checks them
+// for consistency. homeRegions holds the home region of the REGIONAL BY ROW
+// tables which have one, see regionalByRowTableHomeRegion. The zone config of
+// each table and partition is validated once the fields it leaves unset are
+// inherited from its parent, so that conflicts with the database zone config
+// are reported, e.g. voters required in a region in which the database
+// constraints pin fewer replicas.
+func ValidateFullMultiRegionLayout(
+	dbID descpb.ID,
+	tables map[descpb.ID]catpb.LocalityConfig,
+	homeRegions map[descpb.ID]catpb.RegionName,
+	regionConfig multiregion.RegionConfig,
+) error {
+	dbZoneConfig, err := zoneConfigForMultiRegionDatabase(regionConfig)
+	if err != nil {
+		return errors.Wrapf(err, "generating zone config for database %d", dbID)
+	}
+	if err := validateMultiRegionZoneConfigConsistency(dbZoneConfig); err != nil {
+		return errors.Wrapf(err, "zone config for database %d", dbID)
+	}
+
+	tableIDs := make([]descpb.ID, 0, len(tables))
+	for id := range tables {
+		tableIDs = append(tableIDs, id)
+	}
+	sort.Slice(tableIDs, func(i, j int) bool { return tableIDs[i] < tableIDs[j] })
+	for _, id := range tableIDs {
+		localityConfig := tables[id]
+		tableZoneConfig, err := zoneConfigForMultiRegionTable(localityConfig, regionConfig)
+		if err != nil {
+			return errors.Wrapf(err, "generating zone config for table %d", id)
+		}
+		tableZoneConfig.InheritFromParent(&dbZoneConfig)
+		if err := validateMultiRegionZoneConfigConsistency(*tableZoneConfig); err != nil {
+			return errors.Wrapf(err, "zone config for table %d", id)
+		}
+		if localityConfig.GetRegionalByRow() == nil {
+			continue
+		}
+		for _, region := range regionConfig.Regions() {
+			partitionZoneConfig, err := zoneConfigForMultiRegionPartition(
+				region, homeRegions[id], regionConfig,
+			)
+			if err != nil {
+				return errors.Wrapf(err, "generating zone config for partition %s of table %d", region, id)
+			}
+			partitionZoneConfig.InheritFromParent(tableZoneConfig)
+			if err := validateMultiRegionZoneConfigConsistency(partitionZoneConfig); err != nil {
+				return errors.Wrapf(err, "zone config for partition %s of table %d", region, id)
+			}
+		}
+	}
+	return nil
+}
+
+// validateMultiRegionZoneConfigConsistency validates the given zone config,
+// which is expected to have inherited the fields it leaves unset. If its
+// constraints pin all of its replicas, each region must also hold at least as
+// many replicas as the voters which its voter constraints require there.
+func validateMultiRegionZoneConfigConsistency(zc zonepb.ZoneConfig) error {
+	if err := zc.Validate(); err != nil {
+		return err
+	}
+	var numReplicas int32
+	if zc.NumReplicas != nil {
+		numReplicas = *zc.NumReplicas
+	}
+	numVoters := numReplicas
+	if zc.NumVoters != nil && *zc.NumVoters > 0 {
+		numVoters = *zc.NumVoters
+	}
+
+	replicas := requiredReplicasPerRegion(zc.Constraints, numReplicas)
+	var numConstrained int32
+	for _, n := range replicas {
+		numConstrained += n
+	}
+	if numConstrained < numReplicas {
+		// Some replicas are free to be placed in any region.
+		return nil
+	}
+	voters := requiredReplicasPerRegion(zc.VoterConstraints, numVoters)
+	regions := make(catpb.RegionNames, 0, len(voters))
+	for region := range voters {
+		regions = append(regions, region)
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i] < regions[j] })
+	for _, region := range regions {
+		if voters[region] > replicas[region] {
+			return pgerror.Newf(
+				pgcode.InvalidParameterValue,
+				"voter constraints require %d voters in region %s, but constraints pin %d replicas there",
+				voters[region],
+				region,
+				replicas[region],
+			)
+		}
+	}
+	return nil
+}
+
+// requiredReplicasPerRegion returns the number of replicas that the given
+// constraints require in each region. A conjunction with NumReplicas unset
+// applies to all total replicas.
+func requiredReplicasPerRegion(
+	conjunctions []zonepb.ConstraintsConjunction, total int32,
+) map[catpb.RegionName]int32 {
+	ret := make(map[catpb.RegionName]int32)
+	for _, conjunction := range conjunctions {
+		n := conjunction.NumReplicas
+		if n == 0 {
+			n = total
+		}
+		for _, c := range conjunction.Constraints {
+			if c.Type == zonepb.Constraint_REQUIRED && c.Key == "region" {
+				ret[catpb.RegionName(c.Value)] += n
+			}
+		}
+	}
+	return ret
 }
 
 // maybeAddConstraintsForSuperRegion updates the ZoneConfig.Constraints field
@@ -189,15 +1453,19 @@ func zoneConfigForMultiRegionDatabase(
 // within the super region.
 // If primaryRegion is not a member of any super region, there is nothing
 // to be done.
+// An error is returned if the resulting constraints cannot be satisfied by
+// numReplicas, e.g. under zone survival with a super region containing the
+// minimum of two regions, where the home region's voters and the single
+// replica of the other region must add up to exactly numReplicas.
 func maybeAddConstraintsForSuperRegion(
 	primaryRegion catpb.RegionName,
 	regions catpb.RegionNames,
 	zc *zonepb.ZoneConfig,
 	numReplicas int32,
 	regionConfig multiregion.RegionConfig,
-) {
+) error {
 	if !regionConfig.IsMemberOfExplicitSuperRegion(primaryRegion) {
-		return
+		return nil
 	}
 
 	zc.NumReplicas = &numReplicas
@@ -207,7 +1475,7 @@ func maybeAddConstraintsForSuperRegion(
 
 	survivalGoal := regionConfig.SurvivalGoal()
 	switch survivalGoal {
-	case descpb.SurvivalGoal_ZONE_FAILURE:
+	case descpb.SurvivalGoal_ZONE_FAILURE, descpb.SurvivalGoal_TWO_REGION_FAILURE:
 		for _, region := range regions {
 			zc.Constraints = append(zc.Constraints, zonepb.ConstraintsConjunction{
 				NumReplicas: 1,
@@ -242,6 +1510,23 @@ func maybeAddConstraintsForSuperRegion(
 	default:
 		panic(fmt.Sprintf("unknown survival goal %s", survivalGoal))
 	}
+
+	if zc.NumVoters != nil && *zc.NumVoters > numReplicas {
+		return errors.AssertionFailedf(
+			"super region of %s has %d voters but only %d replicas", primaryRegion, *zc.NumVoters, numReplicas,
+		)
+	}
+	var numConstrained int32
+	for _, c := range zc.Constraints {
+		numConstrained += c.NumReplicas
+	}
+	if numConstrained > numReplicas {
+		return errors.AssertionFailedf(
+			"super region of %s constrains %d replicas but only %d replicas are configured",
+			primaryRegion, numConstrained, numReplicas,
+		)
+	}
+	return nil
 }
 
 // zoneConfigForMultiRegionPartition generates a ZoneConfig stub for a partition
@@ -251,11 +1536,45 @@ func maybeAddConstraintsForSuperRegion(
 // `num_voters`, `voter_constraints`, and `lease_preferences`. We expect that
 // the attributes `num_replicas` and `constraints` will be inherited from the
 // database level zone config.
+//
+// If the survival goal of the partition was overridden to differ from the
+// database's, `num_replicas` is set as well so that the partition has enough
+// replicas for its own survival goal.
+//
+// homeRegion, if set, is the home region of the table, i.e. the region which
+// its region column defaults to. Most rows of the table land in the partition
+// of the home region, so if the partition survives region failures, its lease
+// preferences fall back to a designated region rather than to any region
+// holding a voter, see homePartitionFallbackRegion.
 func zoneConfigForMultiRegionPartition(
-	partitionRegion catpb.RegionName, regionConfig multiregion.RegionConfig,
+	partitionRegion catpb.RegionName,
+	homeRegion catpb.RegionName,
+	regionConfig multiregion.RegionConfig,
+	opts ...zoneConfigForMultiRegionTableOption,
 ) (zonepb.ZoneConfig, error) {
+	var options zoneConfigForMultiRegionTableOptions
+	for _, f := range opts {
+		f(&options)
+	}
 	zc := zonepb.NewZoneConfig()
-	voterConstraints, err := synthesizeVoterConstraints(partitionRegion, regionConfig)
+	survivalGoal := regionConfig.PartitionSurvivalGoal(partitionRegion)
+	if err := multiregion.CanSatisfySurvivalGoal(survivalGoal, len(regionConfig.Regions())); err != nil {
+		return zonepb.ZoneConfig{}, err
+	}
+	if options.confinedSuperRegion != "" {
+		// The partition is placed as if it was homed in a region of the super
+		// region, whose members then hold all of its replicas.
+		var err error
+		partitionRegion, err = regionForSuperRegionConfinement(
+			partitionRegion, options.confinedSuperRegion, survivalGoal, regionConfig,
+		)
+		if err != nil {
+			return zonepb.ZoneConfig{}, err
+		}
+	}
+	voterConstraints, err := synthesizeVoterConstraintsForSurvivalGoal(
+		partitionRegion, survivalGoal, regionConfig,
+	)
 	if err != nil {
 		return zonepb.ZoneConfig{}, err
 	}
@@ -269,17 +1588,146 @@ func zoneConfigForMultiRegionPartition(
 	}
 
 	regions := regionConfig.GetSuperRegionRegionsForRegion(partitionRegion)
+	if options.leaseSuperRegion != "" {
+		zc.LeasePreferences, err = leasePreferencesForSuperRegion(
+			partitionRegion, options.leaseSuperRegion, regionConfig,
+		)
+		if err != nil {
+			return zonepb.ZoneConfig{}, err
+		}
+	} else if partitionRegion == homeRegion && survivalGoal == descpb.SurvivalGoal_REGION_FAILURE {
+		if fallback, ok := homePartitionFallbackRegion(homeRegion, regions, regionConfig); ok {
+			zc.LeasePreferences = append(zc.LeasePreferences, zonepb.LeasePreference{
+				Constraints: []zonepb.Constraint{makeRequiredConstraintForRegion(fallback)},
+			})
+		}
+	}
 
 	numVoters, numReplicas := getNumVotersAndNumReplicas(
-		len(regions), regionConfig.SurvivalGoal(), regionConfig.IsPlacementRestricted(),
+		len(regions), survivalGoal, regionConfig.IsPlacementRestricted(),
 	)
 	zc.NumVoters = &numVoters
+	if survivalGoal != regionConfig.SurvivalGoal() {
+		zc.NumReplicas = &numReplicas
+	}
 
-	maybeAddConstraintsForSuperRegion(partitionRegion, regions, zc, numReplicas, regionConfig)
+	if err := maybeAddConstraintsForSuperRegion(
+		partitionRegion, regions, zc, numReplicas, regionConfig,
+	); err != nil {
+		return zonepb.ZoneConfig{}, err
+	}
+	if err := maybeValidateGeneratedZoneConfig(zc); err != nil {
+		return zonepb.ZoneConfig{}, err
+	}
 
 	return *zc, err
 }
 
+// homePartitionFallbackRegion returns the region which the leases of the
+// partition of the home region of a table should move to if the home region
+// fails: the secondary region of the database if it has one, or else its
+// primary region. The fallback region must be one of the given regions, which
+// hold the replicas of the partition.
+func homePartitionFallbackRegion(
+	homeRegion catpb.RegionName, regions catpb.RegionNames, regionConfig multiregion.RegionConfig,
+) (catpb.RegionName, bool) {
+	fallback := regionConfig.PrimaryRegion()
+	if regionConfig.HasSecondaryRegion() {
+		fallback = regionConfig.SecondaryRegion()
+	}
+	if fallback == homeRegion {
+		return "", false
+	}
+	for _, region := range regions {
+		if region == fallback {
+			return fallback, true
+		}
+	}
+	return "", false
+}
+
+// regionalByRowTableHomeRegion returns the home region of the given table,
+// for use with zoneConfigForMultiRegionPartition: the region which the region
+// column of a REGIONAL BY ROW table defaults to, if it is NOT NULL and
+// defaults to a constant region. It returns an empty region otherwise, e.g.
+// if the column defaults to the gateway region.
+func regionalByRowTableHomeRegion(table catalog.TableDescriptor) catpb.RegionName {
+	if !table.IsLocalityRegionalByRow() {
+		return ""
+	}
+	colName, err := table.GetRegionalByRowTableRegionColumnName()
+	if err != nil {
+		return ""
+	}
+	col, err := table.FindColumnWithName(colName)
+	if err != nil || col.IsNullable() || !col.HasDefault() {
+		return ""
+	}
+	expr, err := parser.ParseExpr(col.GetDefaultExpr())
+	if err != nil {
+		return ""
+	}
+	for {
+		switch e := expr.(type) {
+		case *tree.CastExpr:
+			expr = e.Expr
+		case *tree.AnnotateTypeExpr:
+			expr = e.Expr
+		case *tree.ParenExpr:
+			expr = e.Expr
+		case *tree.StrVal:
+			return catpb.RegionName(e.RawString())
+		case *tree.DString:
+			return catpb.RegionName(*e)
+		default:
+			return ""
+		}
+	}
+}
+
+// zoneConfigForIndexPartition generates the subzone for the partition of the
+// given index of a regional by row table which holds the rows homed in the
+// given region. homeRegion is the home region of the table, if any, see
+// zoneConfigForMultiRegionPartition.
+func zoneConfigForIndexPartition(
+	region catpb.RegionName,
+	homeRegion catpb.RegionName,
+	indexID descpb.IndexID,
+	regionConfig multiregion.RegionConfig,
+) (zonepb.Subzone, error) {
+	zc, err := zoneConfigForMultiRegionPartition(region, homeRegion, regionConfig)
+	if err != nil {
+		return zonepb.Subzone{}, err
+	}
+	return zonepb.Subzone{
+		IndexID:       uint32(indexID),
+		PartitionName: string(region),
+		Config:        zc,
+	}, nil
+}
+
+// subzoneSpansForMultiRegionTable returns the subzones which a regional by row
+// table with the given indexes is expected to have: one for the partition of
+// each index homed in each region of the database, ordered by index and then
+// by region. The result can be diffed against the subzones stored in the zone
+// config of the table. homeRegion is the home region of the table, if any, see
+// regionalByRowTableHomeRegion.
+func subzoneSpansForMultiRegionTable(
+	cfg multiregion.RegionConfig, homeRegion catpb.RegionName, indexes []descpb.IndexID,
+) ([]zonepb.Subzone, error) {
+	subzones := make([]zonepb.Subzone, 0, len(indexes)*len(cfg.Regions()))
+	for _, indexID := range indexes {
+		for _, region := range cfg.Regions() {
+			subzone, err := zoneConfigForIndexPartition(region, homeRegion, indexID, cfg)
+			if err != nil {
+				return nil, err
+			}
+			subzones = append(subzones, subzone)
+		}
+	}
+	return subzones, nil
+}
+
 // maxFailuresBeforeUnavailability returns the maximum number of individual
 // failures that can be tolerated, among `numVoters` voting replicas, before a
 // given range is unavailable.
@@ -289,12 +1737,25 @@ func maxFailuresBeforeUnavailability(numVoters int32) int32 {
 
 // getNumVotersAndNumReplicasForDefaultDatabaseRegions computes the number of
 // voters and the total number of replicas needed for a given region config.
+// Regions with restricted placement other than the primary region hold no
+// replica of their own, so they are not counted.
 func getNumVotersAndNumReplicasForDefaultDatabaseRegions(
 	config multiregion.RegionConfig,
 ) (numVoters, numReplicas int32) {
-	return getNumVotersAndNumReplicas(
-		len(config.Regions()), config.SurvivalGoal(), config.IsPlacementRestricted(),
+	numRegions := len(config.PlacedRegions())
+	if config.HasStandbyRegion() {
+		// The standby region holds a replica on top of those needed by the
+		// other regions, so that it has a full copy of the data without
+		// taking away from the replicas of the other regions.
+		numRegions--
+	}
+	numVoters, numReplicas = getNumVotersAndNumReplicas(
+		numRegions, config.SurvivalGoal(), config.IsPlacementRestricted(),
 	)
+	if config.HasStandbyRegion() {
+		numReplicas++
+	}
+	return numVoters, numReplicas
 }
 
 func getNumVotersAndNumReplicas(
@@ -315,6 +1776,13 @@ func getNumVotersAndNumReplicas(
 	// be allowed to "float" around among the other regions in the database. They
 	// may or may not be placed geographically close to the leaseholder replica.
 	const numVotersForRegionSurvival = 5
+	// Under two region survivability, we use 5 voting replicas spread across 5
+	// regions, one per region, so that losing any two regions leaves a quorum
+	// of 3 voters. Using more voters would require the primary region to hold
+	// more than one of them, which in turn requires more regions for any two
+	// of them to hold no more than <numVoters - quorum> voters; e.g. 7 voters
+	// would require 6 regions.
+	const numVotersForTwoRegionSurvival = 5
 
 	switch survivalGoal {
 	// NB: See mega-comment inside `synthesizeVoterConstraints()` for why these
@@ -340,6 +1808,13 @@ func getNumVotersAndNumReplicas(
 			// replicas will be voting replicas.
 			numReplicas = numVoters
 		}
+	case descpb.SurvivalGoal_TWO_REGION_FAILURE:
+		// <1 voter in the home region> + <1 replica for every other region>
+		numVoters = numVotersForTwoRegionSurvival
+		numReplicas = int32(numRegions)
+		if numReplicas < numVoters {
+			numReplicas = numVoters
+		}
 	}
 	return numVoters, numReplicas
 }
@@ -356,7 +1831,15 @@ func getNumVotersAndNumReplicas(
 func synthesizeVoterConstraints(
 	region catpb.RegionName, regionConfig multiregion.RegionConfig,
 ) ([]zonepb.ConstraintsConjunction, error) {
-	switch regionConfig.SurvivalGoal() {
+	return synthesizeVoterConstraintsForSurvivalGoal(region, regionConfig.SurvivalGoal(), regionConfig)
+}
+
+// synthesizeVoterConstraintsForSurvivalGoal is like synthesizeVoterConstraints,
+// but uses the given survival goal instead of the database's.
+func synthesizeVoterConstraintsForSurvivalGoal(
+	region catpb.RegionName, survivalGoal descpb.SurvivalGoal, regionConfig multiregion.RegionConfig,
+) ([]zonepb.ConstraintsConjunction, error) {
+	switch survivalGoal {
 	case descpb.SurvivalGoal_ZONE_FAILURE:
 		return []zonepb.ConstraintsConjunction{
 			{
@@ -397,7 +1880,9 @@ func synthesizeVoterConstraints(
 			},
 		}, nil
 	case descpb.SurvivalGoal_REGION_FAILURE:
-		numVoters, _ := getNumVotersAndNumReplicasForDefaultDatabaseRegions(regionConfig)
+		numVoters, _ := getNumVotersAndNumReplicas(
+			len(regionConfig.Regions()), survivalGoal, regionConfig.IsPlacementRestricted(),
+		)
 		return []zonepb.ConstraintsConjunction{
 			{
 				// We constrain <quorum - 1> voting replicas to the primary region and
@@ -436,9 +1921,178 @@ func synthesizeVoterConstraints(
 				Constraints: []zonepb.Constraint{makeRequiredConstraintForRegion(region)},
 			},
 		}, nil
+	case descpb.SurvivalGoal_TWO_REGION_FAILURE:
+		return []zonepb.ConstraintsConjunction{
+			{
+				// We constrain a single voting replica to the primary region, so that
+				// it can hold the lease, and rely on the diversity heuristic of the
+				// allocator to spread the other voting replicas across the other
+				// regions, one per region. Any two regions then hold at most 2 of the 5
+				// voters, which leaves a quorum after losing both of them.
+				NumReplicas: 1,
+				Constraints: []zonepb.Constraint{makeRequiredConstraintForRegion(region)},
+			},
+		}, nil
 	default:
-		return nil, errors.AssertionFailedf("unknown survival goal: %v", regionConfig.SurvivalGoal())
+		return nil, errors.AssertionFailedf("unknown survival goal: %v", survivalGoal)
+	}
+}
+
+// validateVoterWeights ensures that the per-region voter weights on the
+// given region config account for exactly numVoters voting replicas, that the
+// primary region holds at least one voter so that it can be the leaseholder,
+// and that no single region holds enough voters to lose quorum on its own
+// failure.
+func validateVoterWeights(regionConfig multiregion.RegionConfig, numVoters int32) error {
+	var sum int32
+	for region, weight := range regionConfig.VoterWeights() {
+		if weight < 0 {
+			return pgerror.Newf(pgcode.InvalidParameterValue,
+				"voter weight for region %q must be non-negative", region)
+		}
+		if !regionConfig.IsValidRegionNameString(string(region)) {
+			return pgerror.Newf(pgcode.InvalidParameterValue,
+				"region %q is not a region of the database", region)
+		}
+		if weight > maxFailuresBeforeUnavailability(numVoters) {
+			return pgerror.Newf(pgcode.InvalidParameterValue,
+				"voter weight %d for region %q exceeds the maximum of %d voters per region",
+				weight, region, maxFailuresBeforeUnavailability(numVoters))
+		}
+		sum += weight
+	}
+	if sum != numVoters {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"voter weights sum to %d but the database requires %d voters", sum, numVoters)
+	}
+	if regionConfig.VoterWeights()[regionConfig.PrimaryRegion()] == 0 {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"primary region %q must be assigned at least one voter", regionConfig.PrimaryRegion())
+	}
+	return nil
+}
+
+// voterWeightsForVotingRegions distributes the voters of a region survivable
+// database with non-voting regions across its voting regions. The primary
+// region holds as many voters as can fail without losing quorum, and the
+// remaining voters are spread round-robin across the other voting regions.
+func voterWeightsForVotingRegions(
+	regionConfig multiregion.RegionConfig, numVoters int32,
+) (map[catpb.RegionName]int32, error) {
+	votingRegions := regionConfig.VotingRegions()
+	if err := multiregion.CanSatisfySurvivalGoal(
+		regionConfig.SurvivalGoal(), len(votingRegions),
+	); err != nil {
+		return nil, errors.Wrap(err, "insufficient voting regions")
+	}
+	maxVotersPerRegion := maxFailuresBeforeUnavailability(numVoters)
+	voterWeights := map[catpb.RegionName]int32{
+		regionConfig.PrimaryRegion(): maxVotersPerRegion,
+	}
+	remaining := numVoters - maxVotersPerRegion
+	for remaining > 0 {
+		for _, region := range votingRegions {
+			if remaining == 0 {
+				break
+			}
+			if region == regionConfig.PrimaryRegion() {
+				continue
+			}
+			voterWeights[region]++
+			remaining--
+		}
+	}
+	return voterWeights, nil
+}
+
+// voterWeightsForVoterRegions distributes the voters of a database with voter
+// regions round-robin across them, starting from the primary region so that
+// it holds at least one voter and can be the leaseholder.
+func voterWeightsForVoterRegions(
+	regionConfig multiregion.RegionConfig, numVoters int32,
+) map[catpb.RegionName]int32 {
+	regions := catpb.RegionNames{regionConfig.PrimaryRegion()}
+	for _, region := range regionConfig.VoterRegions() {
+		if region != regionConfig.PrimaryRegion() {
+			regions = append(regions, region)
+		}
 	}
+	voterWeights := make(map[catpb.RegionName]int32, len(regions))
+	for i := 0; i < int(numVoters); i++ {
+		voterWeights[regions[i%len(regions)]]++
+	}
+	return voterWeights
+}
+
+// voterWeightsForAvailabilityZones distributes the voters of a zone survivable
+// database whose primary region has too few availability zones to hold all of
+// them, as no zone may hold more voters than can fail without losing quorum.
+// The primary region holds as many voters as its zones allow, and the
+// remaining voters are spread to the other voting regions, the secondary
+// region first, within the limits of their own zones. Regions whose number of
+// zones is unknown are not limited. Any voter which cannot be placed is left
+// in the primary region; see ValidateAvailabilityZonePlacement. It returns nil
+// if the primary region can hold all of the voters.
+func voterWeightsForAvailabilityZones(
+	regionConfig multiregion.RegionConfig, numVoters int32,
+) map[catpb.RegionName]int32 {
+	maxVotersPerZone := maxFailuresBeforeUnavailability(numVoters)
+	maxVoters := func(region catpb.RegionName) int32 {
+		if zones := regionConfig.AvailabilityZones(region); zones > 0 {
+			return zones * maxVotersPerZone
+		}
+		return numVoters
+	}
+	primaryRegion := regionConfig.PrimaryRegion()
+	if maxVoters(primaryRegion) >= numVoters {
+		return nil
+	}
+	voterWeights := map[catpb.RegionName]int32{
+		primaryRegion: maxVoters(primaryRegion),
+	}
+	remaining := numVoters - maxVoters(primaryRegion)
+	var regions catpb.RegionNames
+	if regionConfig.HasSecondaryRegion() {
+		regions = append(regions, regionConfig.SecondaryRegion())
+	}
+	regions = append(regions, regionConfig.PlacedRegions()...)
+	for _, region := range regions {
+		if remaining == 0 {
+			break
+		}
+		if region == primaryRegion || voterWeights[region] > 0 ||
+			!regionConfig.IsVotingRegion(region) {
+			continue
+		}
+		n := maxVoters(region)
+		if n > remaining {
+			n = remaining
+		}
+		voterWeights[region] = n
+		remaining -= n
+	}
+	voterWeights[primaryRegion] += remaining
+	return voterWeights
+}
+
+// synthesizeWeightedVoterConstraints generates the `voter_constraints` for a
+// region config with explicit per-region voter weights. Each region with a
+// non-zero weight is constrained to hold exactly that many voting replicas.
+func synthesizeWeightedVoterConstraints(
+	regions catpb.RegionNames, voterWeights map[catpb.RegionName]int32,
+) []zonepb.ConstraintsConjunction {
+	var voterConstraints []zonepb.ConstraintsConjunction
+	for _, region := range regions {
+		weight := voterWeights[region]
+		if weight == 0 {
+			continue
+		}
+		voterConstraints = append(voterConstraints, zonepb.ConstraintsConjunction{
+			NumReplicas: weight,
+			Constraints: []zonepb.Constraint{makeRequiredConstraintForRegion(region)},
+		})
+	}
+	return voterConstraints
 }
 
 // zoneConfigForMultiRegionTable generates a ZoneConfig stub for a
@@ -456,8 +2110,14 @@ func synthesizeVoterConstraints(
 // `zonepb.MultiRegionZoneConfigFields`) will be overwritten by the calling function
 // into an existing ZoneConfig.
 func zoneConfigForMultiRegionTable(
-	localityConfig catpb.LocalityConfig, regionConfig multiregion.RegionConfig,
+	localityConfig catpb.LocalityConfig,
+	regionConfig multiregion.RegionConfig,
+	opts ...zoneConfigForMultiRegionTableOption,
 ) (*zonepb.ZoneConfig, error) {
+	var options zoneConfigForMultiRegionTableOptions
+	for _, f := range opts {
+		f(&options)
+	}
 	ret := zonepb.NewZoneConfig()
 
 	switch l := localityConfig.Locality.(type) {
@@ -469,79 +2129,365 @@ func zoneConfigForMultiRegionTable(
 		// for fast reads, so we have to manually build a zone config with the
 		// nonvoters as opposed to REGIONAL BY [TABLE | ROW] which can inherit the
 		// RESTRICTED placement from the database.
+		if options.confinedSuperRegion != "" {
+			return nil, errors.AssertionFailedf(
+				"GLOBAL tables cannot be confined to super region %q", options.confinedSuperRegion,
+			)
+		}
 		if regionConfig.IsPlacementRestricted() {
-			// We only care about NumVoters here at the table level. NumReplicas is set at
-			// the database level, not at the table/partition level.
-			numVoters, _ := getNumVotersAndNumReplicasForDefaultDatabaseRegions(regionConfig)
-
-			ret.NumVoters = &numVoters
-			vc, err := synthesizeVoterConstraints(regionConfig.PrimaryRegion(), regionConfig)
+			zc, err := zoneConfigForGlobalTableInRestrictedDatabase(regionConfig)
 			if err != nil {
 				return nil, err
 			}
-			ret.VoterConstraints = vc
-
-			ret.InheritedConstraints = false
-			ret.NullVoterConstraintsIsEmpty = true
-
-			numNonPrimaryRegions := len(regionConfig.Regions()) - 1
-			// Placement only applies in zone survivability in which case voters are
-			// only in the primary region. This means the total number of replicas is
-			// numVotersForZoneSurvival voting replicas + 1 for each non-primary
-			// region.
-			ret.NumReplicas = proto.Int32(numVoters + int32(numNonPrimaryRegions))
-			ret.Constraints = make([]zonepb.ConstraintsConjunction, len(regionConfig.Regions()))
-			for i, region := range regionConfig.Regions() {
-				ret.Constraints[i] = zonepb.ConstraintsConjunction{
-					NumReplicas: 1,
-					Constraints: []zonepb.Constraint{makeRequiredConstraintForRegion(region)},
-				}
+			ret = zc
+		}
+		// Inherit lease preference from the database, unless the leases may
+		// live anywhere in a super region. We do nothing otherwise because
+		// `NewZoneConfig()` already marks the field as 'inherited'.
+		if options.leaseSuperRegion != "" {
+			prefs, err := leasePreferencesForSuperRegion(
+				regionConfig.PrimaryRegion(), options.leaseSuperRegion, regionConfig,
+			)
+			if err != nil {
+				return nil, err
 			}
+			ret.InheritedLeasePreferences = false
+			ret.LeasePreferences = prefs
 		}
-		// Inherit lease preference from the database. We do
-		// nothing here because `NewZoneConfig()` already marks the field as
-		// 'inherited'.
 	case *catpb.LocalityConfig_RegionalByTable_:
 		primaryRegion := regionConfig.PrimaryRegion()
 		if l.RegionalByTable.Region != nil {
 			primaryRegion = *l.RegionalByTable.Region
+			// The home region may have been dropped from the database, in
+			// which case constraining the table to it would leave its
+			// replicas unplaceable.
+			if !regionConfig.IsValidRegionNameString(string(primaryRegion)) {
+				return nil, errors.WithHintf(
+					pgerror.Newf(
+						pgcode.InvalidTableDefinition,
+						"REGIONAL BY TABLE region %q is not a region of the database",
+						primaryRegion,
+					),
+					"available regions: %s",
+					strings.Join(regionConfig.Regions().ToStrings(), ", "),
+				)
+			}
+		}
+		// A table which ignores the super region of its region is spread
+		// across all of the regions, just like the tables of a database
+		// without super regions.
+		inSuperRegion := regionConfig.IsMemberOfExplicitSuperRegion(primaryRegion) &&
+			!l.RegionalByTable.IgnoreSuperRegion
+		if options.confinedSuperRegion != "" {
+			var err error
+			primaryRegion, err = regionForSuperRegionConfinement(
+				primaryRegion, options.confinedSuperRegion, regionConfig.SurvivalGoal(), regionConfig,
+			)
+			if err != nil {
+				return nil, err
+			}
+			inSuperRegion = true
 		}
-		regions := regionConfig.GetSuperRegionRegionsForRegion(primaryRegion)
-		if l.RegionalByTable.Region == nil && !regionConfig.IsMemberOfExplicitSuperRegion(primaryRegion) {
+		if l.RegionalByTable.Region == nil && !inSuperRegion && options.leaseSuperRegion == "" {
 			// If we don't have an explicit primary
 			// region, use the same configuration as the database and return a blank
 			// zcfg here.
+			if err := multiregion.CanSatisfySurvivalGoal(
+				regionConfig.SurvivalGoal(), len(regionConfig.Regions()),
+			); err != nil {
+				return nil, err
+			}
 			return ret, nil
 		}
-
-		numVoters, numReplicas := getNumVotersAndNumReplicas(
-			len(regions), regionConfig.SurvivalGoal(), regionConfig.IsPlacementRestricted(),
+		if err := homeZoneConfigForMultiRegionTable(
+			ret, primaryRegion, inSuperRegion, regionConfig, options,
+		); err != nil {
+			return nil, err
+		}
+	case *catpb.LocalityConfig_RegionalByRow_:
+		// We purposely do not set anything here at table level - this should be done at
+		// partition level instead, unless the table is confined to a super
+		// region, in which case the replicas of the table are confined to it
+		// at the table level as well.
+		if options.confinedSuperRegion == "" {
+			return ret, nil
+		}
+		homeRegion, err := regionForSuperRegionConfinement(
+			regionConfig.PrimaryRegion(), options.confinedSuperRegion, regionConfig.SurvivalGoal(), regionConfig,
 		)
-		ret.NumVoters = &numVoters
-
-		maybeAddConstraintsForSuperRegion(primaryRegion, regions, ret, numReplicas, regionConfig)
-
-		// If the table has a user-specified primary region, use it.
-		voterConstraints, err := synthesizeVoterConstraints(primaryRegion, regionConfig)
 		if err != nil {
 			return nil, err
 		}
+		if err := homeZoneConfigForMultiRegionTable(
+			ret, homeRegion, true /* inSuperRegion */, regionConfig, options,
+		); err != nil {
+			return nil, err
+		}
+	}
+	if err := maybeValidateGeneratedZoneConfig(ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// zoneConfigForGlobalTableInRestrictedDatabase returns the zone config of a
+// GLOBAL table in a database with RESTRICTED placement. The database confines
+// the replicas of its tables to their home region, but a GLOBAL table needs
+// a replica in every region to serve fast reads everywhere, so the placement
+// of the database is overridden: the voters stay in the primary region, and
+// a non-voter is constrained to each of the other regions.
+func zoneConfigForGlobalTableInRestrictedDatabase(
+	regionConfig multiregion.RegionConfig,
+) (*zonepb.ZoneConfig, error) {
+	if !regionConfig.IsPlacementRestricted() {
+		return nil, errors.AssertionFailedf(
+			"expected a database with restricted placement, found %s", regionConfig.Placement(),
+		)
+	}
+	// Placement only applies in zone survivability, in which case voters are
+	// only in the primary region.
+	if regionConfig.SurvivalGoal() != descpb.SurvivalGoal_ZONE_FAILURE {
+		return nil, errors.AssertionFailedf(
+			"expected a database with restricted placement to survive zone failures, found %s",
+			regionConfig.SurvivalGoal(),
+		)
+	}
+	ret := zonepb.NewZoneConfig()
+	ret.GlobalReads = proto.Bool(true)
+
+	// We only care about NumVoters here at the table level. NumReplicas is set at
+	// the database level, not at the table/partition level.
+	numVoters, _ := getNumVotersAndNumReplicasForDefaultDatabaseRegions(regionConfig)
+	ret.NumVoters = &numVoters
+	vc, err := synthesizeVoterConstraints(regionConfig.PrimaryRegion(), regionConfig)
+	if err != nil {
+		return nil, err
+	}
+	ret.VoterConstraints = vc
 
-		ret.NullVoterConstraintsIsEmpty = true
-		ret.VoterConstraints = voterConstraints
+	ret.InheritedConstraints = false
+	ret.NullVoterConstraintsIsEmpty = true
 
-		ret.InheritedLeasePreferences = false
-		ret.LeasePreferences = []zonepb.LeasePreference{
-			{Constraints: []zonepb.Constraint{makeRequiredConstraintForRegion(primaryRegion)}},
+	// The total number of replicas is numVotersForZoneSurvival voting replicas
+	// + 1 for each non-primary region.
+	numNonPrimaryRegions := len(regionConfig.Regions()) - 1
+	ret.NumReplicas = proto.Int32(numVoters + int32(numNonPrimaryRegions))
+	ret.Constraints = make([]zonepb.ConstraintsConjunction, len(regionConfig.Regions()))
+	for i, region := range regionConfig.Regions() {
+		ret.Constraints[i] = zonepb.ConstraintsConjunction{
+			NumReplicas: 1,
+			Constraints: []zonepb.Constraint{makeRequiredConstraintForRegion(region)},
 		}
-	case *catpb.LocalityConfig_RegionalByRow_:
-		// We purposely do not set anything here at table level - this should be done at
-		// partition level instead.
-		return ret, nil
 	}
+	// Inherit lease preference from the database. We do nothing here because
+	// `NewZoneConfig()` already marks the field as 'inherited'.
 	return ret, nil
 }
 
+type zoneConfigForMultiRegionTableOptions struct {
+	localLeasePreferences bool
+	confinedSuperRegion   string
+	leaseSuperRegion      string
+}
+
+type zoneConfigForMultiRegionTableOption func(options *zoneConfigForMultiRegionTableOptions)
+
+// withLocalLeasePreferences modifies a zoneConfigForMultiRegionTableOptions
+// so that the leases of REGIONAL BY TABLE tables are kept close to their
+// region: if the region is part of a super region, the lease preference of the
+// region is backed by a second tier which confines the leases to the super
+// region, rather than letting them move to any region should the home region
+// fail.
+func withLocalLeasePreferences(options *zoneConfigForMultiRegionTableOptions) {
+	options.localLeasePreferences = true
+}
+
+// withConfinementToSuperRegion returns a zoneConfigForMultiRegionTableOption
+// which confines all of the replicas of a REGIONAL table to the regions of the
+// given super region. The data homed in a region outside of the super region
+// is placed as if it was homed in a region of the super region, see
+// regionForSuperRegionConfinement. The option also applies to the partitions
+// of REGIONAL BY ROW tables.
+func withConfinementToSuperRegion(superRegionName string) zoneConfigForMultiRegionTableOption {
+	return func(options *zoneConfigForMultiRegionTableOptions) {
+		options.confinedSuperRegion = superRegionName
+	}
+}
+
+// withSuperRegionLeasePreference returns a zoneConfigForMultiRegionTableOption
+// which lets the leases of a table live in any region of the given super
+// region, rather than in its home region alone, see
+// leasePreferencesForSuperRegion. The option applies to tables of any
+// locality, and to the partitions of REGIONAL BY ROW tables.
+func withSuperRegionLeasePreference(superRegionName string) zoneConfigForMultiRegionTableOption {
+	return func(options *zoneConfigForMultiRegionTableOptions) {
+		options.leaseSuperRegion = superRegionName
+	}
+}
+
+// superRegionRegions returns the regions of the given super region, or an
+// error if it does not exist.
+func superRegionRegions(
+	superRegionName string, regionConfig multiregion.RegionConfig,
+) (catpb.RegionNames, error) {
+	for _, superRegion := range regionConfig.SuperRegions() {
+		if superRegion.SuperRegionName == superRegionName && len(superRegion.Regions) > 0 {
+			return superRegion.Regions, nil
+		}
+	}
+	return nil, pgerror.Newf(pgcode.UndefinedObject, "super region %q does not exist", superRegionName)
+}
+
+// leasePreferencesForSuperRegion returns the lease preferences which let the
+// leases of the data homed in the given region live in any region of the
+// given super region. As a lease preference can only hold a conjunction of
+// constraints, the super region is expressed as an ordered list of lease
+// preferences, one per member: the home region first if it is a member, and
+// then the other members in the order of the super region.
+func leasePreferencesForSuperRegion(
+	homeRegion catpb.RegionName, superRegionName string, regionConfig multiregion.RegionConfig,
+) ([]zonepb.LeasePreference, error) {
+	regions, err := superRegionRegions(superRegionName, regionConfig)
+	if err != nil {
+		return nil, err
+	}
+	var prefs []zonepb.LeasePreference
+	for _, region := range regions {
+		if region == homeRegion {
+			prefs = append(prefs, zonepb.LeasePreference{
+				Constraints: []zonepb.Constraint{makeRequiredConstraintForRegion(region)},
+			})
+		}
+	}
+	for _, region := range regions {
+		if region != homeRegion {
+			prefs = append(prefs, zonepb.LeasePreference{
+				Constraints: []zonepb.Constraint{makeRequiredConstraintForRegion(region)},
+			})
+		}
+	}
+	return prefs, nil
+}
+
+// regionForSuperRegionConfinement returns the region in which the data homed
+// in the given region is placed when it is confined to the given super
+// region: the region itself if it is a member of the super region, or else
+// the primary region of the database if it is a member, or else the first
+// region of the super region. An error is returned if the super region does
+// not exist or has too few regions for the given survival goal.
+func regionForSuperRegionConfinement(
+	region catpb.RegionName,
+	superRegionName string,
+	survivalGoal descpb.SurvivalGoal,
+	regionConfig multiregion.RegionConfig,
+) (catpb.RegionName, error) {
+	regions, err := superRegionRegions(superRegionName, regionConfig)
+	if err != nil {
+		return "", err
+	}
+	if err := multiregion.CanSatisfySurvivalGoal(survivalGoal, len(regions)); err != nil {
+		return "", errors.Wrapf(err, "cannot confine the table to super region %q", superRegionName)
+	}
+	for _, candidate := range []catpb.RegionName{region, regionConfig.PrimaryRegion()} {
+		for _, member := range regions {
+			if member == candidate {
+				return candidate, nil
+			}
+		}
+	}
+	return regions[0], nil
+}
+
+// homeZoneConfigForMultiRegionTable sets the fields of the given zone config
+// of a table which place its voters and leases in the given home region. If
+// inSuperRegion is set, all of the replicas of the table are confined to the
+// super region of the home region.
+func homeZoneConfigForMultiRegionTable(
+	zc *zonepb.ZoneConfig,
+	homeRegion catpb.RegionName,
+	inSuperRegion bool,
+	regionConfig multiregion.RegionConfig,
+	options zoneConfigForMultiRegionTableOptions,
+) error {
+	regions := regionConfig.Regions()
+	if inSuperRegion {
+		regions = regionConfig.GetSuperRegionRegionsForRegion(homeRegion)
+	}
+	if err := multiregion.CanSatisfySurvivalGoal(regionConfig.SurvivalGoal(), len(regions)); err != nil {
+		return err
+	}
+
+	numVoters, numReplicas := getNumVotersAndNumReplicas(
+		len(regions), regionConfig.SurvivalGoal(), regionConfig.IsPlacementRestricted(),
+	)
+	zc.NumVoters = &numVoters
+
+	if inSuperRegion {
+		if err := maybeAddConstraintsForSuperRegion(
+			homeRegion, regions, zc, numReplicas, regionConfig,
+		); err != nil {
+			return err
+		}
+	}
+
+	// If the table has a user-specified primary region, use it.
+	voterConstraints, err := synthesizeVoterConstraints(homeRegion, regionConfig)
+	if err != nil {
+		return err
+	}
+
+	zc.NullVoterConstraintsIsEmpty = true
+	zc.VoterConstraints = voterConstraints
+
+	zc.InheritedLeasePreferences = false
+	zc.LeasePreferences = []zonepb.LeasePreference{
+		{Constraints: []zonepb.Constraint{makeRequiredConstraintForRegion(homeRegion)}},
+	}
+	if options.localLeasePreferences && inSuperRegion {
+		zc.LeasePreferences = append(
+			zc.LeasePreferences, superRegionLeasePreferences(homeRegion, regionConfig)...,
+		)
+	}
+	if options.leaseSuperRegion != "" {
+		prefs, err := leasePreferencesForSuperRegion(homeRegion, options.leaseSuperRegion, regionConfig)
+		if err != nil {
+			return err
+		}
+		zc.LeasePreferences = prefs
+	}
+	return nil
+}
+
+// superRegionLeasePreferences returns the backup lease preference which keeps
+// the leases of a table homed in the given region within the super region of
+// the region, by prohibiting every region outside of it. Nothing is returned
+// if the region is not part of a super region, or if the super region spans
+// all of the regions of the database.
+func superRegionLeasePreferences(
+	region catpb.RegionName, regionConfig multiregion.RegionConfig,
+) []zonepb.LeasePreference {
+	// If the region is not part of a super region, all of the regions of the
+	// database are returned and nothing is prohibited.
+	inSuperRegion := make(map[catpb.RegionName]bool)
+	for _, r := range regionConfig.GetSuperRegionRegionsForRegion(region) {
+		inSuperRegion[r] = true
+	}
+	var constraints []zonepb.Constraint
+	for _, r := range regionConfig.Regions() {
+		if !inSuperRegion[r] {
+			constraints = append(constraints, zonepb.Constraint{
+				Type:  zonepb.Constraint_PROHIBITED,
+				Key:   "region",
+				Value: string(r),
+			})
+		}
+	}
+	if len(constraints) == 0 {
+		return nil
+	}
+	return []zonepb.LeasePreference{{Constraints: constraints}}
+}
+
 // applyZoneConfigForMultiRegionTableOption is an option that can be passed into
 // applyZoneConfigForMultiRegionTable.
 type applyZoneConfigForMultiRegionTableOption func(
@@ -560,18 +2506,14 @@ func applyZoneConfigForMultiRegionTableOptionNewIndexes(
 		regionConfig multiregion.RegionConfig,
 		table catalog.TableDescriptor,
 	) (hasNewSubzones bool, newZoneConfig zonepb.ZoneConfig, err error) {
-		for _, indexID := range indexIDs {
-			for _, region := range regionConfig.Regions() {
-				zc, err := zoneConfigForMultiRegionPartition(region, regionConfig)
-				if err != nil {
-					return false, zoneConfig, err
-				}
-				zoneConfig.SetSubzone(zonepb.Subzone{
-					IndexID:       uint32(indexID),
-					PartitionName: string(region),
-					Config:        zc,
-				})
-			}
+		subzones, err := subzoneSpansForMultiRegionTable(
+			regionConfig, regionalByRowTableHomeRegion(table), indexIDs,
+		)
+		if err != nil {
+			return false, zoneConfig, err
+		}
+		for _, subzone := range subzones {
+			zoneConfig.SetSubzone(subzone)
 		}
 		return true, zoneConfig, nil
 	}
@@ -669,8 +2611,9 @@ var ApplyZoneConfigForMultiRegionTableOptionTableAndIndexes = func(
 
 	hasNewSubzones := table.IsLocalityRegionalByRow()
 	if hasNewSubzones {
+		homeRegion := regionalByRowTableHomeRegion(table)
 		for _, region := range regionConfig.Regions() {
-			subzoneConfig, err := zoneConfigForMultiRegionPartition(region, regionConfig)
+			subzoneConfig, err := zoneConfigForMultiRegionPartition(region, homeRegion, regionConfig)
 			if err != nil {
 				return false, zc, err
 			}
@@ -787,6 +2730,30 @@ func ApplyZoneConfigForMultiRegionTable(
 	return err
 }
 
+var zoneConfigAnnotationEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.multiregion.zone_config_annotation.enabled",
+	"if enabled, the zone configs generated for multi-region databases record "+
+		"the survival goal and placement they were generated from, for diagnostics",
+	false,
+)
+
+// maybeAnnotateZoneConfigForMultiRegionDatabase records the survival goal and
+// placement of the supplied RegionConfig on a zone config generated from it by
+// zoneConfigForMultiRegionDatabase, if the
+// sql.multiregion.zone_config_annotation.enabled setting is on.
+func maybeAnnotateZoneConfigForMultiRegionDatabase(
+	sv *settings.Values, zc *zonepb.ZoneConfig, regionConfig multiregion.RegionConfig,
+) {
+	if !zoneConfigAnnotationEnabled.Get(sv) {
+		return
+	}
+	zc.MultiRegionAnnotation = &zonepb.MultiRegionAnnotation{
+		SurvivalGoal: regionConfig.SurvivalGoal().String(),
+		Placement:    regionConfig.Placement().String(),
+	}
+}
+
 // ApplyZoneConfigFromDatabaseRegionConfig applies a zone configuration to the
 // database using the information in the supplied RegionConfig.
 func ApplyZoneConfigFromDatabaseRegionConfig(
@@ -801,6 +2768,7 @@ func ApplyZoneConfigFromDatabaseRegionConfig(
 	if err != nil {
 		return err
 	}
+	maybeAnnotateZoneConfigForMultiRegionDatabase(&execConfig.Settings.SV, &dbZoneConfig, regionConfig)
 	return applyZoneConfigForMultiRegionDatabase(
 		ctx,
 		dbID,
@@ -844,6 +2812,9 @@ func applyZoneConfigForMultiRegionDatabase(
 		mergeZoneConfig,
 		zonepb.MultiRegionZoneConfigFields,
 	)
+	// The annotation describes the multi-region fields, so it is replaced
+	// along with them.
+	newZoneConfig.MultiRegionAnnotation = mergeZoneConfig.MultiRegionAnnotation
 	// If the new zone config is the same as a blank zone config, delete it.
 	if newZoneConfig.Equal(zonepb.NewZoneConfig()) {
 		_, err = execConfig.InternalExecutor.Exec(
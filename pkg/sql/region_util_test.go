@@ -11,13 +11,23 @@
 package sql
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/multiregion"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
 	"github.com/gogo/protobuf/proto"
 	"github.com/stretchr/testify/require"
 )
@@ -306,6 +316,137 @@ func TestZoneConfigForMultiRegionDatabase(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "five regions, two region survival",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
+				"region_b",
+				"region_c",
+				"region_a",
+				"region_d",
+				"region_e",
+			}, "region_b", descpb.SurvivalGoal_TWO_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil),
+			expected: zonepb.ZoneConfig{
+				NumReplicas: proto.Int32(5),
+				NumVoters:   proto.Int32(5),
+				LeasePreferences: []zonepb.LeasePreference{
+					{
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+				},
+				Constraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_d"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_e"},
+						},
+					},
+				},
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "six regions, two region survival",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
+				"region_b",
+				"region_c",
+				"region_a",
+				"region_d",
+				"region_e",
+				"region_f",
+			}, "region_b", descpb.SurvivalGoal_TWO_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil),
+			expected: zonepb.ZoneConfig{
+				NumReplicas: proto.Int32(6),
+				NumVoters:   proto.Int32(5),
+				LeasePreferences: []zonepb.LeasePreference{
+					{
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+				},
+				Constraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_d"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_e"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_f"},
+						},
+					},
+				},
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+				},
+			},
+		},
 		{
 			desc: "one region, restricted placement",
 			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
@@ -361,6 +502,88 @@ func TestZoneConfigForMultiRegionDatabase(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "three regions, zone survival, primary region only voters",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
+				"region_a",
+				"region_b",
+				"region_c",
+			}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithPrimaryRegionOnlyVoters()),
+			expected: zonepb.ZoneConfig{
+				NumReplicas: proto.Int32(5),
+				NumVoters:   proto.Int32(3),
+				LeasePreferences: []zonepb.LeasePreference{
+					{
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+				},
+				Constraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+					{
+						NumReplicas: 3,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
+						},
+					},
+				},
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					{
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "one region, zone survival, primary region only voters",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
+				"region_a",
+			}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithPrimaryRegionOnlyVoters()),
+			expected: zonepb.ZoneConfig{
+				NumReplicas: proto.Int32(3),
+				NumVoters:   proto.Int32(3),
+				LeasePreferences: []zonepb.LeasePreference{
+					{
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+				},
+				Constraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 3,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+				},
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					{
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -372,73 +595,338 @@ func TestZoneConfigForMultiRegionDatabase(t *testing.T) {
 	}
 }
 
-func protoRegionName(region catpb.RegionName) *catpb.RegionName {
-	return &region
-}
-
-func TestZoneConfigForMultiRegionTable(t *testing.T) {
+func TestZoneConfigForMultiRegionDatabasePrimaryRegionOnlyVotersErrors(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
 	testCases := []struct {
-		desc           string
-		localityConfig catpb.LocalityConfig
-		regionConfig   multiregion.RegionConfig
-		expected       zonepb.ZoneConfig
+		desc         string
+		regionConfig multiregion.RegionConfig
+		err          string
 	}{
 		{
-			desc: "4-region global table with zone survival",
-			localityConfig: catpb.LocalityConfig{
-				Locality: &catpb.LocalityConfig_Global_{
-					Global: &catpb.LocalityConfig_Global{},
-				},
-			},
+			desc: "region survival",
 			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
-				"region_b",
-				"region_c",
 				"region_a",
-				"region_d",
-			}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil),
-			expected: zonepb.ZoneConfig{
-				GlobalReads:               proto.Bool(true),
-				InheritedConstraints:      true,
-				InheritedLeasePreferences: true,
-			},
-		},
-		{
-			desc: "4-region global table with region survival",
-			localityConfig: catpb.LocalityConfig{
-				Locality: &catpb.LocalityConfig_Global_{
-					Global: &catpb.LocalityConfig_Global{},
-				},
-			},
-			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
 				"region_b",
 				"region_c",
-				"region_a",
-				"region_d",
-			}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil),
-			expected: zonepb.ZoneConfig{
-				GlobalReads:               proto.Bool(true),
-				InheritedConstraints:      true,
-				InheritedLeasePreferences: true,
-			},
+			}, "region_a", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithPrimaryRegionOnlyVoters()),
+			err: "cannot constrain all voters to the primary region of a region survivable database",
 		},
 		{
-			desc: "4-region regional by row table with zone survival",
-			localityConfig: catpb.LocalityConfig{
-				Locality: &catpb.LocalityConfig_RegionalByRow_{
-					RegionalByRow: &catpb.LocalityConfig_RegionalByRow{},
-				},
-			},
+			desc: "restricted placement",
 			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
-				"region_b",
-				"region_c",
 				"region_a",
-				"region_d",
-			}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil),
-			expected: *(zonepb.NewZoneConfig()),
+				"region_b",
+			}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_RESTRICTED, nil,
+				multiregion.WithPrimaryRegionOnlyVoters()),
+			err: "cannot constrain all voters to the primary region of a database with restricted placement",
 		},
-		{
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := zoneConfigForMultiRegionDatabase(tc.regionConfig)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+// TestZoneConfigForMultiRegionDatabasePrimaryRegionOnlyVoters ensures that
+// constraining all voters to the primary region pins the primary region to
+// exactly the voters, which leaves no room for a non-voter in it, whereas the
+// baseline config only requires a single replica there.
+func TestZoneConfigForMultiRegionDatabasePrimaryRegionOnlyVoters(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c"}
+	baseline, err := zoneConfigForMultiRegionDatabase(multiregion.MakeRegionConfig(
+		regions, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+	))
+	require.NoError(t, err)
+	primaryOnly, err := zoneConfigForMultiRegionDatabase(multiregion.MakeRegionConfig(
+		regions, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+		multiregion.WithPrimaryRegionOnlyVoters(),
+	))
+	require.NoError(t, err)
+
+	require.NotEqual(t, baseline, primaryOnly)
+	// Only the number of replicas of the primary region changes.
+	require.Equal(t, baseline.VoterConstraints, primaryOnly.VoterConstraints)
+	require.Equal(t, baseline.LeasePreferences, primaryOnly.LeasePreferences)
+	require.Equal(t, *baseline.NumReplicas, *primaryOnly.NumReplicas)
+	require.Len(t, primaryOnly.Constraints, len(baseline.Constraints))
+	for i := range baseline.Constraints {
+		require.Equal(t, baseline.Constraints[i].Constraints, primaryOnly.Constraints[i].Constraints)
+		expected := int32(1)
+		if region, _ := regionFromConstraints(baseline.Constraints[i].Constraints); region == "region_b" {
+			require.Equal(t, int32(1), baseline.Constraints[i].NumReplicas)
+			expected = *primaryOnly.NumVoters
+		}
+		require.Equal(t, expected, primaryOnly.Constraints[i].NumReplicas)
+	}
+}
+
+func TestZoneConfigForMultiRegionDatabaseWithVoterWeights(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"}
+	testCases := []struct {
+		desc         string
+		voterWeights map[catpb.RegionName]int32
+		expected     zonepb.ZoneConfig
+		err          string
+	}{
+		{
+			desc: "four regions, two regions with extra voters",
+			voterWeights: map[catpb.RegionName]int32{
+				"region_a": 2,
+				"region_b": 2,
+				"region_c": 1,
+			},
+			expected: zonepb.ZoneConfig{
+				NumReplicas: proto.Int32(6),
+				NumVoters:   proto.Int32(5),
+				LeasePreferences: []zonepb.LeasePreference{
+					{
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+				},
+				Constraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_d"},
+						},
+					},
+				},
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 2,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+					{
+						NumReplicas: 2,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "four regions, one voter per region with an extra in the primary",
+			voterWeights: map[catpb.RegionName]int32{
+				"region_a": 2,
+				"region_b": 1,
+				"region_c": 1,
+				"region_d": 1,
+			},
+			expected: zonepb.ZoneConfig{
+				NumReplicas: proto.Int32(5),
+				NumVoters:   proto.Int32(5),
+				LeasePreferences: []zonepb.LeasePreference{
+					{
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+				},
+				Constraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_d"},
+						},
+					},
+				},
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 2,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_d"},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "weights do not sum to the number of voters",
+			voterWeights: map[catpb.RegionName]int32{
+				"region_a": 2,
+				"region_b": 1,
+			},
+			err: "voter weights sum to 3 but the database requires 5 voters",
+		},
+		{
+			desc: "too many voters in a single region",
+			voterWeights: map[catpb.RegionName]int32{
+				"region_a": 3,
+				"region_b": 2,
+			},
+			err: `voter weight 3 for region "region_a" exceeds the maximum of 2 voters per region`,
+		},
+		{
+			desc: "no voters in the primary region",
+			voterWeights: map[catpb.RegionName]int32{
+				"region_b": 2,
+				"region_c": 2,
+				"region_d": 1,
+			},
+			err: `primary region "region_a" must be assigned at least one voter`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			regionConfig := multiregion.MakeRegionConfig(
+				regions, "region_a", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithVoterWeights(tc.voterWeights),
+			)
+			res, err := zoneConfigForMultiRegionDatabase(regionConfig)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func protoRegionName(region catpb.RegionName) *catpb.RegionName {
+	return &region
+}
+
+func TestZoneConfigForMultiRegionTable(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		desc           string
+		localityConfig catpb.LocalityConfig
+		regionConfig   multiregion.RegionConfig
+		expected       zonepb.ZoneConfig
+	}{
+		{
+			desc: "4-region global table with zone survival",
+			localityConfig: catpb.LocalityConfig{
+				Locality: &catpb.LocalityConfig_Global_{
+					Global: &catpb.LocalityConfig_Global{},
+				},
+			},
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
+				"region_b",
+				"region_c",
+				"region_a",
+				"region_d",
+			}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil),
+			expected: zonepb.ZoneConfig{
+				GlobalReads:               proto.Bool(true),
+				InheritedConstraints:      true,
+				InheritedLeasePreferences: true,
+			},
+		},
+		{
+			desc: "4-region global table with region survival",
+			localityConfig: catpb.LocalityConfig{
+				Locality: &catpb.LocalityConfig_Global_{
+					Global: &catpb.LocalityConfig_Global{},
+				},
+			},
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
+				"region_b",
+				"region_c",
+				"region_a",
+				"region_d",
+			}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil),
+			expected: zonepb.ZoneConfig{
+				GlobalReads:               proto.Bool(true),
+				InheritedConstraints:      true,
+				InheritedLeasePreferences: true,
+			},
+		},
+		{
+			desc: "4-region regional by row table with zone survival",
+			localityConfig: catpb.LocalityConfig{
+				Locality: &catpb.LocalityConfig_RegionalByRow_{
+					RegionalByRow: &catpb.LocalityConfig_RegionalByRow{},
+				},
+			},
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
+				"region_b",
+				"region_c",
+				"region_a",
+				"region_d",
+			}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil),
+			expected: *(zonepb.NewZoneConfig()),
+		},
+		{
 			desc: "4-region regional by row table with region survival",
 			localityConfig: catpb.LocalityConfig{
 				Locality: &catpb.LocalityConfig_RegionalByRow_{
@@ -623,12 +1111,75 @@ func TestZoneConfigForMultiRegionTable(t *testing.T) {
 	}
 }
 
+// TestZoneConfigForGlobalTableInRestrictedDatabase locks in the zone config
+// of a GLOBAL table in a database with RESTRICTED placement, which overrides
+// the placement of the database to spread replicas across all regions.
+func TestZoneConfigForGlobalTableInRestrictedDatabase(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	regions := catpb.RegionNames{"region_b", "region_c", "region_a", "region_d"}
+	regionConfig := multiregion.MakeRegionConfig(
+		regions, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_RESTRICTED, nil,
+	)
+	expected := zonepb.ZoneConfig{
+		NumReplicas:                 proto.Int32(6),
+		NumVoters:                   proto.Int32(3),
+		GlobalReads:                 proto.Bool(true),
+		InheritedConstraints:        false,
+		NullVoterConstraintsIsEmpty: true,
+		InheritedLeasePreferences:   true,
+		VoterConstraints: []zonepb.ConstraintsConjunction{
+			{
+				Constraints: []zonepb.Constraint{
+					{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+				},
+			},
+		},
+	}
+	for _, region := range regions {
+		expected.Constraints = append(expected.Constraints, zonepb.ConstraintsConjunction{
+			NumReplicas: 1,
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: string(region)},
+			},
+		})
+	}
+
+	zc, err := zoneConfigForGlobalTableInRestrictedDatabase(regionConfig)
+	require.NoError(t, err)
+	require.Equal(t, expected, *zc)
+
+	// The zone config of the table is the one built by the helper.
+	zc, err = zoneConfigForMultiRegionTable(
+		catpb.LocalityConfig{Locality: &catpb.LocalityConfig_Global_{}}, regionConfig,
+	)
+	require.NoError(t, err)
+	require.Equal(t, expected, *zc)
+
+	t.Run("default placement", func(t *testing.T) {
+		_, err := zoneConfigForGlobalTableInRestrictedDatabase(multiregion.MakeRegionConfig(
+			regions, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+		))
+		require.True(t, errors.HasAssertionFailure(err))
+		require.Regexp(t, "expected a database with restricted placement", err)
+	})
+
+	t.Run("region survival", func(t *testing.T) {
+		_, err := zoneConfigForGlobalTableInRestrictedDatabase(multiregion.MakeRegionConfig(
+			regions, "region_b", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_RESTRICTED, nil,
+		))
+		require.True(t, errors.HasAssertionFailure(err))
+		require.Regexp(t, "to survive zone failures", err)
+	})
+}
+
 func TestZoneConfigForMultiRegionPartition(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
 	testCases := []struct {
 		desc         string
 		region       catpb.RegionName
+		homeRegion   catpb.RegionName
 		regionConfig multiregion.RegionConfig
 		expected     zonepb.ZoneConfig
 	}{
@@ -693,74 +1244,35 @@ func TestZoneConfigForMultiRegionPartition(t *testing.T) {
 				},
 			},
 		},
-	}
-	for _, tc := range testCases {
-		t.Run(tc.desc, func(t *testing.T) {
-			zc, err := zoneConfigForMultiRegionPartition(tc.region, tc.regionConfig)
-			require.NoError(t, err)
-			require.Equal(t, tc.expected, zc)
-		})
-	}
-}
-
-func TestZoneConfigForRegionalByTableWithSuperRegions(t *testing.T) {
-	defer leaktest.AfterTest(t)()
-
-	const validMultiRegionEnumID = 100
-
-	testCases := []struct {
-		desc           string
-		localityConfig catpb.LocalityConfig
-		regionConfig   multiregion.RegionConfig
-		expected       zonepb.ZoneConfig
-	}{
 		{
-			desc: "super region with regional table, zone failure",
-			localityConfig: catpb.LocalityConfig{
-				Locality: &catpb.LocalityConfig_RegionalByTable_{
-					RegionalByTable: &catpb.LocalityConfig_RegionalByTable{
-						Region: protoRegionName("region_b"),
-					},
-				},
-			},
+			desc:       "home region partition with region survivability falls back to the primary region",
+			region:     "region_a",
+			homeRegion: "region_a",
 			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
 				"region_b",
 				"region_c",
 				"region_a",
 				"region_d",
-			}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT, []descpb.SuperRegion{
-				{
-					SuperRegionName: "super_region_ab",
-					Regions:         catpb.RegionNames{"region_a", "region_b"},
-				},
-			}),
+			}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil),
 			expected: zonepb.ZoneConfig{
-				NumReplicas:                 proto.Int32(4),
-				NumVoters:                   proto.Int32(3),
-				InheritedConstraints:        false,
+				NumReplicas:                 nil, // Set at the database level.
+				NumVoters:                   proto.Int32(5),
+				InheritedConstraints:        true,
 				NullVoterConstraintsIsEmpty: true,
-				Constraints: []zonepb.ConstraintsConjunction{
+				VoterConstraints: []zonepb.ConstraintsConjunction{
 					{
-						NumReplicas: 1,
+						NumReplicas: 2,
 						Constraints: []zonepb.Constraint{
 							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
 						},
 					},
-					{
-						NumReplicas: 1,
-						Constraints: []zonepb.Constraint{
-							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
-						},
-					},
 				},
-				VoterConstraints: []zonepb.ConstraintsConjunction{
+				LeasePreferences: []zonepb.LeasePreference{
 					{
 						Constraints: []zonepb.Constraint{
-							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
 						},
 					},
-				},
-				LeasePreferences: []zonepb.LeasePreference{
 					{
 						Constraints: []zonepb.Constraint{
 							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
@@ -770,50 +1282,58 @@ func TestZoneConfigForRegionalByTableWithSuperRegions(t *testing.T) {
 			},
 		},
 		{
-			desc: "super region with regional table region failure super region with 3 regions",
-			localityConfig: catpb.LocalityConfig{
-				Locality: &catpb.LocalityConfig_RegionalByTable_{
-					RegionalByTable: &catpb.LocalityConfig_RegionalByTable{
-						Region: protoRegionName("region_b"),
-					},
-				},
-			},
+			desc:       "home region partition with region survivability falls back to the secondary region",
+			region:     "region_a",
+			homeRegion: "region_a",
 			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
 				"region_b",
 				"region_c",
 				"region_a",
 				"region_d",
-			}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT, []descpb.SuperRegion{
-				{
-					SuperRegionName: "super_region_abc",
-					Regions:         catpb.RegionNames{"region_a", "region_b", "region_c"},
-				},
-			}),
+			}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithSecondaryRegion("region_c")),
 			expected: zonepb.ZoneConfig{
-				NumReplicas:                 proto.Int32(5),
+				NumReplicas:                 nil, // Set at the database level.
 				NumVoters:                   proto.Int32(5),
-				InheritedConstraints:        false,
+				InheritedConstraints:        true,
 				NullVoterConstraintsIsEmpty: true,
-				Constraints: []zonepb.ConstraintsConjunction{
+				VoterConstraints: []zonepb.ConstraintsConjunction{
 					{
 						NumReplicas: 2,
 						Constraints: []zonepb.Constraint{
 							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
 						},
 					},
+				},
+				LeasePreferences: []zonepb.LeasePreference{
 					{
-						NumReplicas: 1,
 						Constraints: []zonepb.Constraint{
-							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
 						},
 					},
 					{
-						NumReplicas: 1,
 						Constraints: []zonepb.Constraint{
 							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
 						},
 					},
 				},
+			},
+		},
+		{
+			desc:       "primary home region partition with region survivability",
+			region:     "region_b",
+			homeRegion: "region_b",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
+				"region_b",
+				"region_c",
+				"region_a",
+				"region_d",
+			}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil),
+			expected: zonepb.ZoneConfig{
+				NumReplicas:                 nil, // Set at the database level.
+				NumVoters:                   proto.Int32(5),
+				InheritedConstraints:        true,
+				NullVoterConstraintsIsEmpty: true,
 				VoterConstraints: []zonepb.ConstraintsConjunction{
 					{
 						NumReplicas: 2,
@@ -832,68 +1352,63 @@ func TestZoneConfigForRegionalByTableWithSuperRegions(t *testing.T) {
 			},
 		},
 		{
-			desc: "super region with regional table region failure super region with 4 regions",
-			localityConfig: catpb.LocalityConfig{
-				Locality: &catpb.LocalityConfig_RegionalByTable_{
-					RegionalByTable: &catpb.LocalityConfig_RegionalByTable{
-						Region: protoRegionName("region_b"),
-					},
-				},
-			},
+			desc:       "home region partition with zone survivability",
+			region:     "region_a",
+			homeRegion: "region_a",
 			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
 				"region_b",
 				"region_c",
 				"region_a",
 				"region_d",
-			}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT, []descpb.SuperRegion{
-				{
-					SuperRegionName: "super_region_abcd",
-					Regions:         catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"},
-				},
-			}),
+			}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil),
 			expected: zonepb.ZoneConfig{
-				NumReplicas:                 proto.Int32(5),
-				NumVoters:                   proto.Int32(5),
-				InheritedConstraints:        false,
+				NumReplicas:                 nil, // Set at the database level.
+				NumVoters:                   proto.Int32(3),
+				InheritedConstraints:        true,
 				NullVoterConstraintsIsEmpty: true,
-				Constraints: []zonepb.ConstraintsConjunction{
+				VoterConstraints: []zonepb.ConstraintsConjunction{
 					{
-						NumReplicas: 1,
 						Constraints: []zonepb.Constraint{
 							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
 						},
 					},
+				},
+				LeasePreferences: []zonepb.LeasePreference{
 					{
-						NumReplicas: 1,
-						Constraints: []zonepb.Constraint{
-							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
-						},
-					},
-					{
-						NumReplicas: 1,
-						Constraints: []zonepb.Constraint{
-							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
-						},
-					},
-					{
-						NumReplicas: 1,
 						Constraints: []zonepb.Constraint{
-							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_d"},
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
 						},
 					},
 				},
+			},
+		},
+		{
+			desc:       "partition of another region than the home region",
+			region:     "region_a",
+			homeRegion: "region_c",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
+				"region_b",
+				"region_c",
+				"region_a",
+				"region_d",
+			}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil),
+			expected: zonepb.ZoneConfig{
+				NumReplicas:                 nil, // Set at the database level.
+				NumVoters:                   proto.Int32(5),
+				InheritedConstraints:        true,
+				NullVoterConstraintsIsEmpty: true,
 				VoterConstraints: []zonepb.ConstraintsConjunction{
 					{
 						NumReplicas: 2,
 						Constraints: []zonepb.Constraint{
-							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
 						},
 					},
 				},
 				LeasePreferences: []zonepb.LeasePreference{
 					{
 						Constraints: []zonepb.Constraint{
-							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
 						},
 					},
 				},
@@ -902,35 +1417,127 @@ func TestZoneConfigForRegionalByTableWithSuperRegions(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
-			err := multiregion.ValidateRegionConfig(tc.regionConfig)
-			require.NoError(t, err)
-			zc, err := zoneConfigForMultiRegionTable(tc.localityConfig, tc.regionConfig)
+			zc, err := zoneConfigForMultiRegionPartition(tc.region, tc.homeRegion, tc.regionConfig)
 			require.NoError(t, err)
-			require.Equal(t, tc.expected, *zc)
+			require.Equal(t, tc.expected, zc)
 		})
 	}
 }
 
-func TestZoneConfigForRegionalByRowPartitionsWithSuperRegions(t *testing.T) {
+func TestRegionalByRowTableHomeRegion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	regionalByRow := &catpb.LocalityConfig{
+		Locality: &catpb.LocalityConfig_RegionalByRow_{
+			RegionalByRow: &catpb.LocalityConfig_RegionalByRow{},
+		},
+	}
+	makeTable := func(
+		localityConfig *catpb.LocalityConfig, nullable bool, defaultExpr string,
+	) catalog.TableDescriptor {
+		return tabledesc.NewBuilder(&descpb.TableDescriptor{
+			ID:       104,
+			Name:     "t",
+			ParentID: 100,
+			Columns: []descpb.ColumnDescriptor{
+				{ID: 1, Name: "crdb_region", Type: types.String, Nullable: nullable, DefaultExpr: &defaultExpr},
+			},
+			NextColumnID: 2,
+			PrimaryIndex: descpb.IndexDescriptor{
+				ID:                  1,
+				Name:                "t_pkey",
+				KeyColumnIDs:        []descpb.ColumnID{1},
+				KeyColumnNames:      []string{"crdb_region"},
+				KeyColumnDirections: []descpb.IndexDescriptor_Direction{descpb.IndexDescriptor_ASC},
+			},
+			NextIndexID:    2,
+			LocalityConfig: localityConfig,
+		}).BuildImmutableTable()
+	}
+
+	const constantDefault = `'region_b':::@100052`
+	const gatewayDefault = `default_to_database_primary_region(gateway_region())::@100052`
+	testCases := []struct {
+		desc     string
+		table    catalog.TableDescriptor
+		expected catpb.RegionName
+	}{
+		{
+			desc:     "constant default",
+			table:    makeTable(regionalByRow, false /* nullable */, constantDefault),
+			expected: "region_b",
+		},
+		{
+			desc:  "gateway region default",
+			table: makeTable(regionalByRow, false /* nullable */, gatewayDefault),
+		},
+		{
+			desc:  "nullable region column",
+			table: makeTable(regionalByRow, true /* nullable */, constantDefault),
+		},
+		{
+			desc: "regional by table",
+			table: makeTable(&catpb.LocalityConfig{
+				Locality: &catpb.LocalityConfig_RegionalByTable_{
+					RegionalByTable: &catpb.LocalityConfig_RegionalByTable{},
+				},
+			}, false /* nullable */, constantDefault),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.expected, regionalByRowTableHomeRegion(tc.table))
+		})
+	}
+
+	// The partition of the home region of the table gets the fallback lease
+	// preference when the zone config of the table is generated.
+	regionConfig := multiregion.MakeRegionConfig(
+		catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_a",
+		descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+	)
+	_, zc, err := ApplyZoneConfigForMultiRegionTableOptionTableAndIndexes(
+		*zonepb.NewZoneConfig(), regionConfig, testCases[0].table,
+	)
+	require.NoError(t, err)
+	for _, subzone := range zc.Subzones {
+		expected := []zonepb.LeasePreference{{
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: subzone.PartitionName},
+			},
+		}}
+		if subzone.PartitionName == "region_b" {
+			expected = append(expected, zonepb.LeasePreference{
+				Constraints: []zonepb.Constraint{
+					{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+				},
+			})
+		}
+		require.Equal(t, expected, subzone.Config.LeasePreferences, "partition %s", subzone.PartitionName)
+	}
+	require.Len(t, zc.Subzones, 3)
+}
+
+func TestZoneConfigForRegionalByTableWithSuperRegions(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
 	const validMultiRegionEnumID = 100
 
 	testCases := []struct {
 		desc           string
-		region         catpb.RegionName
 		localityConfig catpb.LocalityConfig
 		regionConfig   multiregion.RegionConfig
 		expected       zonepb.ZoneConfig
 	}{
 		{
-			desc: "super region with regional by row, zone failure, partition region_a",
+			desc: "super region with regional table, zone failure",
 			localityConfig: catpb.LocalityConfig{
-				Locality: &catpb.LocalityConfig_RegionalByRow_{
-					RegionalByRow: &catpb.LocalityConfig_RegionalByRow{},
+				Locality: &catpb.LocalityConfig_RegionalByTable_{
+					RegionalByTable: &catpb.LocalityConfig_RegionalByTable{
+						Region: protoRegionName("region_b"),
+					},
 				},
 			},
-			region: "region_a",
 			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
 				"region_b",
 				"region_c",
@@ -963,48 +1570,48 @@ func TestZoneConfigForRegionalByRowPartitionsWithSuperRegions(t *testing.T) {
 				},
 				VoterConstraints: []zonepb.ConstraintsConjunction{
 					{
-						NumReplicas: 0,
 						Constraints: []zonepb.Constraint{
-							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
 						},
 					},
 				},
 				LeasePreferences: []zonepb.LeasePreference{
 					{
 						Constraints: []zonepb.Constraint{
-							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
 						},
 					},
 				},
 			},
 		},
 		{
-			desc: "super region with regional by row, zone failure, partition region_b",
+			desc: "super region with regional table region failure super region with 3 regions",
 			localityConfig: catpb.LocalityConfig{
-				Locality: &catpb.LocalityConfig_RegionalByRow_{
-					RegionalByRow: &catpb.LocalityConfig_RegionalByRow{},
+				Locality: &catpb.LocalityConfig_RegionalByTable_{
+					RegionalByTable: &catpb.LocalityConfig_RegionalByTable{
+						Region: protoRegionName("region_b"),
+					},
 				},
 			},
-			region: "region_b",
 			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
 				"region_b",
 				"region_c",
 				"region_a",
 				"region_d",
-			}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT, []descpb.SuperRegion{
+			}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT, []descpb.SuperRegion{
 				{
-					SuperRegionName: "super_region_ab",
-					Regions:         catpb.RegionNames{"region_a", "region_b"},
+					SuperRegionName: "super_region_abc",
+					Regions:         catpb.RegionNames{"region_a", "region_b", "region_c"},
 				},
 			}),
 			expected: zonepb.ZoneConfig{
-				NumReplicas:                 proto.Int32(4),
-				NumVoters:                   proto.Int32(3),
+				NumReplicas:                 proto.Int32(5),
+				NumVoters:                   proto.Int32(5),
 				InheritedConstraints:        false,
 				NullVoterConstraintsIsEmpty: true,
 				Constraints: []zonepb.ConstraintsConjunction{
 					{
-						NumReplicas: 1,
+						NumReplicas: 2,
 						Constraints: []zonepb.Constraint{
 							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
 						},
@@ -1015,10 +1622,16 @@ func TestZoneConfigForRegionalByRowPartitionsWithSuperRegions(t *testing.T) {
 							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
 						},
 					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
+						},
+					},
 				},
 				VoterConstraints: []zonepb.ConstraintsConjunction{
 					{
-						NumReplicas: 0,
+						NumReplicas: 2,
 						Constraints: []zonepb.Constraint{
 							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
 						},
@@ -1034,13 +1647,14 @@ func TestZoneConfigForRegionalByRowPartitionsWithSuperRegions(t *testing.T) {
 			},
 		},
 		{
-			desc: "super region with regional by row, region failure, partition region_a",
+			desc: "super region with regional table region failure super region with 4 regions",
 			localityConfig: catpb.LocalityConfig{
-				Locality: &catpb.LocalityConfig_RegionalByRow_{
-					RegionalByRow: &catpb.LocalityConfig_RegionalByRow{},
+				Locality: &catpb.LocalityConfig_RegionalByTable_{
+					RegionalByTable: &catpb.LocalityConfig_RegionalByTable{
+						Region: protoRegionName("region_b"),
+					},
 				},
 			},
-			region: "region_a",
 			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
 				"region_b",
 				"region_c",
@@ -1048,8 +1662,8 @@ func TestZoneConfigForRegionalByRowPartitionsWithSuperRegions(t *testing.T) {
 				"region_d",
 			}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT, []descpb.SuperRegion{
 				{
-					SuperRegionName: "super_region_abc",
-					Regions:         catpb.RegionNames{"region_a", "region_b", "region_c"},
+					SuperRegionName: "super_region_abcd",
+					Regions:         catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"},
 				},
 			}),
 			expected: zonepb.ZoneConfig{
@@ -1065,7 +1679,7 @@ func TestZoneConfigForRegionalByRowPartitionsWithSuperRegions(t *testing.T) {
 						},
 					},
 					{
-						NumReplicas: 2,
+						NumReplicas: 1,
 						Constraints: []zonepb.Constraint{
 							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
 						},
@@ -1076,51 +1690,570 @@ func TestZoneConfigForRegionalByRowPartitionsWithSuperRegions(t *testing.T) {
 							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
 						},
 					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_d"},
+						},
+					},
 				},
 				VoterConstraints: []zonepb.ConstraintsConjunction{
 					{
 						NumReplicas: 2,
 						Constraints: []zonepb.Constraint{
-							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
 						},
 					},
 				},
 				LeasePreferences: []zonepb.LeasePreference{
 					{
 						Constraints: []zonepb.Constraint{
-							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
 						},
 					},
 				},
 			},
 		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := multiregion.ValidateRegionConfig(tc.regionConfig)
+			require.NoError(t, err)
+			zc, err := zoneConfigForMultiRegionTable(tc.localityConfig, tc.regionConfig)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, *zc)
+		})
+	}
+}
+
+// TestZoneConfigForRegionalByTableWithTwoRegionSuperRegion guards the replica
+// counts of a regional table homed in the primary region, when the primary
+// region is part of a super region with the minimum of two regions under zone
+// survival: 3 voters in the home region and a single replica in the other
+// region of the super region.
+func TestZoneConfigForRegionalByTableWithTwoRegionSuperRegion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const validMultiRegionEnumID = 100
+
+	regionConfig := multiregion.MakeRegionConfig(catpb.RegionNames{
+		"region_a",
+		"region_b",
+		"region_c",
+	}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT, []descpb.SuperRegion{
 		{
-			desc: "super region with regional by row, region failure, partition region_b",
+			SuperRegionName: "super_region_ab",
+			Regions:         catpb.RegionNames{"region_a", "region_b"},
+		},
+	})
+	require.NoError(t, multiregion.ValidateRegionConfig(regionConfig))
+
+	for _, region := range []*catpb.RegionName{nil, protoRegionName("region_a")} {
+		localityConfig := catpb.LocalityConfig{
+			Locality: &catpb.LocalityConfig_RegionalByTable_{
+				RegionalByTable: &catpb.LocalityConfig_RegionalByTable{Region: region},
+			},
+		}
+		zc, err := zoneConfigForMultiRegionTable(localityConfig, regionConfig)
+		require.NoError(t, err)
+		require.Equal(t, zonepb.ZoneConfig{
+			NumReplicas:                 proto.Int32(4),
+			NumVoters:                   proto.Int32(3),
+			InheritedConstraints:        false,
+			NullVoterConstraintsIsEmpty: true,
+			Constraints: []zonepb.ConstraintsConjunction{
+				{
+					NumReplicas: 1,
+					Constraints: []zonepb.Constraint{
+						{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+					},
+				},
+				{
+					NumReplicas: 1,
+					Constraints: []zonepb.Constraint{
+						{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+					},
+				},
+			},
+			VoterConstraints: []zonepb.ConstraintsConjunction{
+				{
+					Constraints: []zonepb.Constraint{
+						{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+					},
+				},
+			},
+			LeasePreferences: []zonepb.LeasePreference{
+				{
+					Constraints: []zonepb.Constraint{
+						{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+					},
+				},
+			},
+		}, *zc)
+	}
+
+	// Too few replicas to place the voters of the home region.
+	zc := zonepb.NewZoneConfig()
+	zc.NumVoters = proto.Int32(3)
+	err := maybeAddConstraintsForSuperRegion(
+		"region_a", catpb.RegionNames{"region_a", "region_b"}, zc, 2 /* numReplicas */, regionConfig,
+	)
+	require.EqualError(t, err, "super region of region_a has 3 voters but only 2 replicas")
+}
+
+// TestZoneConfigForRegionalByTableIgnoringSuperRegion compares the zone config
+// of a regional table bound to the super region of its region with the one of
+// a table which opts out of it, and which is spread like in a database without
+// super regions.
+func TestZoneConfigForRegionalByTableIgnoringSuperRegion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const validMultiRegionEnumID = 100
+
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"}
+	superRegions := []descpb.SuperRegion{
+		{
+			SuperRegionName: "super_region_abc",
+			Regions:         catpb.RegionNames{"region_a", "region_b", "region_c"},
+		},
+	}
+	for _, survivalGoal := range []descpb.SurvivalGoal{
+		descpb.SurvivalGoal_ZONE_FAILURE, descpb.SurvivalGoal_REGION_FAILURE,
+	} {
+		t.Run(survivalGoal.String(), func(t *testing.T) {
+			regionConfig := multiregion.MakeRegionConfig(
+				regions, "region_a", survivalGoal, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT, superRegions,
+			)
+			require.NoError(t, multiregion.ValidateRegionConfig(regionConfig))
+			withoutSuperRegions := multiregion.MakeRegionConfig(
+				regions, "region_a", survivalGoal, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT, nil,
+			)
+
+			for _, region := range []*catpb.RegionName{nil, protoRegionName("region_b")} {
+				localityConfig := func(ignoreSuperRegion bool) catpb.LocalityConfig {
+					return catpb.LocalityConfig{
+						Locality: &catpb.LocalityConfig_RegionalByTable_{
+							RegionalByTable: &catpb.LocalityConfig_RegionalByTable{
+								Region:            region,
+								IgnoreSuperRegion: ignoreSuperRegion,
+							},
+						},
+					}
+				}
+
+				// The table bound to the super region has all of its replicas
+				// constrained to it.
+				bound, err := zoneConfigForMultiRegionTable(localityConfig(false), regionConfig)
+				require.NoError(t, err)
+				require.False(t, bound.InheritedConstraints)
+				require.NotEmpty(t, bound.Constraints)
+				for _, c := range bound.Constraints {
+					require.NotEqual(t, "region_d", c.Constraints[0].Value)
+				}
+
+				// The opted-out table is spread across all of the regions.
+				optedOut, err := zoneConfigForMultiRegionTable(localityConfig(true), regionConfig)
+				require.NoError(t, err)
+				expected, err := zoneConfigForMultiRegionTable(localityConfig(false), withoutSuperRegions)
+				require.NoError(t, err)
+				require.Equal(t, *expected, *optedOut)
+				require.True(t, optedOut.InheritedConstraints)
+				require.Empty(t, optedOut.Constraints)
+				require.NotEqual(t, *bound, *optedOut)
+			}
+		})
+	}
+}
+
+// TestZoneConfigForRegionalByTableWithLocalLeasePreferences checks that
+// withLocalLeasePreferences backs the lease preference of a regional table
+// under region survival with a tier confined to the super region of its
+// region, and leaves it alone if the region is not part of a super region.
+func TestZoneConfigForRegionalByTableWithLocalLeasePreferences(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const validMultiRegionEnumID = 100
+
+	regions := catpb.RegionNames{"region_b", "region_c", "region_a", "region_d", "region_e"}
+	localityConfig := catpb.LocalityConfig{
+		Locality: &catpb.LocalityConfig_RegionalByTable_{
+			RegionalByTable: &catpb.LocalityConfig_RegionalByTable{
+				Region: protoRegionName("region_c"),
+			},
+		},
+	}
+	homeLeasePreference := zonepb.LeasePreference{
+		Constraints: []zonepb.Constraint{
+			{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
+		},
+	}
+
+	testCases := []struct {
+		desc         string
+		superRegions []descpb.SuperRegion
+		expected     []zonepb.LeasePreference
+	}{
+		{
+			desc:     "no super region",
+			expected: []zonepb.LeasePreference{homeLeasePreference},
+		},
+		{
+			desc: "super region",
+			superRegions: []descpb.SuperRegion{
+				{
+					SuperRegionName: "super_region_acd",
+					Regions:         catpb.RegionNames{"region_a", "region_c", "region_d"},
+				},
+			},
+			expected: []zonepb.LeasePreference{
+				homeLeasePreference,
+				{
+					Constraints: []zonepb.Constraint{
+						{Type: zonepb.Constraint_PROHIBITED, Key: "region", Value: "region_b"},
+						{Type: zonepb.Constraint_PROHIBITED, Key: "region", Value: "region_e"},
+					},
+				},
+			},
+		},
+		{
+			desc: "super region of another region",
+			superRegions: []descpb.SuperRegion{
+				{
+					SuperRegionName: "super_region_abd",
+					Regions:         catpb.RegionNames{"region_a", "region_b", "region_d"},
+				},
+			},
+			expected: []zonepb.LeasePreference{homeLeasePreference},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			regionConfig := multiregion.MakeRegionConfig(
+				regions, "region_b", descpb.SurvivalGoal_REGION_FAILURE, validMultiRegionEnumID,
+				descpb.DataPlacement_DEFAULT, tc.superRegions,
+			)
+			require.NoError(t, multiregion.ValidateRegionConfig(regionConfig))
+
+			zc, err := zoneConfigForMultiRegionTable(localityConfig, regionConfig, withLocalLeasePreferences)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, zc.LeasePreferences)
+			require.False(t, zc.InheritedLeasePreferences)
+
+			// Only the lease preferences differ from the default zone config.
+			defaultZC, err := zoneConfigForMultiRegionTable(localityConfig, regionConfig)
+			require.NoError(t, err)
+			require.Equal(t, []zonepb.LeasePreference{homeLeasePreference}, defaultZC.LeasePreferences)
+			zc.LeasePreferences = defaultZC.LeasePreferences
+			require.Equal(t, *defaultZC, *zc)
+		})
+	}
+}
+
+// TestZoneConfigForTableConfinedToSuperRegion ensures that all of the
+// replicas of a table confined to a super region are placed in its regions,
+// including those of the data homed outside of it.
+func TestZoneConfigForTableConfinedToSuperRegion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const validMultiRegionEnumID = 100
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c", "region_d", "region_e"}
+	superRegions := []descpb.SuperRegion{
+		{
+			SuperRegionName: "super_region_bcd",
+			Regions:         catpb.RegionNames{"region_b", "region_c", "region_d"},
+		},
+	}
+	regionConfig := multiregion.MakeRegionConfig(
+		regions, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT, superRegions,
+	)
+	confined := withConfinementToSuperRegion("super_region_bcd")
+
+	// expected returns the zone config of the data homed in the given member
+	// of the super region.
+	expected := func(homeRegion string) zonepb.ZoneConfig {
+		zc := zonepb.ZoneConfig{
+			NumReplicas:                 proto.Int32(5),
+			NumVoters:                   proto.Int32(3),
+			NullVoterConstraintsIsEmpty: true,
+			VoterConstraints: []zonepb.ConstraintsConjunction{
+				{
+					Constraints: []zonepb.Constraint{
+						{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: homeRegion},
+					},
+				},
+			},
+			LeasePreferences: []zonepb.LeasePreference{
+				{
+					Constraints: []zonepb.Constraint{
+						{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: homeRegion},
+					},
+				},
+			},
+		}
+		for _, region := range superRegions[0].Regions {
+			zc.Constraints = append(zc.Constraints, zonepb.ConstraintsConjunction{
+				NumReplicas: 1,
+				Constraints: []zonepb.Constraint{
+					{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: string(region)},
+				},
+			})
+		}
+		return zc
+	}
+
+	t.Run("tables", func(t *testing.T) {
+		for _, tc := range []struct {
+			desc           string
+			localityConfig catpb.LocalityConfig
+			homeRegion     string
+		}{
+			{
+				desc:           "regional by row",
+				localityConfig: catpb.LocalityConfig{Locality: &catpb.LocalityConfig_RegionalByRow_{}},
+				// The primary region is not a member of the super region.
+				homeRegion: "region_b",
+			},
+			{
+				desc: "regional by table in a member region",
+				localityConfig: catpb.LocalityConfig{
+					Locality: &catpb.LocalityConfig_RegionalByTable_{
+						RegionalByTable: &catpb.LocalityConfig_RegionalByTable{Region: protoRegionName("region_c")},
+					},
+				},
+				homeRegion: "region_c",
+			},
+			{
+				desc: "regional by table outside of the super region",
+				localityConfig: catpb.LocalityConfig{
+					Locality: &catpb.LocalityConfig_RegionalByTable_{
+						RegionalByTable: &catpb.LocalityConfig_RegionalByTable{Region: protoRegionName("region_e")},
+					},
+				},
+				homeRegion: "region_b",
+			},
+		} {
+			t.Run(tc.desc, func(t *testing.T) {
+				zc, err := zoneConfigForMultiRegionTable(tc.localityConfig, regionConfig, confined)
+				require.NoError(t, err)
+				require.Equal(t, expected(tc.homeRegion), *zc)
+				require.NoError(t, zc.Validate())
+			})
+		}
+	})
+
+	t.Run("partitions", func(t *testing.T) {
+		for region, homeRegion := range map[catpb.RegionName]string{
+			"region_a": "region_b",
+			"region_b": "region_b",
+			"region_c": "region_c",
+			"region_d": "region_d",
+			"region_e": "region_b",
+		} {
+			zc, err := zoneConfigForMultiRegionPartition(region, "" /* homeRegion */, regionConfig, confined)
+			require.NoError(t, err)
+			require.Equal(t, expected(homeRegion), zc, "partition %s", region)
+			require.NoError(t, zc.Validate())
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := zoneConfigForMultiRegionTable(
+			catpb.LocalityConfig{Locality: &catpb.LocalityConfig_RegionalByRow_{}},
+			regionConfig,
+			withConfinementToSuperRegion("super_region_xyz"),
+		)
+		require.Regexp(t, `super region "super_region_xyz" does not exist`, err)
+
+		_, err = zoneConfigForMultiRegionTable(
+			catpb.LocalityConfig{Locality: &catpb.LocalityConfig_Global_{}}, regionConfig, confined,
+		)
+		require.True(t, errors.HasAssertionFailure(err))
+
+		// A super region with two regions cannot survive a region failure.
+		_, err = zoneConfigForMultiRegionPartition(
+			"region_a", "" /* homeRegion */, multiregion.MakeRegionConfig(
+				regions, "region_a", descpb.SurvivalGoal_REGION_FAILURE, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT,
+				[]descpb.SuperRegion{{SuperRegionName: "super_region_bc", Regions: catpb.RegionNames{"region_b", "region_c"}}},
+			),
+			withConfinementToSuperRegion("super_region_bc"),
+		)
+		require.Regexp(t, `cannot confine the table to super region "super_region_bc": at least 3 regions are required for surviving a region failure`, err)
+	})
+}
+
+// TestZoneConfigWithSuperRegionLeasePreference ensures that the lease
+// preferences generated for a table whose leases may live anywhere in a
+// super region cover all of the members of the super region, starting with
+// the home region of the data if it is a member.
+func TestZoneConfigWithSuperRegionLeasePreference(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const validMultiRegionEnumID = 100
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c", "region_d", "region_e"}
+	superRegions := []descpb.SuperRegion{
+		{
+			SuperRegionName: "super_region_bcd",
+			Regions:         catpb.RegionNames{"region_b", "region_c", "region_d"},
+		},
+	}
+	regionConfig := multiregion.MakeRegionConfig(
+		regions, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT, superRegions,
+	)
+	leaseInSuperRegion := withSuperRegionLeasePreference("super_region_bcd")
+
+	// expected returns the lease preferences of the super region, in the
+	// given order of its regions.
+	expected := func(order ...catpb.RegionName) []zonepb.LeasePreference {
+		var prefs []zonepb.LeasePreference
+		for _, region := range order {
+			prefs = append(prefs, zonepb.LeasePreference{
+				Constraints: []zonepb.Constraint{
+					{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: string(region)},
+				},
+			})
+		}
+		return prefs
+	}
+
+	t.Run("tables", func(t *testing.T) {
+		for _, tc := range []struct {
+			desc           string
+			localityConfig catpb.LocalityConfig
+			expected       []zonepb.LeasePreference
+		}{
+			{
+				desc:           "global",
+				localityConfig: catpb.LocalityConfig{Locality: &catpb.LocalityConfig_Global_{}},
+				expected:       expected("region_b", "region_c", "region_d"),
+			},
+			{
+				desc: "regional by table in the primary region",
+				localityConfig: catpb.LocalityConfig{
+					Locality: &catpb.LocalityConfig_RegionalByTable_{
+						RegionalByTable: &catpb.LocalityConfig_RegionalByTable{},
+					},
+				},
+				expected: expected("region_b", "region_c", "region_d"),
+			},
+			{
+				desc: "regional by table in a member region",
+				localityConfig: catpb.LocalityConfig{
+					Locality: &catpb.LocalityConfig_RegionalByTable_{
+						RegionalByTable: &catpb.LocalityConfig_RegionalByTable{Region: protoRegionName("region_c")},
+					},
+				},
+				expected: expected("region_c", "region_b", "region_d"),
+			},
+			{
+				desc: "regional by table outside of the super region",
+				localityConfig: catpb.LocalityConfig{
+					Locality: &catpb.LocalityConfig_RegionalByTable_{
+						RegionalByTable: &catpb.LocalityConfig_RegionalByTable{Region: protoRegionName("region_e")},
+					},
+				},
+				expected: expected("region_b", "region_c", "region_d"),
+			},
+		} {
+			t.Run(tc.desc, func(t *testing.T) {
+				zc, err := zoneConfigForMultiRegionTable(tc.localityConfig, regionConfig, leaseInSuperRegion)
+				require.NoError(t, err)
+				require.False(t, zc.InheritedLeasePreferences)
+				require.Equal(t, tc.expected, zc.LeasePreferences)
+				require.NoError(t, zc.Validate())
+			})
+		}
+	})
+
+	t.Run("partitions", func(t *testing.T) {
+		for region, expectedPrefs := range map[catpb.RegionName][]zonepb.LeasePreference{
+			"region_a": expected("region_b", "region_c", "region_d"),
+			"region_b": expected("region_b", "region_c", "region_d"),
+			"region_d": expected("region_d", "region_b", "region_c"),
+		} {
+			zc, err := zoneConfigForMultiRegionPartition(region, "" /* homeRegion */, regionConfig, leaseInSuperRegion)
+			require.NoError(t, err)
+			require.Equal(t, expectedPrefs, zc.LeasePreferences, "partition %s", region)
+			require.NoError(t, zc.Validate())
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := zoneConfigForMultiRegionTable(
+			catpb.LocalityConfig{Locality: &catpb.LocalityConfig_Global_{}},
+			regionConfig,
+			withSuperRegionLeasePreference("super_region_xyz"),
+		)
+		require.Regexp(t, `super region "super_region_xyz" does not exist`, err)
+	})
+}
+
+func TestZoneConfigForRegionalByTableWithDanglingRegion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const validMultiRegionEnumID = 100
+
+	regionConfig := multiregion.MakeRegionConfig(
+		catpb.RegionNames{"region_a", "region_b"},
+		"region_a",
+		descpb.SurvivalGoal_ZONE_FAILURE,
+		validMultiRegionEnumID,
+		descpb.DataPlacement_DEFAULT,
+		nil,
+	)
+	// region_c was dropped from the database, but the table is still homed
+	// in it.
+	localityConfig := catpb.LocalityConfig{
+		Locality: &catpb.LocalityConfig_RegionalByTable_{
+			RegionalByTable: &catpb.LocalityConfig_RegionalByTable{
+				Region: protoRegionName("region_c"),
+			},
+		},
+	}
+	_, err := zoneConfigForMultiRegionTable(localityConfig, regionConfig)
+	require.EqualError(t, err, `REGIONAL BY TABLE region "region_c" is not a region of the database`)
+	require.Equal(t, pgcode.InvalidTableDefinition, pgerror.GetPGCode(err))
+	require.Equal(t, "available regions: region_a, region_b", errors.FlattenHints(err))
+}
+
+func TestZoneConfigForRegionalByRowPartitionsWithSuperRegions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const validMultiRegionEnumID = 100
+
+	testCases := []struct {
+		desc           string
+		region         catpb.RegionName
+		localityConfig catpb.LocalityConfig
+		regionConfig   multiregion.RegionConfig
+		expected       zonepb.ZoneConfig
+	}{
+		{
+			desc: "super region with regional by row, zone failure, partition region_a",
 			localityConfig: catpb.LocalityConfig{
 				Locality: &catpb.LocalityConfig_RegionalByRow_{
 					RegionalByRow: &catpb.LocalityConfig_RegionalByRow{},
 				},
 			},
-			region: "region_b",
+			region: "region_a",
 			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
 				"region_b",
 				"region_c",
 				"region_a",
 				"region_d",
-			}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT, []descpb.SuperRegion{
+			}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT, []descpb.SuperRegion{
 				{
-					SuperRegionName: "super_region_abc",
-					Regions:         catpb.RegionNames{"region_a", "region_b", "region_c"},
+					SuperRegionName: "super_region_ab",
+					Regions:         catpb.RegionNames{"region_a", "region_b"},
 				},
 			}),
 			expected: zonepb.ZoneConfig{
-				NumReplicas:                 proto.Int32(5),
-				NumVoters:                   proto.Int32(5),
+				NumReplicas:                 proto.Int32(4),
+				NumVoters:                   proto.Int32(3),
 				InheritedConstraints:        false,
 				NullVoterConstraintsIsEmpty: true,
 				Constraints: []zonepb.ConstraintsConjunction{
 					{
-						NumReplicas: 2,
+						NumReplicas: 1,
 						Constraints: []zonepb.Constraint{
 							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
 						},
@@ -1131,32 +2264,26 @@ func TestZoneConfigForRegionalByRowPartitionsWithSuperRegions(t *testing.T) {
 							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
 						},
 					},
-					{
-						NumReplicas: 1,
-						Constraints: []zonepb.Constraint{
-							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
-						},
-					},
 				},
 				VoterConstraints: []zonepb.ConstraintsConjunction{
 					{
-						NumReplicas: 2,
+						NumReplicas: 0,
 						Constraints: []zonepb.Constraint{
-							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
 						},
 					},
 				},
 				LeasePreferences: []zonepb.LeasePreference{
 					{
 						Constraints: []zonepb.Constraint{
-							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
 						},
 					},
 				},
 			},
 		},
 		{
-			desc: "super region with regional by row, region failure, 6 regions, super region with 5 regions",
+			desc: "super region with regional by row, zone failure, partition region_b",
 			localityConfig: catpb.LocalityConfig{
 				Locality: &catpb.LocalityConfig_RegionalByRow_{
 					RegionalByRow: &catpb.LocalityConfig_RegionalByRow{},
@@ -1168,17 +2295,15 @@ func TestZoneConfigForRegionalByRowPartitionsWithSuperRegions(t *testing.T) {
 				"region_c",
 				"region_a",
 				"region_d",
-				"region_e",
-				"region_f",
-			}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT, []descpb.SuperRegion{
+			}, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT, []descpb.SuperRegion{
 				{
-					SuperRegionName: "super_region_abcde",
-					Regions:         catpb.RegionNames{"region_a", "region_b", "region_c", "region_d", "region_e"},
+					SuperRegionName: "super_region_ab",
+					Regions:         catpb.RegionNames{"region_a", "region_b"},
 				},
 			}),
 			expected: zonepb.ZoneConfig{
-				NumReplicas:                 proto.Int32(6),
-				NumVoters:                   proto.Int32(5),
+				NumReplicas:                 proto.Int32(4),
+				NumVoters:                   proto.Int32(3),
 				InheritedConstraints:        false,
 				NullVoterConstraintsIsEmpty: true,
 				Constraints: []zonepb.ConstraintsConjunction{
@@ -1194,28 +2319,10 @@ func TestZoneConfigForRegionalByRowPartitionsWithSuperRegions(t *testing.T) {
 							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
 						},
 					},
-					{
-						NumReplicas: 1,
-						Constraints: []zonepb.Constraint{
-							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
-						},
-					},
-					{
-						NumReplicas: 1,
-						Constraints: []zonepb.Constraint{
-							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_d"},
-						},
-					},
-					{
-						NumReplicas: 1,
-						Constraints: []zonepb.Constraint{
-							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_e"},
-						},
-					},
 				},
 				VoterConstraints: []zonepb.ConstraintsConjunction{
 					{
-						NumReplicas: 2,
+						NumReplicas: 0,
 						Constraints: []zonepb.Constraint{
 							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
 						},
@@ -1230,14 +2337,2453 @@ func TestZoneConfigForRegionalByRowPartitionsWithSuperRegions(t *testing.T) {
 				},
 			},
 		},
-	}
-	for _, tc := range testCases {
-		t.Run(tc.desc, func(t *testing.T) {
-			err := multiregion.ValidateRegionConfig(tc.regionConfig)
-			require.NoError(t, err)
-			zc, err := zoneConfigForMultiRegionPartition(tc.region, tc.regionConfig)
-			require.NoError(t, err)
-			require.Equal(t, tc.expected, zc)
+		{
+			desc: "super region with regional by row, region failure, partition region_a",
+			localityConfig: catpb.LocalityConfig{
+				Locality: &catpb.LocalityConfig_RegionalByRow_{
+					RegionalByRow: &catpb.LocalityConfig_RegionalByRow{},
+				},
+			},
+			region: "region_a",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
+				"region_b",
+				"region_c",
+				"region_a",
+				"region_d",
+			}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT, []descpb.SuperRegion{
+				{
+					SuperRegionName: "super_region_abc",
+					Regions:         catpb.RegionNames{"region_a", "region_b", "region_c"},
+				},
+			}),
+			expected: zonepb.ZoneConfig{
+				NumReplicas:                 proto.Int32(5),
+				NumVoters:                   proto.Int32(5),
+				InheritedConstraints:        false,
+				NullVoterConstraintsIsEmpty: true,
+				Constraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+					{
+						NumReplicas: 2,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
+						},
+					},
+				},
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 2,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+				},
+				LeasePreferences: []zonepb.LeasePreference{
+					{
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "super region with regional by row, region failure, partition region_b",
+			localityConfig: catpb.LocalityConfig{
+				Locality: &catpb.LocalityConfig_RegionalByRow_{
+					RegionalByRow: &catpb.LocalityConfig_RegionalByRow{},
+				},
+			},
+			region: "region_b",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
+				"region_b",
+				"region_c",
+				"region_a",
+				"region_d",
+			}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT, []descpb.SuperRegion{
+				{
+					SuperRegionName: "super_region_abc",
+					Regions:         catpb.RegionNames{"region_a", "region_b", "region_c"},
+				},
+			}),
+			expected: zonepb.ZoneConfig{
+				NumReplicas:                 proto.Int32(5),
+				NumVoters:                   proto.Int32(5),
+				InheritedConstraints:        false,
+				NullVoterConstraintsIsEmpty: true,
+				Constraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 2,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
+						},
+					},
+				},
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 2,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+				},
+				LeasePreferences: []zonepb.LeasePreference{
+					{
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "super region with regional by row, region failure, 6 regions, super region with 5 regions",
+			localityConfig: catpb.LocalityConfig{
+				Locality: &catpb.LocalityConfig_RegionalByRow_{
+					RegionalByRow: &catpb.LocalityConfig_RegionalByRow{},
+				},
+			},
+			region: "region_b",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
+				"region_b",
+				"region_c",
+				"region_a",
+				"region_d",
+				"region_e",
+				"region_f",
+			}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, validMultiRegionEnumID, descpb.DataPlacement_DEFAULT, []descpb.SuperRegion{
+				{
+					SuperRegionName: "super_region_abcde",
+					Regions:         catpb.RegionNames{"region_a", "region_b", "region_c", "region_d", "region_e"},
+				},
+			}),
+			expected: zonepb.ZoneConfig{
+				NumReplicas:                 proto.Int32(6),
+				NumVoters:                   proto.Int32(5),
+				InheritedConstraints:        false,
+				NullVoterConstraintsIsEmpty: true,
+				Constraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_d"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_e"},
+						},
+					},
+				},
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 2,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+				},
+				LeasePreferences: []zonepb.LeasePreference{
+					{
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := multiregion.ValidateRegionConfig(tc.regionConfig)
+			require.NoError(t, err)
+			zc, err := zoneConfigForMultiRegionPartition(tc.region, "" /* homeRegion */, tc.regionConfig)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, zc)
+		})
+	}
+}
+
+func TestInferRegionConfig(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	regions := catpb.RegionNames{"region_b", "region_c", "region_a", "region_d"}
+	testCases := []struct {
+		desc         string
+		regionConfig multiregion.RegionConfig
+	}{
+		{
+			desc: "one region, zone survival",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
+				"region_a",
+			}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil),
+		},
+		{
+			desc:         "four regions, zone survival",
+			regionConfig: multiregion.MakeRegionConfig(regions, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil),
+		},
+		{
+			desc:         "four regions, region survival",
+			regionConfig: multiregion.MakeRegionConfig(regions, "region_c", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil),
+		},
+		{
+			desc: "five regions, two region survival",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
+				"region_b", "region_c", "region_a", "region_d", "region_e",
+			}, "region_c", descpb.SurvivalGoal_TWO_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil),
+		},
+		{
+			desc: "one region, restricted placement",
+			regionConfig: multiregion.MakeRegionConfig(catpb.RegionNames{
+				"region_a",
+			}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_RESTRICTED, nil),
+		},
+		{
+			desc: "four regions, primary region only voters",
+			regionConfig: multiregion.MakeRegionConfig(regions, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithPrimaryRegionOnlyVoters()),
+		},
+		{
+			desc: "four regions, weighted voters",
+			regionConfig: multiregion.MakeRegionConfig(regions, "region_a", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithVoterWeights(map[catpb.RegionName]int32{"region_a": 2, "region_b": 2, "region_c": 1})),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			zc, err := zoneConfigForMultiRegionDatabase(tc.regionConfig)
+			require.NoError(t, err)
+			inferred, err := InferRegionConfig(zc)
+			require.NoError(t, err)
+			require.Equal(t, tc.regionConfig, inferred)
+
+			roundTripped, err := zoneConfigForMultiRegionDatabase(inferred)
+			require.NoError(t, err)
+			require.Equal(t, zc, roundTripped)
+		})
+	}
+
+	t.Run("not multi-region", func(t *testing.T) {
+		_, err := InferRegionConfig(*zonepb.NewZoneConfig())
+		require.EqualError(t, err,
+			"expected exactly one lease preference, found 0: zone config is not a multi-region zone config")
+	})
+}
+
+func TestSubzoneSpansForMultiRegionTable(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	regions := catpb.RegionNames{"region_b", "region_c", "region_a", "region_d"}
+	regionConfig := multiregion.MakeRegionConfig(
+		regions, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+	)
+	indexes := []descpb.IndexID{1, 2}
+	subzones, err := subzoneSpansForMultiRegionTable(regionConfig, "" /* homeRegion */, indexes)
+	require.NoError(t, err)
+	require.Len(t, subzones, len(indexes)*len(regions))
+
+	var i int
+	for _, indexID := range indexes {
+		for _, region := range regions {
+			subzone := subzones[i]
+			i++
+			require.Equal(t, uint32(indexID), subzone.IndexID)
+			require.Equal(t, string(region), subzone.PartitionName)
+			require.Equal(t, zonepb.ZoneConfig{
+				NumReplicas:                 nil, // Set at the database level.
+				NumVoters:                   proto.Int32(3),
+				InheritedConstraints:        true,
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					{
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: string(region)},
+						},
+					},
+				},
+				LeasePreferences: []zonepb.LeasePreference{
+					{
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: string(region)},
+						},
+					},
+				},
+			}, subzone.Config)
+		}
+	}
+}
+
+func TestZoneConfigForMultiRegionPartitionWithSurvivalGoalOverrides(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"}
+	leasePreferences := func(region catpb.RegionName) []zonepb.LeasePreference {
+		return []zonepb.LeasePreference{
+			{
+				Constraints: []zonepb.Constraint{
+					{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: string(region)},
+				},
+			},
+		}
+	}
+	zoneSurvivalPartition := func(region catpb.RegionName) zonepb.ZoneConfig {
+		return zonepb.ZoneConfig{
+			NumReplicas:                 proto.Int32(6),
+			NumVoters:                   proto.Int32(3),
+			InheritedConstraints:        true,
+			NullVoterConstraintsIsEmpty: true,
+			VoterConstraints: []zonepb.ConstraintsConjunction{
+				{
+					Constraints: []zonepb.Constraint{
+						{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: string(region)},
+					},
+				},
+			},
+			LeasePreferences: leasePreferences(region),
+		}
+	}
+	regionSurvivalPartition := func(region catpb.RegionName, numReplicas *int32) zonepb.ZoneConfig {
+		return zonepb.ZoneConfig{
+			NumReplicas:                 numReplicas,
+			NumVoters:                   proto.Int32(5),
+			InheritedConstraints:        true,
+			NullVoterConstraintsIsEmpty: true,
+			VoterConstraints: []zonepb.ConstraintsConjunction{
+				{
+					NumReplicas: 2,
+					Constraints: []zonepb.Constraint{
+						{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: string(region)},
+					},
+				},
+			},
+			LeasePreferences: leasePreferences(region),
+		}
+	}
+
+	t.Run("region survival database with zone survival partitions", func(t *testing.T) {
+		regionConfig := multiregion.MakeRegionConfig(
+			regions, "region_a", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+			multiregion.WithPartitionSurvivalGoals(map[catpb.RegionName]descpb.SurvivalGoal{
+				"region_b": descpb.SurvivalGoal_ZONE_FAILURE,
+				"region_c": descpb.SurvivalGoal_ZONE_FAILURE,
+			}),
+		)
+		expected := map[catpb.RegionName]zonepb.ZoneConfig{
+			"region_a": regionSurvivalPartition("region_a", nil /* numReplicas */),
+			"region_b": zoneSurvivalPartition("region_b"),
+			"region_c": zoneSurvivalPartition("region_c"),
+			"region_d": regionSurvivalPartition("region_d", nil /* numReplicas */),
+		}
+		for _, region := range regions {
+			zc, err := zoneConfigForMultiRegionPartition(region, "" /* homeRegion */, regionConfig)
+			require.NoError(t, err)
+			require.Equal(t, expected[region], zc)
+		}
+	})
+
+	t.Run("zone survival database with a region survival partition", func(t *testing.T) {
+		regionConfig := multiregion.MakeRegionConfig(
+			regions, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+			multiregion.WithPartitionSurvivalGoals(map[catpb.RegionName]descpb.SurvivalGoal{
+				"region_d": descpb.SurvivalGoal_REGION_FAILURE,
+			}),
+		)
+		zc, err := zoneConfigForMultiRegionPartition("region_d", "" /* homeRegion */, regionConfig)
+		require.NoError(t, err)
+		require.Equal(t, regionSurvivalPartition("region_d", proto.Int32(5)), zc)
+
+		zc, err = zoneConfigForMultiRegionPartition("region_b", "" /* homeRegion */, regionConfig)
+		require.NoError(t, err)
+		expected := zoneSurvivalPartition("region_b")
+		expected.NumReplicas = nil // Set at the database level.
+		require.Equal(t, expected, zc)
+	})
+
+	t.Run("not enough regions for a region survival partition", func(t *testing.T) {
+		regionConfig := multiregion.MakeRegionConfig(
+			catpb.RegionNames{"region_a", "region_b"}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE,
+			descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+			multiregion.WithPartitionSurvivalGoals(map[catpb.RegionName]descpb.SurvivalGoal{
+				"region_b": descpb.SurvivalGoal_REGION_FAILURE,
+			}),
+		)
+		_, err := zoneConfigForMultiRegionPartition("region_b", "" /* homeRegion */, regionConfig)
+		require.EqualError(t, err, "at least 3 regions are required for surviving a region failure")
+		require.EqualError(t, multiregion.ValidatePartitionSurvivalGoals(regionConfig),
+			"partition region_b: at least 3 regions are required for surviving a region failure")
+	})
+}
+
+func TestEstimateLeaseMigration(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"}
+	makeConfig := func(primary catpb.RegionName) multiregion.RegionConfig {
+		return multiregion.MakeRegionConfig(
+			regions, primary, descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+		)
+	}
+
+	t.Run("primary region swap", func(t *testing.T) {
+		estimate, err := EstimateLeaseMigration(makeConfig("region_b"), makeConfig("region_c"))
+		require.NoError(t, err)
+		require.Equal(t, LeaseMigrationEstimate{
+			NumChangedLeasePreferences: 1,
+			GainedRegions:              catpb.RegionNames{"region_c"},
+			LostRegions:                catpb.RegionNames{"region_b"},
+		}, estimate)
+	})
+
+	t.Run("unchanged primary region", func(t *testing.T) {
+		estimate, err := EstimateLeaseMigration(makeConfig("region_b"), makeConfig("region_b"))
+		require.NoError(t, err)
+		require.Equal(t, LeaseMigrationEstimate{}, estimate)
+	})
+}
+
+func TestPreviewAddRegion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	regionConstraint := func(region string, numReplicas int32) zonepb.ConstraintsConjunction {
+		return zonepb.ConstraintsConjunction{
+			NumReplicas: numReplicas,
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: region},
+			},
+		}
+	}
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c"}
+	cfg := multiregion.MakeRegionConfig(
+		regions, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+	)
+
+	before, after, err := PreviewAddRegion(cfg, "region_d")
+	require.NoError(t, err)
+	require.Equal(t, proto.Int32(5), before.NumReplicas)
+	require.Equal(t, []zonepb.ConstraintsConjunction{
+		regionConstraint("region_a", 1),
+		regionConstraint("region_b", 1),
+		regionConstraint("region_c", 1),
+	}, before.Constraints)
+	require.Equal(t, proto.Int32(6), after.NumReplicas)
+	require.Equal(t, []zonepb.ConstraintsConjunction{
+		regionConstraint("region_a", 1),
+		regionConstraint("region_b", 1),
+		regionConstraint("region_c", 1),
+		regionConstraint("region_d", 1),
+	}, after.Constraints)
+	// The voters and leases remain in the primary region.
+	require.Equal(t, before.NumVoters, after.NumVoters)
+	require.Equal(t, before.VoterConstraints, after.VoterConstraints)
+	require.Equal(t, before.LeasePreferences, after.LeasePreferences)
+	// The previewed config is left unchanged.
+	require.Equal(t, regions, cfg.Regions())
+
+	_, _, err = PreviewAddRegion(cfg, "region_c")
+	require.EqualError(t, err, `region "region_c" already added to database`)
+}
+
+func TestSurvivalGoalChangeDelta(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	regionConstraint := func(region string, numReplicas int32) zonepb.ConstraintsConjunction {
+		return zonepb.ConstraintsConjunction{
+			NumReplicas: numReplicas,
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: region},
+			},
+		}
+	}
+	makeConfig := func(regions catpb.RegionNames) multiregion.RegionConfig {
+		return multiregion.MakeRegionConfig(
+			regions, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+		)
+	}
+
+	t.Run("zone to region survival", func(t *testing.T) {
+		cfg := makeConfig(catpb.RegionNames{"region_a", "region_b", "region_c"})
+		before, after, summary, err := SurvivalGoalChangeDelta(cfg, descpb.SurvivalGoal_REGION_FAILURE)
+		require.NoError(t, err)
+
+		expectedBefore, err := zoneConfigForMultiRegionDatabase(cfg)
+		require.NoError(t, err)
+		require.Equal(t, expectedBefore, before)
+		require.Equal(t, proto.Int32(5), after.NumVoters)
+		require.Equal(t, []zonepb.ConstraintsConjunction{regionConstraint("region_b", 2)}, after.VoterConstraints)
+
+		// The voters are spread across the regions, but every region already
+		// holds a replica, so the replica constraints are left unchanged.
+		require.Equal(t, SurvivalGoalChangeSummary{
+			OldNumVoters:            3,
+			NewNumVoters:            5,
+			OldNumReplicas:          5,
+			NewNumReplicas:          5,
+			AddedVoterConstraints:   []zonepb.ConstraintsConjunction{regionConstraint("region_b", 2)},
+			RemovedVoterConstraints: []zonepb.ConstraintsConjunction{regionConstraint("region_b", 0)},
+		}, summary)
+	})
+
+	t.Run("too few regions", func(t *testing.T) {
+		cfg := makeConfig(catpb.RegionNames{"region_a", "region_b"})
+		_, _, _, err := SurvivalGoalChangeDelta(cfg, descpb.SurvivalGoal_REGION_FAILURE)
+		require.Error(t, err)
+		require.Equal(t, pgcode.InvalidParameterValue, pgerror.GetPGCode(err))
+	})
+}
+
+func TestSurvivalCostComparison(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		desc    string
+		regions catpb.RegionNames
+		zone    SurvivalCost
+		region  SurvivalCost
+		errCode pgcode.Code
+	}{
+		{
+			// Zone survival: 3 voters in the primary region and a non-voter in
+			// each of the other two. Region survival: 5 voters, 2 of them in the
+			// primary region.
+			desc:    "three regions",
+			regions: catpb.RegionNames{"region_a", "region_b", "region_c"},
+			zone:    SurvivalCost{NumReplicas: 5, NumVoters: 3},
+			region:  SurvivalCost{NumReplicas: 5, NumVoters: 5},
+		},
+		{
+			// Surviving region failures requires no more replicas than surviving
+			// zone failures once there are four regions.
+			desc:    "four regions",
+			regions: catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"},
+			zone:    SurvivalCost{NumReplicas: 6, NumVoters: 3},
+			region:  SurvivalCost{NumReplicas: 5, NumVoters: 5},
+		},
+		{
+			desc:    "too few regions to survive a region failure",
+			regions: catpb.RegionNames{"region_a", "region_b"},
+			errCode: pgcode.InvalidParameterValue,
+		},
+		{
+			desc:    "no regions",
+			errCode: pgcode.InvalidParameterValue,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			zone, region, err := SurvivalCostComparison(tc.regions)
+			if tc.errCode != (pgcode.Code{}) {
+				require.Error(t, err)
+				require.Equal(t, tc.errCode, pgerror.GetPGCode(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.zone, zone)
+			require.Equal(t, tc.region, region)
+		})
+	}
+}
+
+func TestUnderReplicatedAfterDrop(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c"}
+	testCases := []struct {
+		desc         string
+		regionConfig multiregion.RegionConfig
+		region       catpb.RegionName
+		expected     []string
+		errCode      pgcode.Code
+	}{
+		{
+			// The voters pinned to the dropped region as well as its replica
+			// are lost.
+			desc: "voter region under region survival",
+			regionConfig: multiregion.MakeRegionConfig(
+				regions,
+				"region_a",
+				descpb.SurvivalGoal_REGION_FAILURE,
+				descpb.InvalidID,
+				descpb.DataPlacement_DEFAULT,
+				nil,
+				multiregion.WithVoterWeights(map[catpb.RegionName]int32{
+					"region_a": 2,
+					"region_b": 2,
+					"region_c": 1,
+				}),
+			),
+			region: "region_b",
+			expected: []string{
+				"database: voter constraint +region=region_b:2",
+				"database: constraint +region=region_b:1",
+			},
+		},
+		{
+			// All voters are in the primary region, so only the non-voter of the
+			// dropped region is lost.
+			desc: "non-voter region under zone survival",
+			regionConfig: multiregion.MakeRegionConfig(
+				regions,
+				"region_a",
+				descpb.SurvivalGoal_ZONE_FAILURE,
+				descpb.InvalidID,
+				descpb.DataPlacement_DEFAULT,
+				nil,
+			),
+			region: "region_c",
+			expected: []string{
+				"database: constraint +region=region_c:1",
+			},
+		},
+		{
+			desc: "primary region",
+			regionConfig: multiregion.MakeRegionConfig(
+				regions,
+				"region_a",
+				descpb.SurvivalGoal_ZONE_FAILURE,
+				descpb.InvalidID,
+				descpb.DataPlacement_DEFAULT,
+				nil,
+			),
+			region:  "region_a",
+			errCode: pgcode.InvalidDatabaseDefinition,
+		},
+		{
+			desc: "unknown region",
+			regionConfig: multiregion.MakeRegionConfig(
+				regions,
+				"region_a",
+				descpb.SurvivalGoal_ZONE_FAILURE,
+				descpb.InvalidID,
+				descpb.DataPlacement_DEFAULT,
+				nil,
+			),
+			region:  "region_d",
+			errCode: pgcode.UndefinedObject,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			res, err := UnderReplicatedAfterDrop(tc.regionConfig, tc.region)
+			if tc.errCode != (pgcode.Code{}) {
+				require.Error(t, err)
+				require.Equal(t, tc.errCode, pgerror.GetPGCode(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestZoneConfigForIndexPartition(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c"}
+	for _, survivalGoal := range []descpb.SurvivalGoal{
+		descpb.SurvivalGoal_ZONE_FAILURE,
+		descpb.SurvivalGoal_REGION_FAILURE,
+	} {
+		regionConfig := multiregion.MakeRegionConfig(
+			regions, "region_a", survivalGoal, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+		)
+		for _, region := range regions {
+			t.Run(fmt.Sprintf("%s/%s", survivalGoal, region), func(t *testing.T) {
+				partitionZoneConfig, err := zoneConfigForMultiRegionPartition(region, "" /* homeRegion */, regionConfig)
+				require.NoError(t, err)
+
+				subzone, err := zoneConfigForIndexPartition(region, "" /* homeRegion */, descpb.IndexID(2), regionConfig)
+				require.NoError(t, err)
+				require.Equal(t, zonepb.Subzone{
+					IndexID:       2,
+					PartitionName: string(region),
+					Config:        partitionZoneConfig,
+				}, subzone)
+			})
+		}
+	}
+
+	t.Run("unsatisfiable survival goal", func(t *testing.T) {
+		regionConfig := multiregion.MakeRegionConfig(
+			regions[:2], "region_a", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+		)
+		_, err := zoneConfigForIndexPartition("region_a", "" /* homeRegion */, descpb.IndexID(1), regionConfig)
+		require.EqualError(t, err, "at least 3 regions are required for surviving a region failure")
+	})
+}
+
+func TestZoneConfigForMultiRegionDatabaseWithExtraPrimaryRegionNonVoters(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	regionConfig := multiregion.MakeRegionConfig(catpb.RegionNames{
+		"region_b",
+		"region_c",
+		"region_a",
+	}, "region_b", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+		multiregion.WithExtraPrimaryRegionNonVoters(2))
+	expected := zonepb.ZoneConfig{
+		NumReplicas: proto.Int32(7),
+		NumVoters:   proto.Int32(5),
+		LeasePreferences: []zonepb.LeasePreference{
+			{
+				Constraints: []zonepb.Constraint{
+					{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"}},
+			},
+		},
+		Constraints: []zonepb.ConstraintsConjunction{
+			{
+				// The 2 voters of the primary region and the 2 extra non-voters.
+				NumReplicas: 4,
+				Constraints: []zonepb.Constraint{
+					{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+				},
+			},
+			{
+				NumReplicas: 1,
+				Constraints: []zonepb.Constraint{
+					{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
+				},
+			},
+			{
+				NumReplicas: 1,
+				Constraints: []zonepb.Constraint{
+					{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+				},
+			},
+		},
+		NullVoterConstraintsIsEmpty: true,
+		VoterConstraints: []zonepb.ConstraintsConjunction{
+			{
+				NumReplicas: 2,
+				Constraints: []zonepb.Constraint{
+					{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+				},
+			},
+		},
+	}
+	zc, err := zoneConfigForMultiRegionDatabase(regionConfig)
+	require.NoError(t, err)
+	require.Equal(t, expected, zc)
+}
+
+func TestZoneConfigForMultiRegionDatabaseWithNonVotingRegions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c", "region_d", "region_e"}
+	regionConstraint := func(region catpb.RegionName, numReplicas int32) zonepb.ConstraintsConjunction {
+		return zonepb.ConstraintsConjunction{
+			NumReplicas: numReplicas,
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: string(region)},
+			},
+		}
+	}
+	leasePreferences := []zonepb.LeasePreference{
+		{
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"}},
+		},
+	}
+	replicaConstraints := []zonepb.ConstraintsConjunction{
+		regionConstraint("region_a", 1),
+		regionConstraint("region_b", 1),
+		regionConstraint("region_c", 1),
+		regionConstraint("region_d", 1),
+		regionConstraint("region_e", 1),
+	}
+
+	testCases := []struct {
+		desc         string
+		regionConfig multiregion.RegionConfig
+		expected     zonepb.ZoneConfig
+	}{
+		{
+			desc: "region survival, two observer regions",
+			regionConfig: multiregion.MakeRegionConfig(
+				regions, "region_a", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithNonVotingRegions(catpb.RegionNames{"region_d", "region_e"}),
+			),
+			expected: zonepb.ZoneConfig{
+				// 2 voters in region_a and region_b, 1 voter in region_c and 1
+				// non-voter in each observer region.
+				NumReplicas:                 proto.Int32(7),
+				NumVoters:                   proto.Int32(5),
+				LeasePreferences:            leasePreferences,
+				Constraints:                 replicaConstraints,
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					regionConstraint("region_a", 2),
+					regionConstraint("region_b", 2),
+					regionConstraint("region_c", 1),
+				},
+			},
+		},
+		{
+			desc: "zone survival, two observer regions",
+			regionConfig: multiregion.MakeRegionConfig(
+				regions, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithNonVotingRegions(catpb.RegionNames{"region_d", "region_e"}),
+			),
+			expected: zonepb.ZoneConfig{
+				NumReplicas:                 proto.Int32(7),
+				NumVoters:                   proto.Int32(3),
+				LeasePreferences:            leasePreferences,
+				Constraints:                 replicaConstraints,
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					regionConstraint("region_a", 0),
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			zc, err := zoneConfigForMultiRegionDatabase(tc.regionConfig)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, zc)
+		})
+	}
+
+	t.Run("insufficient voting regions", func(t *testing.T) {
+		regionConfig := multiregion.MakeRegionConfig(
+			regions[:4], "region_a", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+			multiregion.WithNonVotingRegions(catpb.RegionNames{"region_c", "region_d"}),
+		)
+		_, err := zoneConfigForMultiRegionDatabase(regionConfig)
+		require.EqualError(t, err,
+			"insufficient voting regions: at least 3 regions are required for surviving a region failure")
+	})
+}
+
+func TestZoneConfigForMultiRegionDatabaseWithVoterRegions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	regionConstraint := func(region string, numReplicas int32) zonepb.ConstraintsConjunction {
+		return zonepb.ConstraintsConjunction{
+			NumReplicas: numReplicas,
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: region},
+			},
+		}
+	}
+	leasePreferences := func(region string) []zonepb.LeasePreference {
+		return []zonepb.LeasePreference{
+			{Constraints: []zonepb.Constraint{{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: region}}},
+		}
+	}
+
+	testCases := []struct {
+		desc         string
+		regionConfig multiregion.RegionConfig
+		expected     zonepb.ZoneConfig
+	}{
+		{
+			desc: "zone survival, voters spread beyond the primary region",
+			regionConfig: multiregion.MakeRegionConfig(
+				catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"},
+				"region_a",
+				descpb.SurvivalGoal_ZONE_FAILURE,
+				descpb.InvalidID,
+				descpb.DataPlacement_DEFAULT,
+				nil,
+				multiregion.WithVoterRegions(catpb.RegionNames{"region_c", "region_a", "region_d"}),
+			),
+			expected: zonepb.ZoneConfig{
+				NumReplicas:      proto.Int32(6),
+				NumVoters:        proto.Int32(3),
+				LeasePreferences: leasePreferences("region_a"),
+				Constraints: []zonepb.ConstraintsConjunction{
+					regionConstraint("region_a", 1),
+					regionConstraint("region_b", 1),
+					regionConstraint("region_c", 1),
+					regionConstraint("region_d", 1),
+				},
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					regionConstraint("region_a", 1),
+					regionConstraint("region_c", 1),
+					regionConstraint("region_d", 1),
+				},
+			},
+		},
+		{
+			desc: "region survival, voters in a subset of the regions",
+			regionConfig: multiregion.MakeRegionConfig(
+				catpb.RegionNames{"region_a", "region_b", "region_c", "region_d", "region_e"},
+				"region_b",
+				descpb.SurvivalGoal_REGION_FAILURE,
+				descpb.InvalidID,
+				descpb.DataPlacement_DEFAULT,
+				nil,
+				multiregion.WithVoterRegions(catpb.RegionNames{"region_b", "region_d", "region_e"}),
+			),
+			expected: zonepb.ZoneConfig{
+				// The 2 voters of region_b and region_d take an extra replica
+				// each on top of the replica of every region.
+				NumReplicas:      proto.Int32(7),
+				NumVoters:        proto.Int32(5),
+				LeasePreferences: leasePreferences("region_b"),
+				Constraints: []zonepb.ConstraintsConjunction{
+					regionConstraint("region_a", 1),
+					regionConstraint("region_b", 1),
+					regionConstraint("region_c", 1),
+					regionConstraint("region_d", 1),
+					regionConstraint("region_e", 1),
+				},
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					regionConstraint("region_b", 2),
+					regionConstraint("region_d", 2),
+					regionConstraint("region_e", 1),
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			zc, err := zoneConfigForMultiRegionDatabase(tc.regionConfig)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, zc)
+			// The lease preference stays in the primary region while the voters
+			// are constrained to other regions as well.
+			require.NotEqual(t, zc.LeasePreferences[0].Constraints, zc.VoterConstraints[len(zc.VoterConstraints)-1].Constraints)
+		})
+	}
+}
+
+func TestZoneConfigForMultiRegionDatabaseWithRegionPlacements(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	regionConstraint := func(region string, numReplicas int32) zonepb.ConstraintsConjunction {
+		return zonepb.ConstraintsConjunction{
+			NumReplicas: numReplicas,
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: region},
+			},
+		}
+	}
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"}
+	leasePreferences := []zonepb.LeasePreference{
+		{Constraints: []zonepb.Constraint{{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"}}},
+	}
+
+	testCases := []struct {
+		desc         string
+		regionConfig multiregion.RegionConfig
+		expected     zonepb.ZoneConfig
+	}{
+		{
+			desc: "zone survival, two restricted regions",
+			regionConfig: multiregion.MakeRegionConfig(
+				regions, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithRegionPlacements(map[catpb.RegionName]descpb.DataPlacement{
+					"region_c": descpb.DataPlacement_RESTRICTED,
+					"region_d": descpb.DataPlacement_RESTRICTED,
+				}),
+			),
+			expected: zonepb.ZoneConfig{
+				// 3 voters in region_a and a non-voter in region_b only.
+				NumReplicas:      proto.Int32(4),
+				NumVoters:        proto.Int32(3),
+				LeasePreferences: leasePreferences,
+				Constraints: []zonepb.ConstraintsConjunction{
+					regionConstraint("region_a", 1),
+					regionConstraint("region_b", 1),
+				},
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					regionConstraint("region_a", 0),
+				},
+			},
+		},
+		{
+			desc: "zone survival, restricted primary region",
+			regionConfig: multiregion.MakeRegionConfig(
+				regions, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithRegionPlacements(map[catpb.RegionName]descpb.DataPlacement{
+					"region_a": descpb.DataPlacement_RESTRICTED,
+				}),
+			),
+			expected: zonepb.ZoneConfig{
+				// The primary region only holds voters, so restricting it has no
+				// effect.
+				NumReplicas:      proto.Int32(6),
+				NumVoters:        proto.Int32(3),
+				LeasePreferences: leasePreferences,
+				Constraints: []zonepb.ConstraintsConjunction{
+					regionConstraint("region_a", 1),
+					regionConstraint("region_b", 1),
+					regionConstraint("region_c", 1),
+					regionConstraint("region_d", 1),
+				},
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					regionConstraint("region_a", 0),
+				},
+			},
+		},
+		{
+			desc: "region survival, one restricted region",
+			regionConfig: multiregion.MakeRegionConfig(
+				regions, "region_a", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithRegionPlacements(map[catpb.RegionName]descpb.DataPlacement{
+					"region_b": descpb.DataPlacement_DEFAULT,
+					"region_d": descpb.DataPlacement_RESTRICTED,
+				}),
+			),
+			expected: zonepb.ZoneConfig{
+				NumReplicas:      proto.Int32(5),
+				NumVoters:        proto.Int32(5),
+				LeasePreferences: leasePreferences,
+				Constraints: []zonepb.ConstraintsConjunction{
+					regionConstraint("region_a", 1),
+					regionConstraint("region_b", 1),
+					regionConstraint("region_c", 1),
+				},
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					regionConstraint("region_a", 2),
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			zc, err := zoneConfigForMultiRegionDatabase(tc.regionConfig)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, zc)
+		})
+	}
+}
+
+func TestZoneConfigForMultiRegionDatabaseWithTieBreakerVoter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	regionConstraint := func(region string, numReplicas int32) zonepb.ConstraintsConjunction {
+		return zonepb.ConstraintsConjunction{
+			NumReplicas: numReplicas,
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: region},
+			},
+		}
+	}
+	tieBreakerConstraint := zonepb.ConstraintsConjunction{
+		NumReplicas: 1,
+		Constraints: []zonepb.Constraint{
+			{Type: zonepb.Constraint_REQUIRED, Key: "cloud", Value: "gcp"},
+		},
+	}
+	leasePreferences := []zonepb.LeasePreference{
+		{
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc         string
+		regionConfig multiregion.RegionConfig
+		expected     zonepb.ZoneConfig
+	}{
+		{
+			desc: "two regions, zone survival",
+			regionConfig: multiregion.MakeRegionConfig(
+				catpb.RegionNames{"region_a", "region_b"},
+				"region_a",
+				descpb.SurvivalGoal_ZONE_FAILURE,
+				descpb.InvalidID,
+				descpb.DataPlacement_DEFAULT,
+				nil,
+				multiregion.WithTieBreakerVoter("cloud", "gcp"),
+			),
+			// One of the voters pinned to the primary region is moved to the
+			// tie-breaker locality.
+			expected: zonepb.ZoneConfig{
+				NumReplicas:      proto.Int32(4),
+				NumVoters:        proto.Int32(3),
+				LeasePreferences: leasePreferences,
+				Constraints: []zonepb.ConstraintsConjunction{
+					regionConstraint("region_a", 1),
+					regionConstraint("region_b", 1),
+				},
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					regionConstraint("region_a", 2),
+					tieBreakerConstraint,
+				},
+			},
+		},
+		{
+			desc: "three regions, region survival",
+			regionConfig: multiregion.MakeRegionConfig(
+				catpb.RegionNames{"region_a", "region_b", "region_c"},
+				"region_a",
+				descpb.SurvivalGoal_REGION_FAILURE,
+				descpb.InvalidID,
+				descpb.DataPlacement_DEFAULT,
+				nil,
+				multiregion.WithTieBreakerVoter("cloud", "gcp"),
+			),
+			// One fewer voter floats across the regions.
+			expected: zonepb.ZoneConfig{
+				NumReplicas:      proto.Int32(5),
+				NumVoters:        proto.Int32(5),
+				LeasePreferences: leasePreferences,
+				Constraints: []zonepb.ConstraintsConjunction{
+					regionConstraint("region_a", 1),
+					regionConstraint("region_b", 1),
+					regionConstraint("region_c", 1),
+				},
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					regionConstraint("region_a", 2),
+					tieBreakerConstraint,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			zc, err := zoneConfigForMultiRegionDatabase(tc.regionConfig)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, zc)
+			require.NoError(t, zc.Validate())
+		})
+	}
+}
+
+func TestZoneConfigForMultiRegionDatabaseWithConstraintKeys(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	conjunction := func(key, value string, numReplicas int32) zonepb.ConstraintsConjunction {
+		return zonepb.ConstraintsConjunction{
+			NumReplicas: numReplicas,
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: key, Value: value},
+			},
+		}
+	}
+	leasePreferences := func(key string) []zonepb.LeasePreference {
+		return []zonepb.LeasePreference{
+			{
+				Constraints: []zonepb.Constraint{
+					{Type: zonepb.Constraint_REQUIRED, Key: key, Value: "region_a"},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		desc         string
+		regionConfig multiregion.RegionConfig
+		expected     zonepb.ZoneConfig
+	}{
+		{
+			desc: "zone voter key, region replica key, zone survival",
+			regionConfig: multiregion.MakeRegionConfig(
+				catpb.RegionNames{"region_a", "region_b", "region_c"},
+				"region_a",
+				descpb.SurvivalGoal_ZONE_FAILURE,
+				descpb.InvalidID,
+				descpb.DataPlacement_DEFAULT,
+				nil,
+				multiregion.WithConstraintKey(multiregion.VoterConstraintKind, "zone"),
+				multiregion.WithConstraintKey(multiregion.ReplicaConstraintKind, "region"),
+			),
+			expected: zonepb.ZoneConfig{
+				NumReplicas:      proto.Int32(5),
+				NumVoters:        proto.Int32(3),
+				LeasePreferences: leasePreferences("region"),
+				Constraints: []zonepb.ConstraintsConjunction{
+					conjunction("region", "region_a", 1),
+					conjunction("region", "region_b", 1),
+					conjunction("region", "region_c", 1),
+				},
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					conjunction("zone", "region_a", 0),
+				},
+			},
+		},
+		{
+			desc: "zone voter key, region replica key, zone lease key, region survival",
+			regionConfig: multiregion.MakeRegionConfig(
+				catpb.RegionNames{"region_a", "region_b", "region_c"},
+				"region_a",
+				descpb.SurvivalGoal_REGION_FAILURE,
+				descpb.InvalidID,
+				descpb.DataPlacement_DEFAULT,
+				nil,
+				multiregion.WithConstraintKey(multiregion.VoterConstraintKind, "zone"),
+				multiregion.WithConstraintKey(multiregion.LeaseConstraintKind, "zone"),
+			),
+			expected: zonepb.ZoneConfig{
+				NumReplicas:      proto.Int32(5),
+				NumVoters:        proto.Int32(5),
+				LeasePreferences: leasePreferences("zone"),
+				Constraints: []zonepb.ConstraintsConjunction{
+					conjunction("region", "region_a", 1),
+					conjunction("region", "region_b", 1),
+					conjunction("region", "region_c", 1),
+				},
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					conjunction("zone", "region_a", 2),
+				},
+			},
+		},
+		{
+			desc: "zone voter key with a tie-breaker voter",
+			regionConfig: multiregion.MakeRegionConfig(
+				catpb.RegionNames{"region_a", "region_b"},
+				"region_a",
+				descpb.SurvivalGoal_ZONE_FAILURE,
+				descpb.InvalidID,
+				descpb.DataPlacement_DEFAULT,
+				nil,
+				multiregion.WithTieBreakerVoter("cloud", "gcp"),
+				multiregion.WithConstraintKey(multiregion.VoterConstraintKind, "zone"),
+			),
+			// The tie-breaker voter keeps its own locality tier.
+			expected: zonepb.ZoneConfig{
+				NumReplicas:      proto.Int32(4),
+				NumVoters:        proto.Int32(3),
+				LeasePreferences: leasePreferences("region"),
+				Constraints: []zonepb.ConstraintsConjunction{
+					conjunction("region", "region_a", 1),
+					conjunction("region", "region_b", 1),
+				},
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					conjunction("zone", "region_a", 2),
+					conjunction("cloud", "gcp", 1),
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			zc, err := zoneConfigForMultiRegionDatabase(tc.regionConfig)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, zc)
+			require.NoError(t, zc.Validate())
+		})
+	}
+}
+
+func TestZoneConfigForMultiRegionDatabaseWithProhibitedRegions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const regionEnumID = 100
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"}
+	regionConstraints := func(numReplicas ...int32) []zonepb.ConstraintsConjunction {
+		ret := make([]zonepb.ConstraintsConjunction, len(numReplicas))
+		for i, n := range numReplicas {
+			ret[i] = zonepb.ConstraintsConjunction{
+				NumReplicas: n,
+				Constraints: []zonepb.Constraint{
+					{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: string(regions[i])},
+				},
+			}
+		}
+		return ret
+	}
+	leasePreferences := []zonepb.LeasePreference{
+		{
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc         string
+		regionConfig multiregion.RegionConfig
+		expected     zonepb.ZoneConfig
+	}{
+		{
+			desc: "zone survival",
+			regionConfig: multiregion.MakeRegionConfig(
+				regions, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, regionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithProhibitedRegions(catpb.RegionNames{"region_d"}),
+			),
+			// The prohibited region does not count towards the replicas, and the
+			// voters make up the remaining replicas of the primary region.
+			expected: zonepb.ZoneConfig{
+				NumReplicas:                 proto.Int32(5),
+				NumVoters:                   proto.Int32(3),
+				LeasePreferences:            leasePreferences,
+				Constraints:                 regionConstraints(3, 1, 1),
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					{
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "region survival",
+			regionConfig: multiregion.MakeRegionConfig(
+				regions, "region_a", descpb.SurvivalGoal_REGION_FAILURE, regionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithProhibitedRegions(catpb.RegionNames{"region_d"}),
+			),
+			// The replicas beyond the voters of the primary region are spread
+			// across the other regions.
+			expected: zonepb.ZoneConfig{
+				NumReplicas:                 proto.Int32(5),
+				NumVoters:                   proto.Int32(5),
+				LeasePreferences:            leasePreferences,
+				Constraints:                 regionConstraints(2, 2, 1),
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 2,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.NoError(t, multiregion.ValidateRegionConfig(tc.regionConfig))
+			zc, err := zoneConfigForMultiRegionDatabase(tc.regionConfig)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, zc)
+			require.NoError(t, zc.Validate())
+
+			// Every region which is not prohibited holds a replica, and every
+			// replica is pinned to such a region.
+			replicasPerRegion := make(map[string]int32)
+			var numConstrained int32
+			for _, c := range zc.Constraints {
+				require.Len(t, c.Constraints, 1)
+				require.Equal(t, zonepb.Constraint_REQUIRED, c.Constraints[0].Type)
+				replicasPerRegion[c.Constraints[0].Value] += c.NumReplicas
+				numConstrained += c.NumReplicas
+			}
+			for _, region := range regions {
+				if tc.regionConfig.IsProhibitedRegion(region) {
+					require.Zero(t, replicasPerRegion[string(region)], "region %s", region)
+				} else {
+					require.Positive(t, replicasPerRegion[string(region)], "region %s", region)
+				}
+			}
+			require.Equal(t, *zc.NumReplicas, numConstrained)
+		})
+	}
+}
+
+func TestZoneConfigForMultiRegionDatabaseWithAvailabilityZones(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const regionEnumID = 100
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c"}
+	constraints := []zonepb.ConstraintsConjunction{
+		{
+			NumReplicas: 1,
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+			},
+		},
+		{
+			NumReplicas: 1,
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+			},
+		},
+		{
+			NumReplicas: 1,
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
+			},
+		},
+	}
+	leasePreferences := []zonepb.LeasePreference{
+		{
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc         string
+		regionConfig multiregion.RegionConfig
+		expected     zonepb.ZoneConfig
+	}{
+		{
+			desc: "primary region with 2 zones",
+			regionConfig: multiregion.MakeRegionConfig(
+				regions, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, regionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithAvailabilityZones(map[catpb.RegionName]int32{"region_a": 2}),
+			),
+			// The primary region holds a voter in each of its zones, and the
+			// third voter is spread to the next region.
+			expected: zonepb.ZoneConfig{
+				NumReplicas:                 proto.Int32(5),
+				NumVoters:                   proto.Int32(3),
+				LeasePreferences:            leasePreferences,
+				Constraints:                 constraints,
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 2,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "primary region with 2 zones and a secondary region",
+			regionConfig: multiregion.MakeRegionConfig(
+				regions, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, regionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithAvailabilityZones(map[catpb.RegionName]int32{"region_a": 2}),
+				multiregion.WithSecondaryRegion("region_c"),
+			),
+			expected: zonepb.ZoneConfig{
+				NumReplicas:                 proto.Int32(5),
+				NumVoters:                   proto.Int32(3),
+				LeasePreferences:            leasePreferences,
+				Constraints:                 constraints,
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					{
+						NumReplicas: 2,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+					{
+						NumReplicas: 1,
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_c"},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "primary region with 3 zones",
+			regionConfig: multiregion.MakeRegionConfig(
+				regions, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, regionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithAvailabilityZones(map[catpb.RegionName]int32{"region_a": 3, "region_b": 2}),
+			),
+			expected: zonepb.ZoneConfig{
+				NumReplicas:                 proto.Int32(5),
+				NumVoters:                   proto.Int32(3),
+				LeasePreferences:            leasePreferences,
+				Constraints:                 constraints,
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					{
+						Constraints: []zonepb.Constraint{
+							{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.NoError(t, multiregion.ValidateRegionConfig(tc.regionConfig))
+			zc, err := zoneConfigForMultiRegionDatabase(tc.regionConfig)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, zc)
+			require.NoError(t, zc.Validate())
+			require.NoError(t, ValidateAvailabilityZonePlacement(tc.regionConfig))
+		})
+	}
+
+	t.Run("unsatisfiable", func(t *testing.T) {
+		// Each region has a single zone, so one of them has to hold two of the
+		// three voters.
+		regionConfig := multiregion.MakeRegionConfig(
+			catpb.RegionNames{"region_a", "region_b"}, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, regionEnumID, descpb.DataPlacement_DEFAULT, nil,
+			multiregion.WithAvailabilityZones(map[catpb.RegionName]int32{"region_a": 1, "region_b": 1}),
+		)
+		require.NoError(t, multiregion.ValidateRegionConfig(regionConfig))
+		require.EqualError(
+			t,
+			ValidateAvailabilityZonePlacement(regionConfig),
+			"not enough availability zones to survive a zone failure in regions: region_a (2 voters, 1 zones)",
+		)
+	})
+}
+
+func TestZoneConfigForMultiRegionDatabaseWithPrimaryRegionAvailabilityZones(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const regionEnumID = 100
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c"}
+	voterConstraintsForZones := func(numReplicas int32, zones ...string) []zonepb.ConstraintsConjunction {
+		var ret []zonepb.ConstraintsConjunction
+		for _, zone := range zones {
+			ret = append(ret, zonepb.ConstraintsConjunction{
+				NumReplicas: numReplicas,
+				Constraints: []zonepb.Constraint{
+					{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+					{Type: zonepb.Constraint_REQUIRED, Key: "zone", Value: zone},
+				},
+			})
+		}
+		return ret
+	}
+
+	testCases := []struct {
+		desc                     string
+		opts                     []multiregion.MakeRegionConfigOption
+		expectedVoterConstraints []zonepb.ConstraintsConjunction
+	}{
+		{
+			desc: "3 zones",
+			opts: []multiregion.MakeRegionConfigOption{
+				multiregion.WithPrimaryRegionAvailabilityZones("az1", "az2", "az3"),
+			},
+			expectedVoterConstraints: voterConstraintsForZones(1, "az1", "az2", "az3"),
+		},
+		{
+			desc: "4 zones",
+			opts: []multiregion.MakeRegionConfigOption{
+				multiregion.WithPrimaryRegionAvailabilityZones("az1", "az2", "az3", "az4"),
+			},
+			// The fourth zone holds no voter.
+			expectedVoterConstraints: voterConstraintsForZones(1, "az1", "az2", "az3"),
+		},
+		{
+			desc: "voter regions",
+			opts: []multiregion.MakeRegionConfigOption{
+				multiregion.WithPrimaryRegionAvailabilityZones("az1", "az2", "az3"),
+				multiregion.WithVoterRegions(catpb.RegionNames{"region_a", "region_b"}),
+			},
+			// The voters are not all in the primary region, so they are not
+			// spread across its zones.
+			expectedVoterConstraints: []zonepb.ConstraintsConjunction{
+				{
+					NumReplicas: 2,
+					Constraints: []zonepb.Constraint{
+						{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+					},
+				},
+				{
+					NumReplicas: 1,
+					Constraints: []zonepb.Constraint{
+						{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			regionConfig := multiregion.MakeRegionConfig(
+				regions, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, regionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				tc.opts...,
+			)
+			require.NoError(t, multiregion.ValidateRegionConfig(regionConfig))
+			zc, err := zoneConfigForMultiRegionDatabase(regionConfig)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedVoterConstraints, zc.VoterConstraints)
+			require.Equal(t, int32(3), *zc.NumVoters)
+			require.NoError(t, zc.Validate())
+		})
+	}
+}
+
+func TestMaybeValidateGeneratedZoneConfig(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	defer func(old bool) { validateGeneratedZoneConfigs = old }(validateGeneratedZoneConfigs)
+	validateGeneratedZoneConfigs = true
+
+	regionConfig := multiregion.MakeRegionConfig(
+		catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_a", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+	)
+	dbZoneConfig, err := zoneConfigForMultiRegionDatabase(regionConfig)
+	require.NoError(t, err)
+	_, err = zoneConfigForMultiRegionTable(
+		catpb.LocalityConfig{Locality: &catpb.LocalityConfig_Global_{}}, regionConfig,
+	)
+	require.NoError(t, err)
+	_, err = zoneConfigForMultiRegionPartition("region_b", "" /* homeRegion */, regionConfig)
+	require.NoError(t, err)
+
+	t.Run("malformed", func(t *testing.T) {
+		// More voters than replicas.
+		zc := protoutil.Clone(&dbZoneConfig).(*zonepb.ZoneConfig)
+		zc.NumVoters = proto.Int32(*zc.NumReplicas + 1)
+		err := maybeValidateGeneratedZoneConfig(zc)
+		require.True(t, errors.HasAssertionFailure(err))
+		require.Regexp(t, "num_voters cannot be greater than num_replicas", err)
+
+		// A prohibitive voter constraint.
+		zc = protoutil.Clone(&dbZoneConfig).(*zonepb.ZoneConfig)
+		zc.VoterConstraints[0].Constraints[0].Type = zonepb.Constraint_PROHIBITED
+		err = maybeValidateGeneratedZoneConfig(zc)
+		require.True(t, errors.HasAssertionFailure(err))
+		require.Regexp(t, "voter_constraints cannot contain prohibitive constraints", err)
+
+		// The validation is skipped unless enabled.
+		validateGeneratedZoneConfigs = false
+		require.NoError(t, maybeValidateGeneratedZoneConfig(zc))
+	})
+}
+
+func TestTransitionalZoneConfigForPrimaryChange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const regionEnumID = 100
+	regionConfig := multiregion.MakeRegionConfig(
+		catpb.RegionNames{"region_a", "region_b", "region_c"},
+		"region_a",
+		descpb.SurvivalGoal_ZONE_FAILURE,
+		regionEnumID,
+		descpb.DataPlacement_DEFAULT,
+		nil,
+	)
+
+	zc, err := transitionalZoneConfigForPrimaryChange(regionConfig, "region_b")
+	require.NoError(t, err)
+	require.NoError(t, zc.Validate())
+	// The new primary region is preferred for leases, followed by the old one.
+	require.Equal(t, []zonepb.LeasePreference{
+		{
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_b"},
+			},
+		},
+		{
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+			},
+		},
+	}, zc.LeasePreferences)
+	// Other than the lease preferences, the zone config is the one of the
+	// database with the new primary region.
+	expected, err := zoneConfigForMultiRegionDatabase(regionConfig.WithPrimaryRegion("region_b"))
+	require.NoError(t, err)
+	expected.LeasePreferences = zc.LeasePreferences
+	require.Equal(t, expected, zc)
+
+	// Keeping the primary region does not require a transition.
+	zc, err = transitionalZoneConfigForPrimaryChange(regionConfig, "region_a")
+	require.NoError(t, err)
+	expected, err = zoneConfigForMultiRegionDatabase(regionConfig)
+	require.NoError(t, err)
+	require.Equal(t, expected, zc)
+
+	_, err = transitionalZoneConfigForPrimaryChange(regionConfig, "region_d")
+	require.EqualError(t, err, `region "region_d" has not been added to the database`)
+}
+
+func TestZoneConfigForMultiRegionDatabaseWithNonVoterStorageClass(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const regionEnumID = 100
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"}
+	storageClass := zonepb.Constraint{Type: zonepb.Constraint_REQUIRED, Value: "hdd"}
+	regionConstraint := func(
+		region catpb.RegionName, withStorageClass bool,
+	) zonepb.ConstraintsConjunction {
+		c := zonepb.ConstraintsConjunction{
+			NumReplicas: 1,
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: string(region)},
+			},
+		}
+		if withStorageClass {
+			c.Constraints = append(c.Constraints, storageClass)
+		}
+		return c
+	}
+
+	testCases := []struct {
+		desc     string
+		survival descpb.SurvivalGoal
+		opts     []multiregion.MakeRegionConfigOption
+		// expected are the replica constraints with the storage class.
+		expected []zonepb.ConstraintsConjunction
+	}{
+		{
+			// All voters are in the primary region.
+			desc:     "zone survival",
+			survival: descpb.SurvivalGoal_ZONE_FAILURE,
+			expected: []zonepb.ConstraintsConjunction{
+				regionConstraint("region_a", false),
+				regionConstraint("region_b", true),
+				regionConstraint("region_c", true),
+				regionConstraint("region_d", true),
+			},
+		},
+		{
+			// The voters outside of the primary region may be placed in any
+			// region, so no replica can be constrained to the storage class.
+			desc:     "region survival",
+			survival: descpb.SurvivalGoal_REGION_FAILURE,
+			expected: []zonepb.ConstraintsConjunction{
+				regionConstraint("region_a", false),
+				regionConstraint("region_b", false),
+				regionConstraint("region_c", false),
+				regionConstraint("region_d", false),
+			},
+		},
+		{
+			desc:     "region survival with a non-voting region",
+			survival: descpb.SurvivalGoal_REGION_FAILURE,
+			opts: []multiregion.MakeRegionConfigOption{
+				multiregion.WithNonVotingRegions(catpb.RegionNames{"region_d"}),
+			},
+			expected: []zonepb.ConstraintsConjunction{
+				regionConstraint("region_a", false),
+				regionConstraint("region_b", false),
+				regionConstraint("region_c", false),
+				regionConstraint("region_d", true),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			regionConfig := multiregion.MakeRegionConfig(
+				regions, "region_a", tc.survival, regionEnumID, descpb.DataPlacement_DEFAULT, nil, tc.opts...,
+			)
+			withStorageClass := multiregion.MakeRegionConfig(
+				regions, "region_a", tc.survival, regionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				append(tc.opts, multiregion.WithNonVoterStorageClass("hdd"))...,
+			)
+			require.NoError(t, multiregion.ValidateRegionConfig(withStorageClass))
+
+			expected, err := zoneConfigForMultiRegionDatabase(regionConfig)
+			require.NoError(t, err)
+			zc, err := zoneConfigForMultiRegionDatabase(withStorageClass)
+			require.NoError(t, err)
+			require.NoError(t, zc.Validate())
+			require.Equal(t, tc.expected, zc.Constraints)
+
+			// The storage class is never required of voters.
+			for _, c := range zc.VoterConstraints {
+				require.NotContains(t, c.Constraints, storageClass)
+			}
+			expected.Constraints = tc.expected
+			require.Equal(t, expected, zc)
+		})
+	}
+}
+
+func TestZoneConfigForMultiRegionDatabaseWithStandbyRegion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const regionEnumID = 100
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"}
+	regionConstraint := func(region catpb.RegionName, numReplicas int32) zonepb.ConstraintsConjunction {
+		return zonepb.ConstraintsConjunction{
+			NumReplicas: numReplicas,
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: string(region)},
+			},
+		}
+	}
+	replicaConstraints := []zonepb.ConstraintsConjunction{
+		regionConstraint("region_a", 1),
+		regionConstraint("region_b", 1),
+		regionConstraint("region_c", 1),
+		regionConstraint("region_d", 1),
+	}
+	leasePreferences := []zonepb.LeasePreference{
+		{
+			Constraints: []zonepb.Constraint{
+				{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc     string
+		survival descpb.SurvivalGoal
+		expected zonepb.ZoneConfig
+	}{
+		{
+			// All voters are in the primary region, so the standby region
+			// holds a non-voter like any other region.
+			desc:     "zone survival",
+			survival: descpb.SurvivalGoal_ZONE_FAILURE,
+			expected: zonepb.ZoneConfig{
+				NumReplicas:                 proto.Int32(6),
+				NumVoters:                   proto.Int32(3),
+				LeasePreferences:            leasePreferences,
+				Constraints:                 replicaConstraints,
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints:            []zonepb.ConstraintsConjunction{regionConstraint("region_a", 0)},
+			},
+		},
+		{
+			// The voters are spread across the other regions, and the standby
+			// region holds a sixth replica.
+			desc:     "region survival",
+			survival: descpb.SurvivalGoal_REGION_FAILURE,
+			expected: zonepb.ZoneConfig{
+				NumReplicas:                 proto.Int32(6),
+				NumVoters:                   proto.Int32(5),
+				LeasePreferences:            leasePreferences,
+				Constraints:                 replicaConstraints,
+				NullVoterConstraintsIsEmpty: true,
+				VoterConstraints: []zonepb.ConstraintsConjunction{
+					regionConstraint("region_a", 2),
+					regionConstraint("region_b", 2),
+					regionConstraint("region_c", 1),
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			regionConfig := multiregion.MakeRegionConfig(
+				regions, "region_a", tc.survival, regionEnumID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithStandbyRegion("region_d"),
+			)
+			require.NoError(t, multiregion.ValidateRegionConfig(regionConfig))
+			zc, err := zoneConfigForMultiRegionDatabase(regionConfig)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, zc)
+			require.NoError(t, zc.Validate())
+
+			// The standby region gets a replica, but neither voters nor leases.
+			for _, c := range zc.VoterConstraints {
+				require.NotEqual(t, "region_d", c.Constraints[0].Value)
+			}
+			for _, p := range zc.LeasePreferences {
+				require.NotEqual(t, "region_d", p.Constraints[0].Value)
+			}
+
+			// The standby region raises the number of replicas needed by the
+			// other regions.
+			withoutStandby, err := zoneConfigForMultiRegionDatabase(multiregion.MakeRegionConfig(
+				regions[:3], "region_a", tc.survival, regionEnumID, descpb.DataPlacement_DEFAULT, nil,
+			))
+			require.NoError(t, err)
+			require.Equal(t, *withoutStandby.NumReplicas+1, *zc.NumReplicas)
+			require.Equal(t, *withoutStandby.NumVoters, *zc.NumVoters)
+		})
+	}
+}
+
+func TestZoneConfigForSystemDatabaseMultiRegion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const regionEnumID = 100
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c"}
+	regionConstraints := func(regions ...catpb.RegionName) []zonepb.ConstraintsConjunction {
+		ret := make([]zonepb.ConstraintsConjunction, len(regions))
+		for i, region := range regions {
+			ret[i] = zonepb.ConstraintsConjunction{
+				NumReplicas: 1,
+				Constraints: []zonepb.Constraint{
+					{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: string(region)},
+				},
+			}
+		}
+		return ret
+	}
+
+	t.Run("zone survival", func(t *testing.T) {
+		regionConfig := multiregion.MakeRegionConfig(
+			regions, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, regionEnumID, descpb.DataPlacement_DEFAULT, nil,
+		)
+		user, err := zoneConfigForMultiRegionDatabase(regionConfig)
+		require.NoError(t, err)
+		system, err := zoneConfigForSystemDatabaseMultiRegion(regionConfig)
+		require.NoError(t, err)
+		require.NoError(t, system.Validate())
+
+		// The voters of a user database are all in the primary region, while
+		// those of the system database are spread to survive a region failure.
+		require.Equal(t, int32(3), *user.NumVoters)
+		require.Equal(t, int32(5), *system.NumVoters)
+		require.Equal(t, []zonepb.ConstraintsConjunction{
+			{
+				NumReplicas: 2,
+				Constraints: []zonepb.Constraint{
+					{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: "region_a"},
+				},
+			},
+		}, system.VoterConstraints)
+		expected, err := zoneConfigForMultiRegionDatabase(
+			regionConfig.WithSurvivalGoal(descpb.SurvivalGoal_REGION_FAILURE),
+		)
+		require.NoError(t, err)
+		require.Equal(t, expected, system)
+		require.Equal(t, user.LeasePreferences, system.LeasePreferences)
+	})
+
+	t.Run("restricted placement", func(t *testing.T) {
+		regionConfig := multiregion.MakeRegionConfig(
+			regions, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, regionEnumID, descpb.DataPlacement_RESTRICTED, nil,
+		)
+		user, err := zoneConfigForMultiRegionDatabase(regionConfig)
+		require.NoError(t, err)
+		system, err := zoneConfigForSystemDatabaseMultiRegion(regionConfig)
+		require.NoError(t, err)
+
+		// The system database keeps a replica in every region.
+		require.Nil(t, user.Constraints)
+		require.Equal(t, regionConstraints(regions...), system.Constraints)
+	})
+
+	t.Run("too few regions to survive a region failure", func(t *testing.T) {
+		regionConfig := multiregion.MakeRegionConfig(
+			regions[:2], "region_a", descpb.SurvivalGoal_ZONE_FAILURE, regionEnumID, descpb.DataPlacement_DEFAULT, nil,
+		)
+		user, err := zoneConfigForMultiRegionDatabase(regionConfig)
+		require.NoError(t, err)
+		system, err := zoneConfigForSystemDatabaseMultiRegion(regionConfig)
+		require.NoError(t, err)
+		require.Equal(t, user, system)
+	})
+
+	t.Run("custom constraint key", func(t *testing.T) {
+		regionConfig := multiregion.MakeRegionConfig(
+			regions, "region_a", descpb.SurvivalGoal_REGION_FAILURE, regionEnumID, descpb.DataPlacement_DEFAULT, nil,
+			multiregion.WithConstraintKey(multiregion.ReplicaConstraintKind, "dc"),
+		)
+		system, err := zoneConfigForSystemDatabaseMultiRegion(regionConfig)
+		require.NoError(t, err)
+		for _, c := range system.Constraints {
+			require.Equal(t, "dc", c.Constraints[0].Key)
+		}
+		for _, c := range system.VoterConstraints {
+			require.Equal(t, "region", c.Constraints[0].Key)
+		}
+	})
+}
+
+func TestValidateFullMultiRegionLayout(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const dbID = 100
+	regions := catpb.RegionNames{"region_a", "region_b", "region_c"}
+	regionB := catpb.RegionName("region_b")
+	tables := map[descpb.ID]catpb.LocalityConfig{
+		101: {
+			Locality: &catpb.LocalityConfig_Global_{
+				Global: &catpb.LocalityConfig_Global{},
+			},
+		},
+		102: {
+			Locality: &catpb.LocalityConfig_RegionalByTable_{
+				RegionalByTable: &catpb.LocalityConfig_RegionalByTable{},
+			},
+		},
+		103: {
+			Locality: &catpb.LocalityConfig_RegionalByTable_{
+				RegionalByTable: &catpb.LocalityConfig_RegionalByTable{Region: &regionB},
+			},
+		},
+		104: {
+			Locality: &catpb.LocalityConfig_RegionalByRow_{
+				RegionalByRow: &catpb.LocalityConfig_RegionalByRow{},
+			},
+		},
+		105: {
+			Locality: &catpb.LocalityConfig_RegionalByRow_{
+				RegionalByRow: &catpb.LocalityConfig_RegionalByRow{},
+			},
+		},
+	}
+	homeRegions := map[descpb.ID]catpb.RegionName{105: regionB}
+
+	testCases := []struct {
+		desc         string
+		regionConfig multiregion.RegionConfig
+		err          string
+	}{
+		{
+			desc: "zone survival",
+			regionConfig: multiregion.MakeRegionConfig(
+				regions, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+			),
+		},
+		{
+			desc: "region survival",
+			regionConfig: multiregion.MakeRegionConfig(
+				regions, "region_a", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+			),
+		},
+		{
+			// The database constraints pin a single replica outside of the
+			// primary region, which cannot hold the voters of the table homed
+			// in region_b.
+			desc: "primary region only voters",
+			regionConfig: multiregion.MakeRegionConfig(
+				regions, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithPrimaryRegionOnlyVoters(),
+			),
+			err: "zone config for table 103: voter constraints require 3 voters in region region_b, " +
+				"but constraints pin 1 replicas there",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := ValidateFullMultiRegionLayout(dbID, tables, homeRegions, tc.regionConfig)
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestQuorumRegions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		desc         string
+		regionConfig multiregion.RegionConfig
+		expected     []catpb.RegionNames
+	}{
+		{
+			desc: "three regions, zone survival",
+			regionConfig: multiregion.MakeRegionConfig(
+				catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_a",
+				descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+			),
+			// All of the voters are in the primary region.
+			expected: []catpb.RegionNames{{"region_a"}},
+		},
+		{
+			desc: "three regions, region survival",
+			regionConfig: multiregion.MakeRegionConfig(
+				catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_a",
+				descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+			),
+			// The voters are spread 2-2-1, so that any single region can be lost.
+			expected: []catpb.RegionNames{
+				{"region_a", "region_b"},
+				{"region_a", "region_c"},
+				{"region_b", "region_c"},
+			},
+		},
+		{
+			desc: "four regions, region survival",
+			regionConfig: multiregion.MakeRegionConfig(
+				catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"}, "region_a",
+				descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+			),
+			// The voters are spread 2-1-1-1: losing the primary region requires
+			// all of the other regions to be available.
+			expected: []catpb.RegionNames{
+				{"region_a", "region_b"},
+				{"region_a", "region_c"},
+				{"region_a", "region_d"},
+				{"region_b", "region_c", "region_d"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			quorumRegions, err := QuorumRegions(tc.regionConfig)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, quorumRegions)
+		})
+	}
+}
+
+func TestRequiresCrossRegionQuorum(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		desc         string
+		regionConfig multiregion.RegionConfig
+		expected     bool
+	}{
+		{
+			desc: "single region, zone survival",
+			regionConfig: multiregion.MakeRegionConfig(
+				catpb.RegionNames{"region_a"}, "region_a",
+				descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+			),
+			expected: false,
+		},
+		{
+			desc: "three regions, zone survival",
+			regionConfig: multiregion.MakeRegionConfig(
+				catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_a",
+				descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+			),
+			expected: false,
+		},
+		{
+			desc: "three regions, zone survival, voters spread across voter regions",
+			regionConfig: multiregion.MakeRegionConfig(
+				catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_a",
+				descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithVoterRegions(catpb.RegionNames{"region_a", "region_b", "region_c"}),
+			),
+			expected: true,
+		},
+		{
+			desc: "three regions, region survival",
+			regionConfig: multiregion.MakeRegionConfig(
+				catpb.RegionNames{"region_a", "region_b", "region_c"}, "region_a",
+				descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+			),
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			requiresCrossRegionQuorum, err := RequiresCrossRegionQuorum(tc.regionConfig)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, requiresCrossRegionQuorum)
+		})
+	}
+}
+
+func TestVoterRegions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	threeRegions := catpb.RegionNames{"region_a", "region_b", "region_c"}
+	fourRegions := catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"}
+	fiveRegions := catpb.RegionNames{"region_a", "region_b", "region_c", "region_d", "region_e"}
+	testCases := []struct {
+		desc         string
+		regionConfig multiregion.RegionConfig
+		expected     catpb.RegionNames
+	}{
+		{
+			desc: "zone survival",
+			regionConfig: multiregion.MakeRegionConfig(
+				threeRegions, "region_b", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+			),
+			expected: catpb.RegionNames{"region_b"},
+		},
+		{
+			desc: "region survival",
+			regionConfig: multiregion.MakeRegionConfig(
+				threeRegions, "region_b", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+			),
+			expected: threeRegions,
+		},
+		{
+			desc: "region survival, primary region in a super region",
+			regionConfig: multiregion.MakeRegionConfig(
+				fourRegions, "region_b", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT,
+				[]descpb.SuperRegion{
+					{SuperRegionName: "super_region_abc", Regions: catpb.RegionNames{"region_a", "region_b", "region_c"}},
+				},
+			),
+			expected: catpb.RegionNames{"region_a", "region_b", "region_c"},
+		},
+		{
+			desc: "region survival, voter weights",
+			regionConfig: multiregion.MakeRegionConfig(
+				fourRegions, "region_b", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithVoterWeights(map[catpb.RegionName]int32{"region_b": 2, "region_c": 2, "region_d": 1}),
+			),
+			expected: catpb.RegionNames{"region_b", "region_c", "region_d"},
+		},
+		{
+			desc: "region survival, non-voting regions",
+			regionConfig: multiregion.MakeRegionConfig(
+				fiveRegions, "region_a", descpb.SurvivalGoal_REGION_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithNonVotingRegions(catpb.RegionNames{"region_d", "region_e"}),
+			),
+			expected: catpb.RegionNames{"region_a", "region_b", "region_c"},
+		},
+		{
+			desc: "zone survival, voter regions",
+			regionConfig: multiregion.MakeRegionConfig(
+				fourRegions, "region_a", descpb.SurvivalGoal_ZONE_FAILURE, descpb.InvalidID, descpb.DataPlacement_DEFAULT, nil,
+				multiregion.WithVoterRegions(catpb.RegionNames{"region_a", "region_c", "region_d"}),
+			),
+			expected: catpb.RegionNames{"region_a", "region_c", "region_d"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			regions, err := VoterRegions(tc.regionConfig)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, regions)
+		})
+	}
+}
+
+func TestZoneConfigsEquivalent(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	region := func(r string) zonepb.Constraint {
+		return zonepb.Constraint{Type: zonepb.Constraint_REQUIRED, Key: "region", Value: r}
+	}
+	zone := func(z string) zonepb.Constraint {
+		return zonepb.Constraint{Type: zonepb.Constraint_PROHIBITED, Key: "zone", Value: z}
+	}
+	base := zonepb.ZoneConfig{
+		NumReplicas: proto.Int32(5),
+		NumVoters:   proto.Int32(3),
+		Constraints: []zonepb.ConstraintsConjunction{
+			{NumReplicas: 1, Constraints: []zonepb.Constraint{region("a"), zone("a1")}},
+			{NumReplicas: 1, Constraints: []zonepb.Constraint{region("b")}},
+		},
+		VoterConstraints: []zonepb.ConstraintsConjunction{
+			{NumReplicas: 2, Constraints: []zonepb.Constraint{region("a")}},
+		},
+		LeasePreferences: []zonepb.LeasePreference{
+			{Constraints: []zonepb.Constraint{region("a"), zone("a1")}},
+			{Constraints: []zonepb.Constraint{region("b")}},
+		},
+	}
+
+	testCases := []struct {
+		desc     string
+		modify   func(zc *zonepb.ZoneConfig)
+		expected bool
+	}{
+		{
+			desc:     "identical",
+			modify:   func(zc *zonepb.ZoneConfig) {},
+			expected: true,
+		},
+		{
+			desc: "reordered constraints",
+			modify: func(zc *zonepb.ZoneConfig) {
+				zc.Constraints = []zonepb.ConstraintsConjunction{
+					{NumReplicas: 1, Constraints: []zonepb.Constraint{region("b")}},
+					{NumReplicas: 1, Constraints: []zonepb.Constraint{zone("a1"), region("a")}},
+				}
+				zc.LeasePreferences = []zonepb.LeasePreference{
+					{Constraints: []zonepb.Constraint{zone("a1"), region("a")}},
+					{Constraints: []zonepb.Constraint{region("b")}},
+				}
+			},
+			expected: true,
+		},
+		{
+			desc: "different constraint value",
+			modify: func(zc *zonepb.ZoneConfig) {
+				zc.VoterConstraints = []zonepb.ConstraintsConjunction{
+					{NumReplicas: 2, Constraints: []zonepb.Constraint{region("b")}},
+				}
+			},
+			expected: false,
+		},
+		{
+			desc: "reordered lease preferences",
+			modify: func(zc *zonepb.ZoneConfig) {
+				zc.LeasePreferences = []zonepb.LeasePreference{
+					{Constraints: []zonepb.Constraint{region("b")}},
+					{Constraints: []zonepb.Constraint{region("a"), zone("a1")}},
+				}
+			},
+			expected: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			zc := base
+			tc.modify(&zc)
+			require.Equal(t, tc.expected, ZoneConfigsEquivalent(base, zc))
+			require.Equal(t, tc.expected, ZoneConfigsEquivalent(zc, base))
+		})
+	}
+
+	t.Run("nil and empty slices", func(t *testing.T) {
+		a := zonepb.ZoneConfig{NumReplicas: proto.Int32(3)}
+		b := zonepb.ZoneConfig{
+			NumReplicas:      proto.Int32(3),
+			Constraints:      []zonepb.ConstraintsConjunction{},
+			VoterConstraints: []zonepb.ConstraintsConjunction{},
+			LeasePreferences: []zonepb.LeasePreference{},
+			Subzones:         []zonepb.Subzone{},
+		}
+		require.True(t, ZoneConfigsEquivalent(a, b))
+	})
+}
+
+func TestMaybeAnnotateZoneConfigForMultiRegionDatabase(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	regionConfig := multiregion.MakeRegionConfig(
+		catpb.RegionNames{"region_a", "region_b", "region_c"},
+		"region_b",
+		descpb.SurvivalGoal_REGION_FAILURE,
+		descpb.InvalidID,
+		descpb.DataPlacement_DEFAULT,
+		nil,
+	)
+	expected, err := zoneConfigForMultiRegionDatabase(regionConfig)
+	require.NoError(t, err)
+
+	t.Run("disabled", func(t *testing.T) {
+		zc, err := zoneConfigForMultiRegionDatabase(regionConfig)
+		require.NoError(t, err)
+		maybeAnnotateZoneConfigForMultiRegionDatabase(&st.SV, &zc, regionConfig)
+		require.Nil(t, zc.MultiRegionAnnotation)
+		require.Equal(t, expected, zc)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		zoneConfigAnnotationEnabled.Override(ctx, &st.SV, true)
+		defer zoneConfigAnnotationEnabled.Override(ctx, &st.SV, false)
+		zc, err := zoneConfigForMultiRegionDatabase(regionConfig)
+		require.NoError(t, err)
+		maybeAnnotateZoneConfigForMultiRegionDatabase(&st.SV, &zc, regionConfig)
+		require.Equal(t, &zonepb.MultiRegionAnnotation{
+			SurvivalGoal: "REGION_FAILURE",
+			Placement:    "DEFAULT",
+		}, zc.MultiRegionAnnotation)
+
+		// The annotation doesn't change the multi-region fields, and it
+		// round-trips through the stored encoding of the zone config.
+		same, _, err := zc.DiffWithZone(expected, zonepb.MultiRegionZoneConfigFields)
+		require.NoError(t, err)
+		require.True(t, same)
+		buf, err := protoutil.Marshal(&zc)
+		require.NoError(t, err)
+		var roundTripped zonepb.ZoneConfig
+		require.NoError(t, protoutil.Unmarshal(buf, &roundTripped))
+		require.Equal(t, zc.MultiRegionAnnotation, roundTripped.MultiRegionAnnotation)
+	})
+}
+
+func TestValidateReplicaFeasibility(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// Four regions under zone survival have 6 replicas, including 3 voters in
+	// the primary region and a replica in every other region.
+	zc, err := zoneConfigForMultiRegionDatabase(multiregion.MakeRegionConfig(
+		catpb.RegionNames{"region_a", "region_b", "region_c", "region_d"},
+		"region_a",
+		descpb.SurvivalGoal_ZONE_FAILURE,
+		descpb.InvalidID,
+		descpb.DataPlacement_DEFAULT,
+		nil,
+	))
+	require.NoError(t, err)
+	require.Equal(t, proto.Int32(6), zc.NumReplicas)
+
+	testCases := []struct {
+		desc           string
+		zc             zonepb.ZoneConfig
+		nodesPerRegion map[catpb.RegionName]int
+		expectedErr    string
+	}{
+		{
+			desc: "feasible",
+			zc:   zc,
+			nodesPerRegion: map[catpb.RegionName]int{
+				"region_a": 3,
+				"region_b": 1,
+				"region_c": 1,
+				"region_d": 2,
+			},
+		},
+		{
+			desc: "too few nodes in the primary region and a region without nodes",
+			zc:   zc,
+			nodesPerRegion: map[catpb.RegionName]int{
+				"region_a": 2,
+				"region_b": 3,
+				"region_c": 3,
+			},
+			expectedErr: "not enough nodes to place the required replicas in regions: " +
+				"region_a (3 required, 2 available), region_d (1 required, 0 available)",
+		},
+		{
+			desc: "too few nodes overall",
+			zc: zonepb.ZoneConfig{
+				NumReplicas: proto.Int32(5),
+				Constraints: []zonepb.ConstraintsConjunction{
+					{NumReplicas: 1, Constraints: []zonepb.Constraint{makeRequiredConstraintForRegion("region_a")}},
+				},
+			},
+			nodesPerRegion: map[catpb.RegionName]int{
+				"region_a": 2,
+				"region_b": 2,
+			},
+			expectedErr: "num_replicas = 5 but only 4 nodes are available",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := ValidateReplicaFeasibility(tc.zc, tc.nodesPerRegion)
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.expectedErr)
+			}
 		})
 	}
 }
@@ -67,6 +67,13 @@ const (
 	// RunningStatusWaitingGC is for jobs that are currently in progress and
 	// are waiting for the GC interval to expire
 	RunningStatusWaitingGC jobs.RunningStatus = "waiting for GC TTL"
+	// RunningStatusWaitingForGCSlot is for GC jobs that have expired elements
+	// but are waiting for another GC job on the same node to finish clearing
+	// data, because of sql.gc_job.max_concurrent_clears_per_node.
+	RunningStatusWaitingForGCSlot jobs.RunningStatus = "waiting for a GC slot"
+	// RunningStatusWaitingForDestructiveOps is for GC jobs that are parked
+	// because kv.background_gc.destructive_ops.enabled is disabled.
+	RunningStatusWaitingForDestructiveOps jobs.RunningStatus = "waiting for destructive ops to be enabled"
 	// RunningStatusDeleteOnly is for jobs that are currently waiting on
 	// the cluster to converge to seeing the schema element in the DELETE_ONLY
 	// state.
@@ -2265,10 +2272,40 @@ func CreateGCJobRecord(
 type GCJobTestingKnobs struct {
 	RunBeforeResume    func(jobID jobspb.JobID) error
 	RunBeforePerformGC func(jobID jobspb.JobID) error
+	// RunAfterPerformGC is called after a GC pass has completed and the job has
+	// transitioned back to RunningStatusWaitingGC.
+	RunAfterPerformGC func(jobID jobspb.JobID) error
+	// RunAfterClearRange is called after the data of a dropped table or index
+	// has been cleared, before the clearing is optionally verified.
+	RunAfterClearRange func(span roachpb.Span)
+	// RunAfterChunkCleared is called after each ClearRange request issued for
+	// a dropped table or index, once the high-water key of the element has
+	// been updated in the job progress. It is passed the end key of the chunk.
+	RunAfterChunkCleared func(endKey roachpb.Key)
+	// RunAfterUnsplitRanges is called after the ranges of the dropped tables
+	// and indexes of a GC job have been unsplit.
+	RunAfterUnsplitRanges func(jobID jobspb.JobID)
+	// RunAfterGCElement is called after a table or an index has been GC'd
+	// during a GC pass, before moving on to the next element. It is passed the
+	// ID of the dropped table, or the IDs of the dropped index and of its
+	// table. The index ID is zero for tables.
+	RunAfterGCElement func(tableID descpb.ID, indexID descpb.IndexID)
 	// RunAfterIsProtectedCheck is called after a successfully checking the
 	// protected timestamp status of a table or an index. The protection status is
 	// passed in along with the jobID.
 	RunAfterIsProtectedCheck func(jobID jobspb.JobID, isProtected bool)
+	// RunAfterStallDetected is called when the watchdog of a GC pass detects
+	// that the pass made no progress for longer than the stall timeout.
+	RunAfterStallDetected func(jobID jobspb.JobID)
+	// VerifyCodecOnResume, if set, makes the GC job check that its SQL codec
+	// computes the key prefixes of dropped tables and indexes within the
+	// keyspace of the tenant the server runs as, before it clears or unsplits
+	// anything. The job fails if the check does not pass.
+	VerifyCodecOnResume bool
+	// IndexQueriesPerSecond, if set, overrides the range stats lookup used to
+	// decide whether a dropped index is still too hot to be GC'd. It is passed
+	// the span of each range of the index and returns its queries per second.
+	IndexQueriesPerSecond func(span roachpb.Span) float64
 	// DisableNewProtectedTimestampSubsystemCheck disables checking the new
 	// protected timestamp subsystem when checking the protection status of a
 	// table or an index. This is useful for tests that disable the span
@@ -2947,25 +2984,38 @@ func DeleteTableDescAndZoneConfig(
 ) error {
 	log.Infof(ctx, "removing table descriptor and zone config for table %d", tableDesc.GetID())
 	return db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
-		if !settings.Version.IsActive(
-			ctx, clusterversion.DisableSystemConfigGossipTrigger,
-		) {
-			if err := txn.DeprecatedSetSystemConfigTrigger(codec.ForSystemTenant()); err != nil {
-				return err
-			}
-		}
-		b := &kv.Batch{}
+		return DeleteTableDescAndZoneConfigInTxn(ctx, txn, settings, codec, tableDesc)
+	})
+}
 
-		// Delete the descriptor.
-		descKey := catalogkeys.MakeDescMetadataKey(codec, tableDesc.GetID())
-		b.Del(descKey)
-		// Delete the zone config entry for this table, if necessary.
-		if codec.ForSystemTenant() {
-			zoneKeyPrefix := config.MakeZoneKeyPrefix(codec, tableDesc.GetID())
-			b.DelRange(zoneKeyPrefix, zoneKeyPrefix.PrefixEnd(), false /* returnKeys */)
+// DeleteTableDescAndZoneConfigInTxn is like DeleteTableDescAndZoneConfig, but
+// removes the descriptor and zone config of the table in the given
+// transaction.
+func DeleteTableDescAndZoneConfigInTxn(
+	ctx context.Context,
+	txn *kv.Txn,
+	settings *cluster.Settings,
+	codec keys.SQLCodec,
+	tableDesc catalog.TableDescriptor,
+) error {
+	if !settings.Version.IsActive(
+		ctx, clusterversion.DisableSystemConfigGossipTrigger,
+	) {
+		if err := txn.DeprecatedSetSystemConfigTrigger(codec.ForSystemTenant()); err != nil {
+			return err
 		}
-		return txn.Run(ctx, b)
-	})
+	}
+	b := &kv.Batch{}
+
+	// Delete the descriptor.
+	descKey := catalogkeys.MakeDescMetadataKey(codec, tableDesc.GetID())
+	b.Del(descKey)
+	// Delete the zone config entry for this table, if necessary.
+	if codec.ForSystemTenant() {
+		zoneKeyPrefix := config.MakeZoneKeyPrefix(codec, tableDesc.GetID())
+		b.DelRange(zoneKeyPrefix, zoneKeyPrefix.PrefixEnd(), false /* returnKeys */)
+	}
+	return txn.Run(ctx, b)
 }
 
 // getDependentMutationJobs gets the dependent jobs that need to complete for
@@ -26,6 +26,9 @@ const (
 	// SurvivalGoalZoneFailure indicates a database being able to
 	// withstand a failure of an availibility zone.
 	SurvivalGoalZoneFailure
+	// SurvivalGoalTwoRegionFailure indicates a database being able to
+	// withstand the simultaneous failure of two entire regions.
+	SurvivalGoalTwoRegionFailure
 )
 
 // Format implements the NodeFormatter interface.
@@ -35,6 +38,8 @@ func (node *SurvivalGoal) Format(ctx *FmtCtx) {
 		ctx.WriteString("SURVIVE REGION FAILURE")
 	case SurvivalGoalZoneFailure:
 		ctx.WriteString("SURVIVE ZONE FAILURE")
+	case SurvivalGoalTwoRegionFailure:
+		ctx.WriteString("SURVIVE TWO REGION FAILURE")
 	default:
 		panic(errors.AssertionFailedf("unknown survival goal: %d", *node))
 	}
@@ -49,6 +54,8 @@ func (node *SurvivalGoal) TelemetryName() string {
 		return "survive_region_failure"
 	case SurvivalGoalZoneFailure:
 		return "survive_zone_failure"
+	case SurvivalGoalTwoRegionFailure:
+		return "survive_two_region_failure"
 	default:
 		panic(errors.AssertionFailedf("unknown survival goal: %d", *node))
 	}
@@ -2527,6 +2527,18 @@ var charts = []sectionDescription{
 			},
 		},
 	},
+	{
+		Organization: [][]string{{SQLLayer, "SQL", "Schema Change GC"}},
+		Charts: []chartDescription{
+			{
+				Title: "Oldest Pending Drop Age",
+				Metrics: []string{
+					"sql.gc.oldest_pending_drop_age_seconds",
+				},
+				AxisLabel: "Age (seconds)",
+			},
+		},
+	},
 	{
 		Organization: [][]string{{SQLLayer, "SQL", "Feature Flag"}},
 		Charts: []chartDescription{
@@ -27,14 +27,16 @@ import (
 // or size threshold is met, at which point they are put in a queue to be flushed
 // by the child sink.  If the queue is full, current bundle is compacted rather
 // sent, which currently drops the messages but retains their count for later
-// reporting.
-// TODO(knz): Actually report the count of dropped messages.
-// See: https://github.com/cockroachdb/cockroach/issues/72453
+// reporting. The number of messages dropped so far, along with the messages
+// currently buffered, is reported by SinkStats().
 //
 // Should an error occur in the child sink, it's forwarded to the provided
 // errCallback (unless forceSync is requested, in which case the error is returned
 // synchronously, as it would for any other sink).
 type bufferSink struct {
+	// name identifies the sink in the statistics reported by SinkStats().
+	name string
+
 	// child is the wrapped logSink.
 	child logSink
 
@@ -63,12 +65,22 @@ type bufferSink struct {
 
 	// inErrorState is used internally to temporarily disable the sink during error handling.
 	inErrorState bool
+
+	// stats tracks the messages accumulated or queued for flushing but not
+	// yet written to the child sink, and the messages dropped so far because
+	// of buffer fullness. Accessed atomically.
+	stats struct {
+		bufferedBytes   int64
+		bufferedEntries int64
+		dropped         int64
+	}
 }
 
 const bufferSinkDefaultMaxInFlight = 4
 
 func newBufferSink(
 	ctx context.Context,
+	name string,
 	child logSink,
 	maxStaleness time.Duration,
 	triggerSize int,
@@ -80,6 +92,7 @@ func newBufferSink(
 	}
 
 	sink := &bufferSink{
+		name:         name,
 		child:        child,
 		messageCh:    make(chan bufferSinkMessage),
 		flushCh:      make(chan bufferSinkBundle, maxInFlight),
@@ -117,6 +130,8 @@ func (bs *bufferSink) accumulator(ctx context.Context) {
 		appendMessage := func(m bufferSinkMessage) {
 			b.messages = append(b.messages, m)
 			b.byteLen += len(m.b.Bytes()) + 1 // account for the final newline.
+			atomic.AddInt64(&bs.stats.bufferedBytes, int64(len(m.b.Bytes())+1))
+			atomic.AddInt64(&bs.stats.bufferedEntries, 1)
 			if m.flush || m.errorCh != nil || (bs.triggerSize > 0 && b.byteLen > bs.triggerSize) {
 				flush = true
 				// TODO(knz): This seems incorrect. If there is a non-empty
@@ -162,6 +177,9 @@ func (bs *bufferSink) accumulator(ctx context.Context) {
 				atomic.AddInt32(&bs.nInFlight, 1)
 				reset()
 			} else {
+				atomic.AddInt64(&bs.stats.bufferedBytes, -messagesByteLen(b.messages))
+				atomic.AddInt64(&bs.stats.bufferedEntries, -int64(len(b.messages)))
+				atomic.AddInt64(&bs.stats.dropped, int64(len(b.messages)))
 				b.compact()
 			}
 		}
@@ -185,6 +203,9 @@ func (bs *bufferSink) accumulator(ctx context.Context) {
 func (bs *bufferSink) flusher(ctx context.Context) {
 	for b := range bs.flushCh {
 		if len(b.messages) > 0 {
+			// Measure the messages before the first buffer is extended below,
+			// to stop accounting for them once the child sink is done.
+			byteLen := messagesByteLen(b.messages)
 			// Append all the messages in the first buffer.
 			buf := b.messages[0].b
 			buf.Grow(b.byteLen - len(buf.Bytes()))
@@ -203,6 +224,8 @@ func (bs *bufferSink) flusher(ctx context.Context) {
 			// Send the accumulated messages to the child sink.
 			err := bs.child.output(buf.Bytes(),
 				sinkOutputOptions{extraFlush: true, forceSync: forceSync})
+			atomic.AddInt64(&bs.stats.bufferedBytes, -byteLen)
+			atomic.AddInt64(&bs.stats.bufferedEntries, -int64(len(b.messages)))
 			if forceSync {
 				b.errorCh <- err
 			} else if err != nil && bs.errCallback != nil {
@@ -254,6 +277,16 @@ type bufferSinkBundle struct {
 	done bool
 }
 
+// messagesByteLen returns the total length in bytes of the given messages,
+// including their separators.
+func messagesByteLen(messages []bufferSinkMessage) int64 {
+	var n int64
+	for _, m := range messages {
+		n += int64(len(m.b.Bytes()) + 1)
+	}
+	return n
+}
+
 // compact compacts a bundle, and is called if the buffer is full.
 // Currently, drops all messages and keeps track of the
 // count. In the future, if there's visibility into message
@@ -268,6 +301,43 @@ func (b *bufferSinkBundle) compact() {
 	b.messages = nil
 }
 
+// SinkStat reports the buffering statistics of a buffered log sink.
+type SinkStat struct {
+	// Name identifies the sink by its position in the logging
+	// configuration, e.g. "file-groups.default".
+	Name string
+	// BufferedBytes and BufferedEntries measure the log entries which
+	// were accepted by the sink but not yet written to its destination.
+	BufferedBytes   int64
+	BufferedEntries int64
+	// Dropped is the number of log entries dropped since the sink was
+	// created because its buffer was full.
+	Dropped int64
+}
+
+// SinkStats returns the buffering statistics of the currently configured
+// sinks which have buffering enabled. Unbuffered sinks write each entry
+// synchronously and are not reported.
+func SinkStats() []SinkStat {
+	var stats []SinkStat
+	_ = logging.allSinkInfos.iter(func(l *sinkInfo) error {
+		if bs, ok := l.sink.(*bufferSink); ok {
+			stats = append(stats, bs.getStats())
+		}
+		return nil
+	})
+	return stats
+}
+
+func (bs *bufferSink) getStats() SinkStat {
+	return SinkStat{
+		Name:            bs.name,
+		BufferedBytes:   atomic.LoadInt64(&bs.stats.bufferedBytes),
+		BufferedEntries: atomic.LoadInt64(&bs.stats.bufferedEntries),
+		Dropped:         atomic.LoadInt64(&bs.stats.dropped),
+	}
+}
+
 // active returns true if this sink is currently active.
 func (bs *bufferSink) active() bool {
 	return !bs.inErrorState && bs.child.active()
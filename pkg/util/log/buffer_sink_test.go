@@ -30,7 +30,7 @@ func getMockBufferSync(
 	ctx, cancel := context.WithCancel(context.Background())
 	ctrl := gomock.NewController(t)
 	mock = NewMockLogSink(ctrl)
-	sink = newBufferSink(ctx, mock, maxStaleness, sizeTrigger, 2 /* maxInFlight */, errCallback)
+	sink = newBufferSink(ctx, "test", mock, maxStaleness, sizeTrigger, 2 /* maxInFlight */, errCallback)
 	cleanup = func() {
 		cancel()
 		ctrl.Finish()
@@ -192,6 +192,60 @@ func TestBufferForceSync(t *testing.T) {
 	atomic.StoreInt32(&marker, 1)
 }
 
+func TestBufferSinkStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer Scope(t).Close(t)
+
+	sink, mock, cleanup := getMockBufferSync(t, 0 /* maxStaleness*/, 0 /* sizeTrigger */, nil /* errCallback*/)
+	defer cleanup()
+	si := &sinkInfo{sink: sink}
+	logging.allSinkInfos.put(si)
+	defer logging.allSinkInfos.del(si)
+
+	getStats := func() SinkStat {
+		for _, s := range SinkStats() {
+			if s.Name == "test" {
+				return s
+			}
+		}
+		t.Fatal("buffered sink not reported")
+		return SinkStat{}
+	}
+
+	// Make the child sink block until ch is closed. The first flush is
+	// blocked in the child sink and the second one fills the flush queue,
+	// so the remaining messages are dropped.
+	ch := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	mock.EXPECT().
+		output(gomock.Eq([]byte("test")), gomock.Any()).
+		Do(addArgs(func() { <-ch; wg.Done() })).
+		Times(2)
+
+	const numMessages = 10
+	for i := 0; i < numMessages; i++ {
+		require.NoError(t, sink.output([]byte("test"), sinkOutputOptions{extraFlush: true}))
+	}
+	succeedsSoon(t, func() error {
+		if s := getStats(); s.Dropped != numMessages-2 || s.BufferedEntries != 2 {
+			return fmt.Errorf("unexpected stats: %+v", s)
+		}
+		return nil
+	})
+	require.Equal(t, int64(2*len("test\n")), getStats().BufferedBytes)
+
+	close(ch)
+	wg.Wait()
+	succeedsSoon(t, func() error {
+		if s := getStats(); s.BufferedEntries != 0 || s.BufferedBytes != 0 {
+			return fmt.Errorf("unexpected stats: %+v", s)
+		}
+		return nil
+	})
+	require.Equal(t, int64(numMessages-2), getStats().Dropped)
+}
+
 type sinkOutputOptionsMatcher struct {
 	extraFlush   gomock.Matcher
 	ignoreErrors gomock.Matcher
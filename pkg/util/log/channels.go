@@ -52,6 +52,9 @@ func logfDepth(
 	entry := makeUnstructuredEntry(
 		ctx, sev, ch,
 		depth+1, true /* redactable */, format, args...)
+	if sev == severity.WARNING {
+		entry.stacks = maybeGetWarningStacks(format)
+	}
 	if sp, el, ok := getSpanOrEventLog(ctx); ok {
 		// Prevent `entry` from moving to the heap if this branch isn't taken.
 		heapEntry := entry
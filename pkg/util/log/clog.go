@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
 	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/redact"
 )
@@ -95,6 +96,10 @@ type loggingT struct {
 		// fatalCh is closed on fatal errors.
 		fatalCh chan struct{}
 
+		// lastFatalEntry is the last entry logged at severity FATAL, if
+		// any. It is set before fatalCh is closed.
+		lastFatalEntry *logpb.Entry
+
 		// active indicates that at least one event has been logged
 		// to this logger already.
 		active        bool
@@ -176,8 +181,8 @@ type loggerT struct {
 	outputMu syncutil.Mutex
 }
 
-// getFileSinkIndex retrieves the index of the fileSink, if defined,
-// in the sinkInfos. Returns -1 if there is no file sink.
+// getFileSinkIndex retrieves the index of the primary fileSink, if
+// defined, in the sinkInfos. Returns -1 if there is no file sink.
 func (l *loggerT) getFileSinkIndex() int {
 	for i, s := range l.sinkInfos {
 		if _, ok := s.sink.(*fileSink); ok {
@@ -187,7 +192,7 @@ func (l *loggerT) getFileSinkIndex() int {
 	return -1
 }
 
-// getFileSink retrieves the file sink if defined.
+// getFileSink retrieves the primary file sink if defined.
 func (l *loggerT) getFileSink() *fileSink {
 	if i := l.getFileSinkIndex(); i != -1 {
 		return l.sinkInfos[i].sink.(*fileSink)
@@ -195,6 +200,21 @@ func (l *loggerT) getFileSink() *fileSink {
 	return nil
 }
 
+// CurrentEntryCount returns the current value of the entry counter of
+// the file sink which the given channel is logged to, e.g. to verify the
+// continuity of the audit logs. The counter is maintained per sink, and
+// so also accounts for the entries of the other channels logged to the
+// same sink. The boolean is false if the channel is not logged to any
+// file sink.
+func CurrentEntryCount(ch Channel) (uint64, bool) {
+	l := logging.getLogger(ch)
+	i := l.getFileSinkIndex()
+	if i == -1 {
+		return 0, false
+	}
+	return atomic.LoadUint64(&l.sinkInfos[i].msgCount), true
+}
+
 // FatalChan is closed when Fatal is called. This can be used to make
 // the process stop handling requests while the final log messages and
 // crash report are being written.
@@ -202,12 +222,27 @@ func FatalChan() <-chan struct{} {
 	return logging.mu.fatalCh
 }
 
-// s ignalFatalCh signals the listeners of l.mu.fatalCh by closing the
-// channel.
+// LastFatalEntry returns the last entry logged at severity FATAL, and
+// false if there is none. The entry is recorded before FatalChan() is
+// closed, so that the listeners of the channel can report the fatal
+// error while the process shuts down. The stack traces which are
+// appended to the entry in the log output are not included.
+func LastFatalEntry() (logpb.Entry, bool) {
+	logging.mu.Lock()
+	defer logging.mu.Unlock()
+	if logging.mu.lastFatalEntry == nil {
+		return logpb.Entry{}, false
+	}
+	return *logging.mu.lastFatalEntry, true
+}
+
+// s ignalFatalCh records the given fatal entry, then signals the
+// listeners of l.mu.fatalCh by closing the channel.
 // l.mu is not held.
-func (l *loggingT) signalFatalCh() {
+func (l *loggingT) signalFatalCh(entry logpb.Entry) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	l.mu.lastFatalEntry = &entry
 	// Close l.fatalCh if it is not already closed (note that we're
 	// holding l.mu to guard against concurrent closes).
 	select {
@@ -224,13 +259,16 @@ func (l *loggerT) outputLogEntry(entry logEntry) {
 	// Mark the logger as active, so that further configuration changes
 	// are disabled. See IsActive() and its callers for details.
 	setActive()
+	// The wall clock may have been adjusted backwards since the previous
+	// entry was output.
+	entry.ts = monotonicTimestamp(entry.ts)
 	var fatalTrigger chan struct{}
 	extraFlush := false
 	isFatal := entry.sev == severity.FATAL
 
 	if isFatal {
 		extraFlush = true
-		logging.signalFatalCh()
+		logging.signalFatalCh(entry.convertToLegacy())
 
 		switch traceback {
 		case tracebackSingle:
@@ -283,6 +321,14 @@ func (l *loggerT) outputLogEntry(entry logEntry) {
 			exitFunc(exit.FatalError(), nil)
 			close(exitCalled)
 		}()
+
+		// Keep a durable copy of the entry, regardless of the sink
+		// thresholds, before the process terminates.
+		writeFatalMirror(entry)
+	}
+
+	if includeUptime.Get() {
+		entry.payload.tags = entry.payload.tags.withUptime(entry.ts)
 	}
 
 	// The following buffers contain the formatted entry before it enters the sink.
@@ -312,7 +358,7 @@ func (l *loggerT) outputLogEntry(entry logEntry) {
 		editedEntry.payload = maybeRedactEntry(editedEntry.payload, s.editor)
 
 		// Format the entry for this sink.
-		bufs.b[i] = s.formatter.formatEntry(editedEntry)
+		bufs.b[i] = formatterForSink(s).formatEntry(editedEntry)
 		someSinkActive = true
 	}
 
@@ -328,13 +374,23 @@ func (l *loggerT) outputLogEntry(entry logEntry) {
 
 		var outputErr error
 		var outputErrExitCode exit.Code
+		// wroteToFileSink is set once the entry has been written to a
+		// file sink. When a channel is routed to multiple file sinks, the
+		// first one accepting the entry is its primary file sink; a
+		// failure on the secondary ones is then not critical, since the
+		// entry is already on disk.
+		wroteToFileSink := false
 		for i, s := range l.sinkInfos {
 			if bufs.b[i] == nil {
 				// The sink was not accepting entries at this level. Nothing to do.
 				continue
 			}
-			if err := s.sink.output(bufs.b[i].Bytes(), sinkOutputOptions{extraFlush: extraFlush, forceSync: isFatal}); err != nil {
-				if !s.criticality {
+			start := timeutil.Now()
+			err := s.sink.output(bufs.b[i].Bytes(), sinkOutputOptions{extraFlush: extraFlush, forceSync: isFatal})
+			maybeReportSlowSinkWrite(s.sink, timeutil.Since(start))
+			_, isFileSink := s.sink.(*fileSink)
+			if err != nil {
+				if !s.criticality || (isFileSink && wroteToFileSink) {
 					// An error on this sink is not critical. Just report
 					// the error and move on.
 					l.reportErrorEverywhereLocked(context.Background(), err)
@@ -346,6 +402,8 @@ func (l *loggerT) outputLogEntry(entry logEntry) {
 					}
 					outputErr = errors.CombineErrors(outputErr, err)
 				}
+			} else if isFileSink {
+				wroteToFileSink = true
 			}
 		}
 		if outputErr != nil {
@@ -26,7 +26,9 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/cli/exit"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
@@ -311,6 +313,45 @@ func TestVmoduleGlob(t *testing.T) {
 	}
 }
 
+// Test that the vmodule configuration can be read back as it was set.
+func TestGetVModule(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer ScopeWithoutShowLogs(t).Close(t)
+
+	defer func() { _ = SetVModule("") }()
+	for _, spec := range []string{"", "clog_test=2", "clog_test=2,vmodule=1,?l*=3,*x=4"} {
+		if err := SetVModule(spec); err != nil {
+			t.Fatal(err)
+		}
+		if got := GetVModule(); got != spec {
+			t.Errorf("expected vmodule %q, got %q", spec, got)
+		}
+		// What is read back can be set again.
+		if err := SetVModule(GetVModule()); err != nil {
+			t.Fatal(err)
+		}
+		if got := GetVModule(); got != spec {
+			t.Errorf("expected vmodule %q after setting it again, got %q", spec, got)
+		}
+	}
+
+	// Patterns with level 0 have no effect and are not reported.
+	if err := SetVModule("clog_test=0,vmodule=1,"); err != nil {
+		t.Fatal(err)
+	}
+	if got, expected := GetVModule(), "vmodule=1"; got != expected {
+		t.Errorf("expected vmodule %q, got %q", expected, got)
+	}
+
+	// An invalid spec leaves the configuration untouched.
+	if err := SetVModule("clog_test"); err == nil {
+		t.Fatal("expected a syntax error")
+	}
+	if got, expected := GetVModule(), "vmodule=1"; got != expected {
+		t.Errorf("expected vmodule %q, got %q", expected, got)
+	}
+}
+
 func TestListLogFiles(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer ScopeWithoutShowLogs(t).Close(t)
@@ -544,6 +585,79 @@ func TestRollover(t *testing.T) {
 	}
 }
 
+// TestTimeBasedRollover verifies that a file sink with a rotation
+// interval rotates its file when the interval boundary is crossed, even
+// though the file is far from its maximum size.
+func TestTimeBasedRollover(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer ScopeWithoutShowLogs(t).Close(t)
+
+	// Rotations are aligned on midnight UTC with a daily interval.
+	lateEvening := time.Date(2022, 3, 14, 23, 59, 59, 0, time.UTC)
+	midnight := time.Date(2022, 3, 15, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, midnight, nextRotationTime(lateEvening, 24*time.Hour))
+	require.Equal(t, midnight.Add(24*time.Hour), nextRotationTime(midnight, 24*time.Hour))
+	require.Equal(t, midnight, nextRotationTime(lateEvening, time.Hour))
+	require.True(t, nextRotationTime(lateEvening, 0).IsZero())
+
+	debugFileSink := debugLog.getFileSink()
+	defer func(previous time.Duration) { debugFileSink.rotationInterval = previous }(debugFileSink.rotationInterval)
+	debugFileSink.rotationInterval = 24 * time.Hour
+
+	Info(context.Background(), "before the day boundary")
+	Flush()
+	fname0 := debugFileSink.getFileName(t)
+
+	// Simulate the crossing of the day boundary.
+	func() {
+		debugFileSink.mu.Lock()
+		defer debugFileSink.mu.Unlock()
+		sb := debugFileSink.mu.file.(*syncBuffer)
+		require.False(t, sb.nextRotation.IsZero())
+		require.Zero(t, sb.nextRotation.Sub(timeutil.Unix(0, 0))%(24*time.Hour))
+		sb.nextRotation = timeutil.Now()
+	}()
+
+	Info(context.Background(), "after the day boundary")
+	Flush()
+	fname1 := debugFileSink.getFileName(t)
+	require.NotEqual(t, fname0, fname1)
+
+	contents0, err := ioutil.ReadFile(fname0)
+	require.NoError(t, err)
+	require.Contains(t, string(contents0), "before the day boundary")
+	require.NotContains(t, string(contents0), "after the day boundary")
+	contents1, err := ioutil.ReadFile(fname1)
+	require.NoError(t, err)
+	require.Contains(t, string(contents1), "after the day boundary")
+}
+
+// TestRolloverHeaderIncludesBuildTag verifies that the header of a log file
+// created by a rollover reports the build tag and version of the binary.
+func TestRolloverHeaderIncludesBuildTag(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer ScopeWithoutShowLogs(t).Close(t)
+	defer build.TestingOverrideTag("v1.2.3-test")()
+
+	debugFileSink := debugLog.getFileSink()
+	defer func(previous int64) { debugFileSink.logFileMaxSize = previous }(debugFileSink.logFileMaxSize)
+	debugFileSink.logFileMaxSize = 2048
+
+	Info(context.Background(), "x") // Be sure we have a file.
+	fname0 := debugFileSink.getFileName(t)
+	// Force a rollover, then create a new file.
+	Infof(context.Background(), "%s", strings.Repeat("x", int(debugFileSink.logFileMaxSize)))
+	Info(context.Background(), "x")
+	Flush()
+
+	fname1 := debugFileSink.getFileName(t)
+	require.NotEqual(t, fname0, fname1)
+	contents, err := ioutil.ReadFile(fname1)
+	require.NoError(t, err)
+	require.Contains(t, string(contents),
+		fmt.Sprintf("build tag: v1.2.3-test, version: %s", build.BinaryVersion()))
+}
+
 // TestFatalStacktraceStderr verifies that a full stacktrace is output.
 // This test would be more interesting if -logtostderr could actually
 // be tested. Well, it wasn't, and it looked like stack trace dumping
@@ -588,6 +702,33 @@ func TestFatalStacktraceStderr(t *testing.T) {
 	}
 }
 
+// TestLastFatalEntry verifies that the fatal entry is available to the
+// listeners of FatalChan() once the channel is closed.
+func TestLastFatalEntry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer ScopeWithoutShowLogs(t).Close(t)
+
+	exited := false
+	SetExitFunc(true /* hideStack */, func(exit.Code) { exited = true })
+
+	Fatalf(context.Background(), "fatal cinap")
+	require.True(t, exited)
+
+	select {
+	case <-FatalChan():
+	default:
+		t.Fatal("fatal channel was not closed")
+	}
+	entry, ok := LastFatalEntry()
+	require.True(t, ok)
+	require.Equal(t, severity.FATAL, entry.Severity)
+	require.Equal(t, channel.DEV, entry.Channel)
+	require.Contains(t, entry.Message, "fatal cinap")
+	require.Contains(t, entry.File, "clog_test.go")
+	// The stack traces are not part of the entry.
+	require.Zero(t, entry.StackTraceStart)
+}
+
 func TestFd2Capture(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	s := ScopeWithoutShowLogs(t)
@@ -806,3 +947,19 @@ func BenchmarkEventf_WithVerboseTraceSpan(b *testing.B) {
 		})
 	}
 }
+
+func TestCurrentEntryCount(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer ScopeWithoutShowLogs(t).Close(t)
+
+	ctx := context.Background()
+	before, ok := CurrentEntryCount(channel.SENSITIVE_ACCESS)
+	require.True(t, ok)
+
+	for i := 0; i < 3; i++ {
+		SensitiveAccess.Infof(ctx, "audit entry %d", i)
+	}
+	after, ok := CurrentEntryCount(channel.SENSITIVE_ACCESS)
+	require.True(t, ok)
+	require.Equal(t, before+3, after)
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+// fatalMirror is the optional append-only file to which every FATAL
+// log entry is copied. Unlike the regular file sinks, this file is
+// never rotated nor garbage collected, and the copy is made
+// regardless of the sink thresholds.
+var fatalMirror struct {
+	syncutil.Mutex
+	f *os.File
+}
+
+// SetFatalMirrorFile configures the file to which FATAL log entries
+// are mirrored. The file is opened in append-only mode and created if
+// it does not exist yet. An empty path disables the mirror.
+//
+// The returned cleanup function closes the file and disables the
+// mirror.
+func SetFatalMirrorFile(path string) (cleanupFn func(), err error) {
+	var f *os.File
+	if path != "" {
+		f, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+		if err != nil {
+			return nil, errors.Wrap(err, "opening FATAL mirror file")
+		}
+	}
+
+	fatalMirror.Lock()
+	prev := fatalMirror.f
+	fatalMirror.f = f
+	fatalMirror.Unlock()
+	if prev != nil {
+		_ = prev.Close()
+	}
+
+	return func() {
+		fatalMirror.Lock()
+		defer fatalMirror.Unlock()
+		if fatalMirror.f == f && f != nil {
+			_ = f.Close()
+			fatalMirror.f = nil
+		}
+	}, nil
+}
+
+// writeFatalMirror appends the given FATAL entry to the mirror file,
+// if one is configured, and syncs it to stable storage.
+//
+// This is called while the process is about to terminate, so it must
+// not recursively call into logging. Errors are thus reported
+// directly to OrigStderr.
+func writeFatalMirror(entry logEntry) {
+	fatalMirror.Lock()
+	defer fatalMirror.Unlock()
+	if fatalMirror.f == nil {
+		return
+	}
+
+	buf := formatCrdbV2{}.formatEntry(entry)
+	defer putBuffer(buf)
+	if _, err := fatalMirror.f.Write(buf.Bytes()); err != nil {
+		fmt.Fprintf(OrigStderr, "log: unable to write FATAL mirror: %v\n", err)
+		return
+	}
+	if err := fatalMirror.f.Sync(); err != nil {
+		fmt.Fprintf(OrigStderr, "log: unable to sync FATAL mirror: %v\n", err)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/exit"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFatalMirror(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	mirrorPath := filepath.Join(s.logDir, "fatal-mirror.log")
+	cleanupFn, err := SetFatalMirrorFile(mirrorPath)
+	require.NoError(t, err)
+	defer cleanupFn()
+
+	// Filter out every entry from the regular sinks, so that the mirror
+	// is the only place where the FATAL entry can be found.
+	for _, si := range debugLog.sinkInfos {
+		si.threshold.set(channel.DEV, severity.NONE)
+	}
+
+	exited := false
+	SetExitFunc(true /* hideStack */, func(exit.Code) { exited = true })
+	defer ResetExitFunc()
+
+	Infof(context.Background(), "not mirrored")
+	Fatalf(context.Background(), "mirrored fatal")
+	require.True(t, exited)
+
+	contents, err := ioutil.ReadFile(mirrorPath)
+	require.NoError(t, err)
+	require.Contains(t, string(contents), "mirrored fatal")
+	require.NotContains(t, string(contents), "not mirrored")
+}
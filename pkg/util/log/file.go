@@ -92,6 +92,16 @@ type fileSink struct {
 
 	filePermissions fs.FileMode
 
+	// compressRotatedFiles, if set, causes log files to be compressed
+	// asynchronously after they are rotated out. Compressed files are
+	// accounted for by the GC, but are not listed by ListLogFiles.
+	compressRotatedFiles bool
+
+	// rotationInterval, if positive, causes log files to be rotated at
+	// every multiple of the interval since the Unix epoch, in addition to
+	// being rotated when they reach logFileMaxSize.
+	rotationInterval time.Duration
+
 	// mu protects the remaining elements of this structure and is
 	// used to synchronize output to this file sink..
 	mu struct {
@@ -137,6 +147,8 @@ func newFileSink(
 	fileMaxSize, combinedMaxSize int64,
 	getStartLines func(time.Time) []*buffer,
 	filePermissions fs.FileMode,
+	compressRotatedFiles bool,
+	rotationInterval time.Duration,
 ) *fileSink {
 	f := &fileSink{
 		groupName:               fileGroupName,
@@ -147,6 +159,8 @@ func newFileSink(
 		gcNotify:                make(chan struct{}, 1),
 		getStartLines:           getStartLines,
 		filePermissions:         filePermissions,
+		compressRotatedFiles:    compressRotatedFiles,
+		rotationInterval:        rotationInterval,
 	}
 	f.mu.logDir = dir
 	f.enabled.Set(dir != "")
@@ -151,6 +151,14 @@ func ListLogFiles() (logFiles []logpb.FileInfo, err error) {
 // sink are ignored. This makes it possible to share directories
 // across multiple sinks.
 func (l *fileSink) listLogFiles() (string, []logpb.FileInfo, error) {
+	return l.listLogFilesInternal(false /* includeCompressed */)
+}
+
+// listLogFilesInternal is like listLogFiles, but additionally
+// includes compressed log files if includeCompressed is set.
+func (l *fileSink) listLogFilesInternal(
+	includeCompressed bool,
+) (string, []logpb.FileInfo, error) {
 	var results []logpb.FileInfo
 	l.mu.Lock()
 	dir := l.mu.logDir
@@ -171,6 +179,9 @@ func (l *fileSink) listLogFiles() (string, []logpb.FileInfo, error) {
 	for _, info := range infos {
 		if info.Mode().IsRegular() {
 			details, err := ParseLogFilename(info.Name())
+			if err != nil && includeCompressed {
+				details, err = parseCompressedLogFilename(info.Name())
+			}
 			if err == nil && l.nameGenerator.ownsFileByPrefix(details.Program) {
 				results = append(results, MakeFileInfo(details, info))
 			}
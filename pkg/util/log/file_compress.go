@@ -0,0 +1,98 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
+	"github.com/cockroachdb/errors"
+)
+
+// compressedLogFileSuffix is appended to the name of a rotated log
+// file once it has been compressed.
+const compressedLogFileSuffix = ".gz"
+
+// parseCompressedLogFilename is like ParseLogFilename, but for the
+// names of compressed log files.
+func parseCompressedLogFilename(filename string) (logpb.FileDetails, error) {
+	if !strings.HasSuffix(filename, compressedLogFileSuffix) {
+		return logpb.FileDetails{}, errMalformedName
+	}
+	return ParseLogFilename(strings.TrimSuffix(filename, compressedLogFileSuffix))
+}
+
+// compressRotatedFile compresses the given log file, which must not
+// be in use for writing any more, and then notifies the GC daemon so
+// that it can account for the (smaller) size of the compressed file.
+//
+// This is meant to run asynchronously after a rotation, so that
+// logging does not block on the compression.
+func (l *fileSink) compressRotatedFile(fileName string) {
+	if err := compressFile(fileName, l.filePermissions); err != nil {
+		fmt.Fprintf(OrigStderr, "log: unable to compress %s: %v\n", fileName, err)
+		return
+	}
+	select {
+	case l.gcNotify <- struct{}{}:
+	default:
+	}
+}
+
+// compressFile writes a gzipped copy of the given file next to it,
+// with the compressedLogFileSuffix suffix, then removes the
+// original.
+//
+// The compressed data is first written to a temporary file that is
+// renamed into place once complete, so that a partially compressed
+// file is never mistaken for a log file.
+func compressFile(fileName string, fileMode fs.FileMode) (err error) {
+	src, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.CombineErrors(err, src.Close()) }()
+
+	compressedName := fileName + compressedLogFileSuffix
+	tmpName := compressedName + ".tmp"
+	dst, err := os.OpenFile(tmpName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, fileMode)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmpName)
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	zw.Name = filepath.Base(fileName)
+	_, err = io.Copy(zw, src)
+	err = errors.CombineErrors(err, zw.Close())
+	if err == nil {
+		err = dst.Sync()
+	}
+	err = errors.CombineErrors(err, dst.Close())
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpName, compressedName); err != nil {
+		return err
+	}
+	return os.Remove(fileName)
+}
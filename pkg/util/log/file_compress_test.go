@@ -0,0 +1,89 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/oserror"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressRotatedFiles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer ScopeWithoutShowLogs(t).Close(t)
+
+	debugFileSink := debugLog.getFileSink()
+	defer func(previous int64) { debugFileSink.logFileMaxSize = previous }(debugFileSink.logFileMaxSize)
+	debugFileSink.logFileMaxSize = 2048
+	defer func(previous bool) { debugFileSink.compressRotatedFiles = previous }(debugFileSink.compressRotatedFiles)
+	debugFileSink.compressRotatedFiles = true
+
+	Info(context.Background(), "before rotation")
+	fname0 := debugFileSink.getFileName(t)
+	// Force a rollover, then create a new file.
+	Infof(context.Background(), "%s", strings.Repeat("x", int(debugFileSink.logFileMaxSize)))
+	Info(context.Background(), "after rotation")
+	Flush()
+
+	// The active file is left uncompressed.
+	fname1 := debugFileSink.getFileName(t)
+	require.NotEqual(t, fname0, fname1)
+	require.False(t, strings.HasSuffix(fname1, compressedLogFileSuffix))
+
+	// The rotated file is replaced by its compressed version
+	// asynchronously.
+	compressedName := fname0 + compressedLogFileSuffix
+	succeedsSoon(t, func() error {
+		if _, err := os.Stat(fname0); !oserror.IsNotExist(err) {
+			return errors.Newf("rotated file %s still exists (err: %v)", fname0, err)
+		}
+		_, err := os.Stat(compressedName)
+		return err
+	})
+
+	f, err := os.Open(compressedName)
+	require.NoError(t, err)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Base(fname0), zr.Name)
+	contents, err := ioutil.ReadAll(zr)
+	require.NoError(t, err)
+	require.NoError(t, zr.Close())
+	require.Contains(t, string(contents), "before rotation")
+	require.NotContains(t, string(contents), "after rotation")
+
+	// The GC accounts for the compressed file, but it is not exposed
+	// through the log file listing API.
+	_, gcFiles, err := debugFileSink.listLogFilesInternal(true /* includeCompressed */)
+	require.NoError(t, err)
+	var found bool
+	for _, fi := range gcFiles {
+		if fi.Name == filepath.Base(compressedName) {
+			found = true
+		}
+	}
+	require.True(t, found, "compressed file not listed for GC: %+v", gcFiles)
+	_, files, err := debugFileSink.listLogFiles()
+	require.NoError(t, err)
+	for _, fi := range files {
+		require.NotEqual(t, filepath.Base(compressedName), fi.Name)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"sync/atomic"
+
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// fileFormats maps the names accepted by SetFileFormat to the formatter
+// they select. The "text" format has no formatter: the entries written to
+// a file sink are then formatted with the format configured for the sink.
+var fileFormats = map[string]logFormatter{
+	"text":   nil,
+	"json":   formatters["json"],
+	"logfmt": formatters["logfmt"],
+}
+
+// fileFormatOverride holds the formatter, if any, used for the entries
+// written to file sinks instead of the format configured for each sink.
+var fileFormatOverride atomic.Value // of fileFormatter
+
+type fileFormatter struct {
+	name string
+	f    logFormatter
+}
+
+// SetFileFormat overrides the format of the entries written to file
+// sinks from now on. The format is one of "text", "json" or "logfmt";
+// "text" restores the format configured for each file sink.
+//
+// If the format changes, the open log files are rotated, so that the
+// header of each file declares the format of the entries it contains.
+func SetFileFormat(format string) error {
+	f, ok := fileFormats[format]
+	if !ok {
+		return errors.Newf("unknown file format %q", format)
+	}
+	prev, ok := fileFormatOverride.Swap(fileFormatter{name: format, f: f}).(fileFormatter)
+	if (!ok && format == "text") || prev.name == format {
+		return nil
+	}
+	return logging.allSinkInfos.iterFileSinks(func(l *fileSink) error {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		sb, ok := l.mu.file.(*syncBuffer)
+		if !ok {
+			// The file is created with the new format on the next write.
+			return nil
+		}
+		return sb.rotateFileLocked(timeutil.Now())
+	})
+}
+
+// formatterForSink returns the formatter to use for the entries written
+// to the given sink.
+func formatterForSink(s *sinkInfo) logFormatter {
+	if _, isFileSink := s.sink.(*fileSink); isFileSink {
+		if o, ok := fileFormatOverride.Load().(fileFormatter); ok && o.f != nil {
+			return o.f
+		}
+	}
+	return s.formatter
+}
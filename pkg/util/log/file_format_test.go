@@ -0,0 +1,89 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetFileFormat(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer ScopeWithoutShowLogs(t).Close(t)
+	defer func() { require.NoError(t, SetFileFormat("text")) }()
+
+	require.Error(t, SetFileFormat("xml"))
+
+	ctx := context.Background()
+	// logLine emits an entry in the given file format and returns the line
+	// written for it to the log file.
+	logLine := func(format string) string {
+		require.NoError(t, SetFileFormat(format))
+		marker := fmt.Sprintf("file format marker %s", format)
+		Info(ctx, marker)
+		Flush()
+		contents, err := os.ReadFile(getDebugLogFileName(t))
+		require.NoError(t, err)
+		for _, line := range strings.Split(string(contents), "\n") {
+			if strings.Contains(line, marker) {
+				return line
+			}
+		}
+		t.Fatalf("entry %q not found in the log file", marker)
+		return ""
+	}
+
+	// requireHeader checks that the header of the current log file declares
+	// the given format.
+	requireHeader := func(formatterName string) {
+		contents, err := os.ReadFile(getDebugLogFileName(t))
+		require.NoError(t, err)
+		for _, line := range strings.Split(string(contents), "\n") {
+			if strings.Contains(line, "log format (utf8=") {
+				require.Contains(t, line, "): "+formatterName)
+				return
+			}
+		}
+		t.Fatal("log format header not found in the log file")
+	}
+
+	// The text format is the one configured for the file sink.
+	require.True(t, strings.HasPrefix(logLine("text"), "I"), "expected a crdb-v2 entry")
+	requireHeader("crdb-v2")
+	textFileName := getDebugLogFileName(t)
+
+	// Changing the format rotates the log file, so that its header matches
+	// the format of its entries.
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(logLine("json")), &entry))
+	require.Contains(t, entry["message"], "file format marker json")
+	requireHeader("json")
+	require.NotEqual(t, textFileName, getDebugLogFileName(t))
+
+	require.True(t, strings.HasPrefix(logLine("logfmt"), "ts="), "expected a logfmt entry")
+	requireHeader("logfmt")
+
+	// Switching back to text affects subsequent entries.
+	require.True(t, strings.HasPrefix(logLine("text"), "I"), "expected a crdb-v2 entry")
+	requireHeader("crdb-v2")
+
+	// Setting the same format again does not rotate the file.
+	fileName := getDebugLogFileName(t)
+	require.NoError(t, SetFileFormat("text"))
+	require.Equal(t, fileName, getDebugLogFileName(t))
+}
@@ -42,8 +42,8 @@ func (l *fileSink) gcDaemon(ctx context.Context) {
 // the configured size and number threshold.
 func (l *fileSink) gcOldFiles() {
 	// This only lists the log files for the current logger (sharing the
-	// prefix).
-	dir, allFiles, err := l.listLogFiles()
+	// prefix). Compressed files count towards the combined size too.
+	dir, allFiles, err := l.listLogFilesInternal(true /* includeCompressed */)
 	if err != nil {
 		fmt.Fprintf(OrigStderr, "unable to GC log files: %s\n", err)
 		return
@@ -31,6 +31,9 @@ type syncBuffer struct {
 	file         *os.File
 	lastRotation int64
 	nbytes       int64 // The number of bytes written to this file so far.
+	// nextRotation is the time at which the file is rotated regardless of
+	// its size, if the sink has a rotation interval.
+	nextRotation time.Time
 }
 
 // Sync implements the flushSyncWriter interface.
@@ -43,8 +46,16 @@ func (sb *syncBuffer) Sync() error {
 
 func (sb *syncBuffer) Write(p []byte) (n int, err error) {
 	maxFileSize := atomic.LoadInt64(&sb.fileSink.logFileMaxSize)
-	if maxFileSize > 0 && sb.nbytes+int64(len(p)) >= maxFileSize {
-		if err := sb.rotateFileLocked(timeutil.Now()); err != nil {
+	rotate := maxFileSize > 0 && sb.nbytes+int64(len(p)) >= maxFileSize
+	var now time.Time
+	if rotate || !sb.nextRotation.IsZero() {
+		now = timeutil.Now()
+		// Whichever of the size and the time triggers comes first rotates
+		// the file.
+		rotate = rotate || !now.Before(sb.nextRotation)
+	}
+	if rotate {
+		if err := sb.rotateFileLocked(now); err != nil {
 			return 0, err
 		}
 	}
@@ -53,6 +64,18 @@ func (sb *syncBuffer) Write(p []byte) (n int, err error) {
 	return n, err
 }
 
+// nextRotationTime returns the first multiple of the given rotation
+// interval since the Unix epoch which is after now, or the zero time if
+// the interval is not positive. For instance, with an interval of 24h,
+// this is the next midnight UTC.
+func nextRotationTime(now time.Time, interval time.Duration) time.Time {
+	if interval <= 0 {
+		return time.Time{}
+	}
+	elapsed := now.Sub(timeutil.Unix(0, 0))
+	return timeutil.Unix(0, 0).Add(elapsed - elapsed%interval + interval)
+}
+
 // writeToFileLocked writes to the file and applies the synchronization policy.
 // Assumes that l.mu is held by the caller.
 func (l *fileSink) writeToFileLocked(data []byte) error {
@@ -200,6 +223,7 @@ func (sb *syncBuffer) rotateFileLocked(now time.Time) (err error) {
 	// At this point we're committed to the new file.
 	switchOverDone = true
 	sb.file, sb.Writer, sb.nbytes, sb.lastRotation = newFile, newWriter, nbytes, newLastRotation
+	sb.nextRotation = nextRotationTime(now, sb.fileSink.rotationInterval)
 
 	// Now close the old file if any.
 	if oldFile != nil {
@@ -207,6 +231,9 @@ func (sb *syncBuffer) rotateFileLocked(now time.Time) (err error) {
 			// Ooof. We are likely leaking a file descriptor.
 			return errors.Wrap(err, "log: unable to close previous file")
 		}
+		if sb.fileSink.compressRotatedFiles {
+			go sb.fileSink.compressRotatedFile(oldFile.Name())
+		}
 	}
 
 	// And create a symlink to the new file (best effort).
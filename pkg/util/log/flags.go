@@ -15,6 +15,8 @@ import (
 	"fmt"
 	"io/fs"
 	"math"
+	"sort"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/cli/exit"
 	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
@@ -69,7 +71,10 @@ func init() {
 // taken from the main logger.
 //
 // This is used to assert that configuration is performed
-// before logging has been used for the first time.
+// before logging has been used for the first time. It is safe for
+// concurrent use, so that startup code can check it before attempting
+// to apply a new configuration, which ApplyConfig rejects once logging
+// is active.
 func IsActive() (active bool, firstUse string) {
 	logging.mu.Lock()
 	defer logging.mu.Unlock()
@@ -155,6 +160,7 @@ func ApplyConfig(config logconfig.Config) (resFn func(), err error) {
 		mf := logconfig.ByteSize(math.MaxInt64)
 		f := logconfig.DefaultFileFormat
 		fm := logconfig.FilePermissions(0o644)
+		zd := time.Duration(0)
 		fakeConfig := logconfig.FileSinkConfig{
 			FileDefaults: logconfig.FileDefaults{
 				CommonSinkConfig: logconfig.CommonSinkConfig{
@@ -168,11 +174,13 @@ func ApplyConfig(config logconfig.Config) (resFn func(), err error) {
 					// impression to the entry parser.
 					Redactable: &bf,
 				},
-				Dir:             config.CaptureFd2.Dir,
-				MaxGroupSize:    config.CaptureFd2.MaxGroupSize,
-				MaxFileSize:     &mf,
-				BufferedWrites:  &bf,
-				FilePermissions: &fm,
+				Dir:                  config.CaptureFd2.Dir,
+				MaxGroupSize:         config.CaptureFd2.MaxGroupSize,
+				MaxFileSize:          &mf,
+				BufferedWrites:       &bf,
+				CompressRotatedFiles: &bf,
+				RotationInterval:     &zd,
+				FilePermissions:      &fm,
 			},
 			Channels: logconfig.SelectChannels(channel.DEV),
 		}
@@ -277,11 +285,22 @@ func ApplyConfig(config logconfig.Config) (resFn func(), err error) {
 		}
 	}
 
-	// Create the file sinks.
-	for fileGroupName, fc := range config.Sinks.FileGroups {
+	// Create the file sinks. The file groups are attached in the
+	// lexicographic order of their names, so that the order of the file
+	// sinks of a channel which is routed to multiple file groups is
+	// stable. The first of them is the primary file sink of the channel;
+	// see outputLogEntry().
+	fileGroupNames := make([]string, 0, len(config.Sinks.FileGroups))
+	for fileGroupName := range config.Sinks.FileGroups {
+		fileGroupNames = append(fileGroupNames, fileGroupName)
+	}
+	sort.Strings(fileGroupNames)
+	for _, fileGroupName := range fileGroupNames {
+		fc := config.Sinks.FileGroups[fileGroupName]
 		if fc.Filter == severity.NONE || fc.Dir == nil {
 			continue
 		}
+		sinkName := "file-groups." + fileGroupName
 		if fileGroupName == "default" {
 			fileGroupName = ""
 		}
@@ -289,7 +308,7 @@ func ApplyConfig(config logconfig.Config) (resFn func(), err error) {
 		if err != nil {
 			return nil, err
 		}
-		attachBufferWrapper(secLoggersCtx, fileSinkInfo, fc.CommonSinkConfig)
+		attachBufferWrapper(secLoggersCtx, sinkName, fileSinkInfo, fc.CommonSinkConfig)
 		attachSinkInfo(fileSinkInfo, &fc.Channels)
 
 		// Start the GC process. This ensures that old capture files get
@@ -298,7 +317,7 @@ func ApplyConfig(config logconfig.Config) (resFn func(), err error) {
 	}
 
 	// Create the fluent sinks.
-	for _, fc := range config.Sinks.FluentServers {
+	for sinkName, fc := range config.Sinks.FluentServers {
 		if fc.Filter == severity.NONE {
 			continue
 		}
@@ -306,12 +325,12 @@ func ApplyConfig(config logconfig.Config) (resFn func(), err error) {
 		if err != nil {
 			return nil, err
 		}
-		attachBufferWrapper(secLoggersCtx, fluentSinkInfo, fc.CommonSinkConfig)
+		attachBufferWrapper(secLoggersCtx, "fluent-servers."+sinkName, fluentSinkInfo, fc.CommonSinkConfig)
 		attachSinkInfo(fluentSinkInfo, &fc.Channels)
 	}
 
 	// Create the HTTP sinks.
-	for _, fc := range config.Sinks.HTTPServers {
+	for sinkName, fc := range config.Sinks.HTTPServers {
 		if fc.Filter == severity.NONE {
 			continue
 		}
@@ -319,16 +338,15 @@ func ApplyConfig(config logconfig.Config) (resFn func(), err error) {
 		if err != nil {
 			return nil, err
 		}
-		attachBufferWrapper(secLoggersCtx, httpSinkInfo, fc.CommonSinkConfig)
+		attachBufferWrapper(secLoggersCtx, "http-servers."+sinkName, httpSinkInfo, fc.CommonSinkConfig)
 		attachSinkInfo(httpSinkInfo, &fc.Channels)
 	}
 
 	// Prepend the interceptor sink to all channels.
 	// We prepend it because we want the interceptors
 	// to see every event before they make their way to disk/network.
-	interceptorSinkInfo := logging.newInterceptorSinkInfo()
-	for _, l := range chans {
-		l.sinkInfos = append([]*sinkInfo{interceptorSinkInfo}, l.sinkInfos...)
+	for ch, l := range chans {
+		l.sinkInfos = append([]*sinkInfo{logging.newInterceptorSinkInfo(ch)}, l.sinkInfos...)
 	}
 
 	logging.setChannelLoggers(chans, &stderrSinkInfo)
@@ -355,6 +373,8 @@ func newFileSinkInfo(
 		int64(*c.MaxGroupSize),
 		info.getStartLines,
 		fs.FileMode(*c.FilePermissions),
+		*c.CompressRotatedFiles,
+		*c.RotationInterval,
 	)
 	info.sink = fileSink
 	return info, fileSink, nil
@@ -401,7 +421,9 @@ func (l *sinkInfo) applyFilters(chs logconfig.ChannelFilters) {
 	}
 }
 
-func attachBufferWrapper(ctx context.Context, s *sinkInfo, c logconfig.CommonSinkConfig) {
+func attachBufferWrapper(
+	ctx context.Context, name string, s *sinkInfo, c logconfig.CommonSinkConfig,
+) {
 	b := c.Buffering
 	if b.IsNone() {
 		return
@@ -432,7 +454,7 @@ func attachBufferWrapper(ctx context.Context, s *sinkInfo, c logconfig.CommonSin
 			}
 		}
 	}
-	s.sink = newBufferSink(ctx, s.sink, *b.MaxStaleness, int(*b.FlushTriggerSize), int32(*b.MaxInFlight), errCallback)
+	s.sink = newBufferSink(ctx, name, s.sink, *b.MaxStaleness, int(*b.FlushTriggerSize), int32(*b.MaxInFlight), errCallback)
 }
 
 // applyConfig applies a common sink configuration to a sinkInfo.
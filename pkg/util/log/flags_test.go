@@ -11,6 +11,7 @@
 package log
 
 import (
+	"context"
 	"strings"
 	"testing"
 
@@ -18,8 +19,23 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
 	"github.com/cockroachdb/datadriven"
 	"github.com/pmezard/go-difflib/difflib"
+	"github.com/stretchr/testify/require"
 )
 
+func TestIsActive(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer Scope(t).Close(t)
+
+	TestingResetActive()
+	active, _ := IsActive()
+	require.False(t, active)
+
+	Infof(context.Background(), "hello")
+	active, firstUse := IsActive()
+	require.True(t, active)
+	require.NotEmpty(t, firstUse)
+}
+
 func TestAppliedStandaloneConfig(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
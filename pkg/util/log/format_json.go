@@ -14,6 +14,7 @@ import (
 	"bytes"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/util/jsonbytes"
@@ -132,6 +133,8 @@ Additionally, the following fields are conditionally present:
 	}
 
 	buf.WriteString(`| ` + "`tags`" + `    | The logging context tags for the entry, if there were context tags. |
+| ` + "`trace_id`" + ` | The ID of the trace active on the context of the entry, if any. |
+| ` + "`span_id`" + ` | The ID of the trace span active on the context of the entry, if any. |
 | ` + "`message`" + ` | For unstructured events, the flat text payload. |
 | ` + "`event`" + `   | The logging event, if structured (see below for details). |
 | ` + "`stacks`" + `  | Goroutine stacks, for fatal events. |
@@ -383,6 +386,16 @@ func formatJSON(entry logEntry, forFluent bool, tags tagChoice) *buffer {
 		buf.WriteByte('}')
 	}
 
+	// Trace and span IDs. Like the timestamp, they are enclosed in double
+	// quotes because their precision exceeds json's native float precision.
+	if entry.traceID != 0 {
+		buf.WriteString(`,"trace_id":"`)
+		buf.Write(strconv.AppendUint(buf.tmp[:0], entry.traceID, 10))
+		buf.WriteString(`","span_id":"`)
+		buf.Write(strconv.AppendUint(buf.tmp[:0], entry.spanID, 10))
+		buf.WriteByte('"')
+	}
+
 	if entry.structured {
 		buf.WriteString(`,"event":{`)
 		buf.WriteString(entry.payload.message) // Already JSON.
@@ -13,7 +13,9 @@ package log
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"testing"
 	"time"
 
@@ -21,8 +23,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
 	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/datadriven"
 	"github.com/cockroachdb/logtags"
+	"github.com/stretchr/testify/require"
 )
 
 func TestJSONFormats(t *testing.T) {
@@ -91,3 +95,35 @@ func TestJSONFormats(t *testing.T) {
 	})
 
 }
+
+func TestJSONFormatTraceIDs(t *testing.T) {
+	tr := tracing.NewTracer()
+	sp := tr.StartSpan("s", tracing.WithForceRealSpan())
+	defer sp.Finish()
+	tracedCtx := tracing.ContextWithSpan(context.Background(), sp)
+
+	type jsonEntry struct {
+		Message string `json:"message"`
+		TraceID string `json:"trace_id"`
+		SpanID  string `json:"span_id"`
+	}
+	format := func(ctx context.Context) (e jsonEntry) {
+		entry := makeUnstructuredEntry(ctx, severity.INFO, channel.DEV, 0, true, "hello")
+		buf := formatJSONFull{}.formatEntry(entry)
+		defer putBuffer(buf)
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &e))
+		return e
+	}
+
+	// An entry emitted with a traced context carries the IDs of its span.
+	e := format(tracedCtx)
+	require.Equal(t, "hello", e.Message)
+	require.Equal(t, strconv.FormatUint(uint64(sp.TraceID()), 10), e.TraceID)
+	require.Equal(t, strconv.FormatUint(uint64(sp.SpanID()), 10), e.SpanID)
+	require.NotEqual(t, "0", e.TraceID)
+
+	// Without a span, no IDs are reported.
+	e = format(context.Background())
+	require.Empty(t, e.TraceID)
+	require.Empty(t, e.SpanID)
+}
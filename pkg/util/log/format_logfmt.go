@@ -0,0 +1,174 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cockroachdb/redact"
+)
+
+type formatLogfmt struct{}
+
+func (formatLogfmt) formatterName() string { return "logfmt" }
+
+func (formatLogfmt) formatEntry(entry logEntry) *buffer { return formatLogfmtEntry(entry) }
+
+func (formatLogfmt) contentType() string { return "text/plain" }
+
+func (formatLogfmt) doc() string {
+	return `This format emits log entries as a single line of space-separated
+` + "`key=value`" + ` pairs, as understood by logfmt processing tools.
+
+Values containing spaces, equal signs, double quotes or special
+characters are enclosed in double quotes, with Go escaping rules. The
+entry as a whole is followed by a newline character.
+
+Each entry contains at least the following fields:
+
+| Field | Description |
+|-------|-------------|
+| ` + "`ts`" + ` | The timestamp at which the event was emitted on the logging channel, in RFC 3339 format. |
+| ` + "`sev`" + ` | The severity of the event. |
+| ` + "`ch`" + ` | The name of the logging channel where the event was sent. |
+| ` + "`goroutine`" + ` | The identifier of the goroutine where the event was emitted. |
+| ` + "`file`" + ` | The name of the source file where the event was emitted. |
+| ` + "`line`" + ` | The line number where the event was emitted in the source. |
+| ` + "`counter`" + ` | The entry number on this logging sink, relative to the last process restart. |
+| ` + "`redactable`" + ` | Whether the payload is redactable (see below for details). |
+
+Header entries, at the beginning of each log sink, contain ` + "`header=1`" + `
+instead of the ` + "`sev`, `ch` and `counter`" + ` fields.
+
+Additionally, the following fields are conditionally present:
+
+| Field | Description |
+|-------|-------------|
+| ` + "`cluster_id`" + ` | The cluster ID where the event was generated, once known. |
+| ` + "`node_id`" + ` | The node ID where the event was generated, once known. |
+| ` + "`tenant_id`" + ` | The SQL tenant ID where the event was generated, once known. |
+| ` + "`instance_id`" + ` | The SQL instance ID where the event was generated, once known. |
+| ` + "`version`" + ` | The binary version with which the event was generated. |
+| ` + "`msg`" + ` | For unstructured events, the flat text payload. |
+| ` + "`event`" + ` | For structured events, the JSON payload of the event. |
+| ` + "`stacks`" + ` | Goroutine stacks, for fatal events. |
+
+The logging context tags of the entry, if any, follow as additional
+` + "`key=value`" + ` pairs, after the payload. Tags without a value are
+emitted as a bare key.
+
+When the entry is marked as ` + "`redactable`" + `, the tags and the payload
+contain delimiters (` + string(redact.StartMarker()) + "..." + string(redact.EndMarker()) + `) around
+fields that are considered sensitive.
+`
+}
+
+func formatLogfmtEntry(entry logEntry) *buffer {
+	buf := getBuffer()
+	buf.WriteString("ts=")
+	buf.WriteString(time.Unix(0, entry.ts).UTC().Format(time.RFC3339Nano))
+	if entry.header {
+		buf.WriteString(" header=1")
+	} else {
+		buf.WriteString(" sev=")
+		buf.WriteString(entry.sev.String())
+		buf.WriteString(" ch=")
+		buf.WriteString(entry.ch.String())
+	}
+
+	// Server identifiers.
+	writeLogfmtField(buf, "cluster_id", entry.clusterID)
+	writeLogfmtField(buf, "node_id", entry.nodeID)
+	writeLogfmtField(buf, "tenant_id", entry.tenantID)
+	writeLogfmtField(buf, "instance_id", entry.sqlInstanceID)
+	writeLogfmtField(buf, "version", entry.version)
+
+	buf.WriteString(" goroutine=")
+	n := buf.someDigits(0, int(entry.gid))
+	buf.Write(buf.tmp[:n])
+	writeLogfmtField(buf, "file", entry.file)
+	buf.WriteString(" line=")
+	n = buf.someDigits(0, entry.line)
+	buf.Write(buf.tmp[:n])
+	if !entry.header {
+		buf.WriteString(" counter=")
+		n = buf.someDigits(0, int(entry.counter))
+		buf.Write(buf.tmp[:n])
+	}
+	if entry.payload.redactable {
+		buf.WriteString(" redactable=1")
+	} else {
+		buf.WriteString(" redactable=0")
+	}
+
+	if entry.structured {
+		// The payload is the inside of a JSON object.
+		writeLogfmtValue(buf, "event", "{"+entry.payload.message+"}")
+	} else {
+		writeLogfmtValue(buf, "msg", entry.payload.message)
+	}
+
+	// Tags.
+	fi := formattableTagsIterator{tags: []byte(entry.payload.tags)}
+	for {
+		key, val, done := fi.next()
+		if done {
+			break
+		}
+		buf.WriteByte(' ')
+		writeLogfmtString(buf, string(key))
+		if len(val) > 0 {
+			buf.WriteByte('=')
+			writeLogfmtString(buf, string(val))
+		}
+	}
+
+	// Stacks.
+	if len(entry.stacks) > 0 {
+		writeLogfmtValue(buf, "stacks", string(entry.stacks))
+	}
+	buf.WriteByte('\n')
+	return buf
+}
+
+// writeLogfmtField emits the given key=value pair, unless the value
+// is empty.
+func writeLogfmtField(buf *buffer, key, val string) {
+	if val == "" {
+		return
+	}
+	writeLogfmtValue(buf, key, val)
+}
+
+// writeLogfmtValue emits the given key=value pair, quoting the value
+// if needed.
+func writeLogfmtValue(buf *buffer, key, val string) {
+	buf.WriteByte(' ')
+	buf.WriteString(key)
+	buf.WriteByte('=')
+	writeLogfmtString(buf, val)
+}
+
+// writeLogfmtString emits the string, enclosed in double quotes if it
+// is empty or contains characters which would make it ambiguous.
+// Printable Unicode characters, including the redaction markers, are
+// preserved as-is.
+func writeLogfmtString(buf *buffer, s string) {
+	if s != "" && utf8.ValidString(s) && !strings.ContainsAny(s, " =\"\\") &&
+		strings.IndexFunc(s, func(r rune) bool { return !strconv.IsPrint(r) }) == -1 {
+		buf.WriteString(s)
+		return
+	}
+	buf.WriteString(strconv.Quote(s))
+}
@@ -0,0 +1,113 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/cockroachdb/logtags"
+	"github.com/cockroachdb/redact"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogfmtFormat(t *testing.T) {
+	tm, err := time.Parse(MessageTimeFormat, "060102 15:04:05.654321")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	ctx = logtags.AddTag(ctx, "noval", nil)
+	ctx = logtags.AddTag(ctx, "s", redact.Safe("1"))
+	ctx = logtags.AddTag(ctx, "long", "2 3")
+
+	entry := makeUnstructuredEntry(ctx, severity.WARNING, channel.OPS, 0, true, "hello %s", `"world"`)
+	// Override the non-deterministic fields to stabilize the output.
+	entry.idPayload = idPayload{nodeID: "1"}
+	entry.ts = tm.UnixNano()
+	entry.version = "v999.0.0"
+	entry.file = "foo.go"
+	entry.line = 123
+	entry.gid = 11
+	entry.counter = 3
+
+	b := formatLogfmt{}.formatEntry(entry)
+	line := b.String()
+	putBuffer(b)
+	const expected = `ts=2006-01-02T15:04:05.654321Z sev=WARNING ch=OPS node_id=1 version=v999.0.0 ` +
+		`goroutine=11 file=foo.go line=123 counter=3 redactable=1 ` +
+		`msg="hello ‹\"world\"›" noval s=1 long="‹2 3›"` + "\n"
+	require.Equal(t, expected, line)
+
+	// The line parses back into the fields of the entry, with the
+	// redaction markers preserved.
+	fields := parseLogfmt(t, strings.TrimSuffix(line, "\n"))
+	require.Equal(t, map[string]string{
+		"ts":         "2006-01-02T15:04:05.654321Z",
+		"sev":        "WARNING",
+		"ch":         "OPS",
+		"node_id":    "1",
+		"version":    "v999.0.0",
+		"goroutine":  "11",
+		"file":       "foo.go",
+		"line":       "123",
+		"counter":    "3",
+		"redactable": "1",
+		"msg":        `hello ‹"world"›`,
+		"noval":      "",
+		"s":          "1",
+		"long":       "‹2 3›",
+	}, fields)
+	ts, err := time.Parse(time.RFC3339Nano, fields["ts"])
+	require.NoError(t, err)
+	require.Equal(t, entry.ts, ts.UnixNano())
+	require.Equal(t, entry.payload.message, fields["msg"])
+}
+
+// parseLogfmt splits a logfmt line into its key=value pairs. Bare keys
+// map to an empty value.
+func parseLogfmt(t *testing.T, line string) map[string]string {
+	fields := make(map[string]string)
+	for len(line) > 0 {
+		line = strings.TrimLeft(line, " ")
+		end := strings.IndexAny(line, " =")
+		if end == -1 {
+			end = len(line)
+		}
+		key := line[:end]
+		line = line[end:]
+		if !strings.HasPrefix(line, "=") {
+			fields[key] = ""
+			continue
+		}
+		line = line[1:]
+		if strings.HasPrefix(line, `"`) {
+			quoted, err := strconv.QuotedPrefix(line)
+			require.NoError(t, err)
+			val, err := strconv.Unquote(quoted)
+			require.NoError(t, err)
+			fields[key] = val
+			line = line[len(quoted):]
+			continue
+		}
+		end = strings.IndexByte(line, ' ')
+		if end == -1 {
+			end = len(line)
+		}
+		fields[key] = line[:end]
+		line = line[end:]
+	}
+	return fields
+}
@@ -34,6 +34,7 @@ var formatParsers = map[string]string{
 	"json-compact":        "json",
 	"json-fluent":         "json",
 	"json-fluent-compact": "json",
+	"logfmt":              "logfmt",
 }
 
 var formatters = func() map[string]logFormatter {
@@ -51,6 +52,7 @@ var formatters = func() map[string]logFormatter {
 	r(formatFluentJSONFull{})
 	r(formatJSONCompact{})
 	r(formatJSONFull{})
+	r(formatLogfmt{})
 	return m
 }()
 
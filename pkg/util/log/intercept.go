@@ -11,14 +11,21 @@
 package log
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
+	"sync"
 	"sync/atomic"
+	"unicode"
 
 	"github.com/cockroachdb/cockroach/pkg/cli/exit"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
 	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/redact"
 )
 
 // InterceptWith diverts log traffic to the given interceptor `fn`.
@@ -40,6 +47,189 @@ func InterceptWith(ctx context.Context, fn Interceptor) func() {
 	}
 }
 
+// InterceptChannel is like InterceptWith, but only diverts the log
+// traffic emitted on channel `ch` to the given interceptor `fn`.
+// Entries logged on other channels are never formatted for, nor
+// delivered to, `fn`.
+//
+// The returned function should be called to cancel the interception.
+func InterceptChannel(ctx context.Context, ch Channel, fn Interceptor) func() {
+	InfofDepth(ctx, 1, "starting log interception on channel %s", ch)
+	logging.interceptor.addForChannel(ch, fn)
+	return func() {
+		logging.interceptor.delForChannel(ch, fn)
+		InfofDepth(ctx, 1, "stopping log interception on channel %s", ch)
+	}
+}
+
+// InterceptWithDualForm is like InterceptWith, but serves each log
+// entry to `fn` in two forms: the raw form, which is the one served
+// to the interceptors configured via InterceptWith() and retains the
+// sensitive data enclosed in redaction markers, and the redacted
+// form, in which the sensitive data is replaced by the redaction
+// marker. This enables e.g. an interceptor to process the raw entry
+// internally while only ever sending the redacted entry out.
+//
+// The redacted form is only computed while such an interceptor is
+// configured.
+//
+// The returned function should be called to cancel the interception.
+func InterceptWithDualForm(ctx context.Context, fn DualFormInterceptor) func() {
+	InfofDepth(ctx, 1, "starting dual-form log interception")
+	logging.interceptor.addDualForm(fn)
+	return func() {
+		logging.interceptor.delDualForm(fn)
+		InfofDepth(ctx, 1, "stopping dual-form log interception")
+	}
+}
+
+// InterceptMatching is like InterceptWith, but only serves to `fn`
+// the log entries whose message matches `re`. The message is matched
+// in its redacted form, so that `fn` can only be selected for entries
+// by their non-sensitive content. The entries served to `fn` are in
+// the raw form, like for InterceptWith.
+//
+// The message of each entry is only extracted and redacted, once for
+// all such interceptors, while one is configured.
+//
+// The returned function should be called to cancel the interception.
+func InterceptMatching(ctx context.Context, re *regexp.Regexp, fn Interceptor) func() {
+	InfofDepth(ctx, 1, "starting log interception of entries matching %q", re)
+	m := &matchingInterceptor{re: re, fn: fn}
+	logging.interceptor.addMatching(m)
+	return func() {
+		logging.interceptor.delMatching(m)
+		InfofDepth(ctx, 1, "stopping log interception of entries matching %q", re)
+	}
+}
+
+// matchingInterceptor is an interceptor configured via
+// InterceptMatching().
+type matchingInterceptor struct {
+	re *regexp.Regexp
+	fn Interceptor
+}
+
+// InterceptorActive returns whether any interceptor is currently
+// configured, including interceptors scoped to a single channel.
+func InterceptorActive() bool {
+	return InterceptorCount() > 0
+}
+
+// InterceptorCount returns the number of interceptors currently
+// configured, including interceptors scoped to a single channel.
+func InterceptorCount() int {
+	return logging.interceptor.count()
+}
+
+// InterceptAsync is like InterceptWith, but decouples `fn` from the
+// logging hot path: a copy of each log entry is queued onto a buffer
+// of bufSize entries, which is drained by a background goroutine
+// invoking `fn.Intercept()`. This is suitable for interceptors which
+// perform blocking operations, e.g. network I/O. Entries are dropped,
+// and counted, when the buffer is full.
+//
+// The returned AsyncInterception must be closed to cancel the
+// interception.
+func InterceptAsync(ctx context.Context, bufSize int, fn Interceptor) *AsyncInterception {
+	a := &AsyncInterception{
+		fn:   fn,
+		ch:   make(chan []byte, bufSize),
+		done: make(chan struct{}),
+	}
+	go a.run()
+	a.cleanup = InterceptWith(ctx, a)
+	return a
+}
+
+// AsyncInterception is the handle of an interception configured via
+// InterceptAsync().
+type AsyncInterception struct {
+	fn      Interceptor
+	ch      chan []byte
+	done    chan struct{}
+	cleanup func()
+	// dropped is the number of entries dropped because the buffer was
+	// full. Accessed atomically.
+	dropped   int64
+	closeOnce sync.Once
+}
+
+var _ Interceptor = (*AsyncInterception)(nil)
+
+// Intercept implements the Interceptor interface. It queues a copy of
+// the entry for the background goroutine, or drops it if the buffer
+// is full.
+func (a *AsyncInterception) Intercept(entry []byte) {
+	select {
+	case a.ch <- append([]byte(nil), entry...):
+	default:
+		atomic.AddInt64(&a.dropped, 1)
+	}
+}
+
+func (a *AsyncInterception) run() {
+	defer close(a.done)
+	for entry := range a.ch {
+		a.fn.Intercept(entry)
+	}
+}
+
+// Dropped returns the number of entries which were dropped because
+// the buffer was full.
+func (a *AsyncInterception) Dropped() int64 {
+	return atomic.LoadInt64(&a.dropped)
+}
+
+// Close cancels the interception. It waits until the entries queued
+// so far have been delivered to the interceptor.
+func (a *AsyncInterception) Close() {
+	a.closeOnce.Do(func() {
+		// Once the interceptor is removed, no more entries can be
+		// queued, so it is safe to close the channel.
+		a.cleanup()
+		close(a.ch)
+		<-a.done
+	})
+}
+
+// TeeToBuffer copies the log entries into the returned buffer, while
+// the log sinks keep receiving them as usual. This enables a test to
+// assert on the log output and still exercise e.g. the file sinks
+// configured by a TestLogScope.
+//
+// The returned function should be called to stop copying entries.
+func TeeToBuffer() (*SyncBuffer, func()) {
+	b := &SyncBuffer{}
+	logging.interceptor.add(b)
+	return b, func() { logging.interceptor.del(b) }
+}
+
+// SyncBuffer is a buffer which is safe for concurrent use, and which
+// accumulates the log entries copied by TeeToBuffer(), in JSON
+// format, one per line.
+type SyncBuffer struct {
+	mu  syncutil.Mutex
+	buf bytes.Buffer
+}
+
+var _ Interceptor = (*SyncBuffer)(nil)
+
+// Intercept implements the Interceptor interface.
+func (b *SyncBuffer) Intercept(entry []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Write(entry)
+	b.buf.WriteByte('\n')
+}
+
+// String returns the entries accumulated so far.
+func (b *SyncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
 // Interceptor is the type of an object that can be passed to
 // InterceptWith().
 type Interceptor interface {
@@ -55,9 +245,22 @@ type Interceptor interface {
 	Intercept(entry []byte)
 }
 
-func (l *loggingT) newInterceptorSinkInfo() *sinkInfo {
+// DualFormInterceptor is the type of an object that can be passed to
+// InterceptWithDualForm().
+type DualFormInterceptor interface {
+	// InterceptDualForm is passed each log entry, in JSON format like
+	// for Interceptor.Intercept(), in its raw and redacted forms.
+	//
+	// As for Interceptor.Intercept(), the argument byte buffers are only
+	// valid during the call to InterceptDualForm().
+	InterceptDualForm(rawEntry, redactedEntry []byte)
+}
+
+// newInterceptorSinkInfo creates the sinkInfo through which the
+// interceptors see the entries logged on channel ch.
+func (l *loggingT) newInterceptorSinkInfo(ch Channel) *sinkInfo {
 	si := &sinkInfo{
-		sink:       &l.interceptor,
+		sink:       &channelInterceptorSink{interceptor: &l.interceptor, ch: ch},
 		editor:     getEditor(WithMarkedSensitiveData),
 		formatter:  formatInterceptor{},
 		redact:     false, // do not redact sensitive information
@@ -85,16 +288,122 @@ func (formatInterceptor) formatEntry(entry logEntry) *buffer {
 	return buf
 }
 
+// redactedInterceptedMessage extracts the message of an entry in the
+// raw form served to the interceptors, in its redacted form. The stack
+// traces, if any, are left out. The message of an entry which is not
+// redactable is unsafe as a whole, and is redacted entirely.
+func redactedInterceptedMessage(rawEntry []byte) (string, error) {
+	var entry struct {
+		Message         string
+		Redactable      bool
+		StackTraceStart uint32 `json:"stack_trace_start"`
+	}
+	if err := json.Unmarshal(rawEntry, &entry); err != nil {
+		return "", err
+	}
+	if !entry.Redactable {
+		return string(redactedMarker), nil
+	}
+	msg := entry.Message
+	if entry.StackTraceStart > 0 {
+		msg = msg[:entry.StackTraceStart-1]
+	}
+	return string(redact.RedactableString(msg).Redact()), nil
+}
+
+// redactInterceptedEntry converts an entry in the raw form served to
+// the interceptors to its redacted form. Like maybeRedactEntry, the
+// message and tag values of an entry which is not redactable are
+// replaced by the redaction marker, as they carry no markers to tell
+// the sensitive parts apart.
+func redactInterceptedEntry(rawEntry []byte) []byte {
+	var entry logpb.Entry
+	if err := json.Unmarshal(rawEntry, &entry); err != nil {
+		return []byte(fmt.Sprintf("unable to redact entry: %v", err))
+	}
+	// The stack traces, if any, are appended to the message after a
+	// newline. They do not contain sensitive data, but their position
+	// changes with the length of the redacted message.
+	msg, stacks := entry.Message, ""
+	if entry.StackTraceStart > 0 {
+		msg, stacks = entry.Message[:entry.StackTraceStart-1], entry.Message[entry.StackTraceStart-1:]
+	}
+	if entry.Redactable {
+		msg = string(redact.RedactableString(msg).Redact())
+		entry.Tags = string(redact.RedactableString(entry.Tags).Redact())
+		if entry.StructuredEnd > 0 {
+			entry.StructuredEnd = uint32(len(msg))
+		}
+	} else {
+		msg = string(redactedMarker)
+		entry.Tags = redactLegacyTagValues(entry.Tags)
+		entry.StructuredStart, entry.StructuredEnd = 0, 0
+		entry.Redactable = true
+	}
+	if entry.StackTraceStart > 0 {
+		entry.StackTraceStart = uint32(len(msg)) + 1
+	}
+	entry.Message = msg + stacks
+	j, err := json.Marshal(entry)
+	if err != nil {
+		return []byte(fmt.Sprintf("unable to format entry: %v", err))
+	}
+	return j
+}
+
+// redactLegacyTagValues replaces the values of the tags of a
+// logpb.Entry, as emitted by formattableTags.formatToBuffer, with the
+// redaction marker. The keys are preserved: the key of a tag is the
+// part before the '=' sign, or the first letter of the tag for
+// 1-letter keys, which are written without it. A value which contains
+// a comma cannot be told apart from the next tag; its fragments are
+// redacted as tags of their own.
+func redactLegacyTagValues(tags string) string {
+	if tags == "" {
+		return ""
+	}
+	parts := strings.Split(tags, ",")
+	for i, part := range parts {
+		if eq := strings.IndexByte(part, '='); eq > 0 {
+			parts[i] = part[:eq+1] + string(redactedMarker)
+		} else if len(part) > 1 && unicode.IsLetter(rune(part[0])) {
+			parts[i] = part[:1] + string(redactedMarker)
+		} else {
+			parts[i] = string(redactedMarker)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
 type interceptorSink struct {
 	// activeCount is the number of functions under the mutex. We keep
 	// it out to avoid locking the mutex in the active() method.
 	activeCount uint32
-	mu          struct {
+	// activeChannelCount is the number of channel-scoped functions under
+	// the mutex, per channel.
+	activeChannelCount [logpb.Channel_CHANNEL_MAX]uint32
+	mu                 struct {
 		syncutil.RWMutex
 
 		// fns is the list of interceptor functions.
 		fns []Interceptor
+		// channelFns is the list of interceptor functions scoped to
+		// each channel.
+		channelFns [logpb.Channel_CHANNEL_MAX][]Interceptor
+		// dualFormFns is the list of interceptor functions which are
+		// served both the raw and redacted forms of the entries. They are
+		// accounted for in activeCount.
+		dualFormFns []DualFormInterceptor
+		// matchingFns is the list of interceptors which are only served
+		// the entries whose redacted message matches their regexp. They
+		// are accounted for in activeCount.
+		matchingFns []*matchingInterceptor
 	}
+	// tailActive is set while the tail buffer retains entries, see
+	// EnableTailBuffer. Accessed atomically.
+	tailActive uint32
+	// tail retains the last entries served to the interceptors.
+	tail tailBuffer
 }
 
 func (i *interceptorSink) add(fn Interceptor) {
@@ -107,27 +416,133 @@ func (i *interceptorSink) add(fn Interceptor) {
 func (i *interceptorSink) del(toDel Interceptor) {
 	i.mu.Lock()
 	defer i.mu.Unlock()
-	for j, fn := range i.mu.fns {
+	i.mu.fns = removeInterceptor(i.mu.fns, toDel)
+	atomic.AddUint32(&i.activeCount, ^uint32(0) /* -1 */)
+}
+
+func (i *interceptorSink) addDualForm(fn DualFormInterceptor) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.mu.dualFormFns = append(i.mu.dualFormFns, fn)
+	atomic.AddUint32(&i.activeCount, 1)
+}
+
+func (i *interceptorSink) delDualForm(toDel DualFormInterceptor) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for j, fn := range i.mu.dualFormFns {
 		if fn == toDel {
-			i.mu.fns = append(i.mu.fns[:j], i.mu.fns[j+1:]...)
+			i.mu.dualFormFns = append(i.mu.dualFormFns[:j], i.mu.dualFormFns[j+1:]...)
+			break
+		}
+	}
+	atomic.AddUint32(&i.activeCount, ^uint32(0) /* -1 */)
+}
+
+func (i *interceptorSink) addMatching(m *matchingInterceptor) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.mu.matchingFns = append(i.mu.matchingFns, m)
+	atomic.AddUint32(&i.activeCount, 1)
+}
+
+func (i *interceptorSink) delMatching(toDel *matchingInterceptor) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for j, m := range i.mu.matchingFns {
+		if m == toDel {
+			i.mu.matchingFns = append(i.mu.matchingFns[:j], i.mu.matchingFns[j+1:]...)
 			break
 		}
 	}
 	atomic.AddUint32(&i.activeCount, ^uint32(0) /* -1 */)
 }
 
-func (i *interceptorSink) active() bool {
-	return atomic.LoadUint32(&i.activeCount) > 0
+func (i *interceptorSink) addForChannel(ch Channel, fn Interceptor) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.mu.channelFns[ch] = append(i.mu.channelFns[ch], fn)
+	atomic.AddUint32(&i.activeChannelCount[ch], 1)
+}
+
+func (i *interceptorSink) delForChannel(ch Channel, toDel Interceptor) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.mu.channelFns[ch] = removeInterceptor(i.mu.channelFns[ch], toDel)
+	atomic.AddUint32(&i.activeChannelCount[ch], ^uint32(0) /* -1 */)
+}
+
+func removeInterceptor(fns []Interceptor, toDel Interceptor) []Interceptor {
+	for j, fn := range fns {
+		if fn == toDel {
+			return append(fns[:j], fns[j+1:]...)
+		}
+	}
+	return fns
+}
+
+// count returns the number of configured interceptors, across all
+// channels.
+func (i *interceptorSink) count() int {
+	n := atomic.LoadUint32(&i.activeCount)
+	for ch := range i.activeChannelCount {
+		n += atomic.LoadUint32(&i.activeChannelCount[ch])
+	}
+	return int(n)
+}
+
+// activeForChannel returns true if any interceptor, or the tail
+// buffer, wants to see the entries logged on channel ch.
+func (i *interceptorSink) activeForChannel(ch Channel) bool {
+	return atomic.LoadUint32(&i.activeCount) > 0 ||
+		atomic.LoadUint32(&i.activeChannelCount[ch]) > 0 ||
+		atomic.LoadUint32(&i.tailActive) > 0
 }
 
-func (i *interceptorSink) output(b []byte, _ sinkOutputOptions) error {
+func (i *interceptorSink) outputForChannel(ch Channel, b []byte) {
 	i.mu.RLock()
 	defer i.mu.RUnlock()
+	if atomic.LoadUint32(&i.tailActive) > 0 {
+		i.tail.add(b)
+	}
 	for _, fn := range i.mu.fns {
 		fn.Intercept(b)
 	}
+	for _, fn := range i.mu.channelFns[ch] {
+		fn.Intercept(b)
+	}
+	if len(i.mu.dualFormFns) > 0 {
+		redacted := redactInterceptedEntry(b)
+		for _, fn := range i.mu.dualFormFns {
+			fn.InterceptDualForm(b, redacted)
+		}
+	}
+	if len(i.mu.matchingFns) > 0 {
+		if msg, err := redactedInterceptedMessage(b); err == nil {
+			for _, m := range i.mu.matchingFns {
+				if m.re.MatchString(msg) {
+					m.fn.Intercept(b)
+				}
+			}
+		}
+	}
+}
+
+// channelInterceptorSink is the logSink that serves the entries
+// logged on a single channel to the interceptors.
+type channelInterceptorSink struct {
+	interceptor *interceptorSink
+	ch          Channel
+}
+
+func (c *channelInterceptorSink) active() bool {
+	return c.interceptor.activeForChannel(c.ch)
+}
+
+func (c *channelInterceptorSink) output(b []byte, _ sinkOutputOptions) error {
+	c.interceptor.outputForChannel(c.ch, b)
 	return nil
 }
 
-func (i *interceptorSink) attachHints(stacks []byte) []byte { return stacks }
-func (i *interceptorSink) exitCode() exit.Code              { return exit.UnspecifiedError() }
+func (c *channelInterceptorSink) attachHints(stacks []byte) []byte { return stacks }
+func (c *channelInterceptorSink) exitCode() exit.Code              { return exit.UnspecifiedError() }
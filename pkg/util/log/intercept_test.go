@@ -12,14 +12,22 @@ package log
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
 	"regexp"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/util/caller"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/logtags"
+	"github.com/cockroachdb/redact"
 	"github.com/stretchr/testify/require"
 )
 
@@ -121,3 +129,267 @@ func TestIntercept(t *testing.T) {
 	second.verifyCaptures(t)
 	empty.verifyCaptures(t)
 }
+
+func TestTeeToBuffer(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer ScopeWithoutShowLogs(t).Close(t)
+
+	ctx := context.Background()
+	buf, cleanup := TeeToBuffer()
+	Info(ctx, "tee to buffer")
+	cleanup()
+	Info(ctx, "no longer teed")
+	Flush()
+
+	// The buffer receives the entry...
+	require.Contains(t, buf.String(), "tee to buffer")
+	require.NotContains(t, buf.String(), "no longer teed")
+	var messages []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry logpb.Entry
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		messages = append(messages, entry.Message)
+	}
+	require.Contains(t, messages, "tee to buffer")
+
+	// ...and so does the file sink.
+	contents, err := os.ReadFile(getDebugLogFileName(t))
+	require.NoError(t, err)
+	require.Contains(t, string(contents), "tee to buffer")
+	require.Contains(t, string(contents), "no longer teed")
+}
+
+func TestInterceptChannel(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer Scope(t).Close(t)
+
+	ctx := context.Background()
+	sqlExec := &captureInterceptor{t: t, re: regexp.MustCompile("hello")}
+	all := &captureInterceptor{t: t, re: regexp.MustCompile("hello")}
+	defer InterceptChannel(ctx, channel.SQL_EXEC, sqlExec)()
+	defer addInterceptor(t, all)()
+
+	SqlExec.Infof(ctx, "hello sql exec")
+	Dev.Infof(ctx, "hello dev")
+	Ops.Infof(ctx, "hello ops")
+
+	sqlExec.Lock()
+	defer sqlExec.Unlock()
+	require.Len(t, sqlExec.messages, 1)
+	require.Contains(t, string(sqlExec.messages[0]), "hello sql exec")
+
+	all.Lock()
+	defer all.Unlock()
+	require.Len(t, all.messages, 3)
+}
+
+func TestInterceptMatching(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer Scope(t).Close(t)
+
+	ctx := context.Background()
+	initial := InterceptorCount()
+	b := &SyncBuffer{}
+	remove := InterceptMatching(ctx, regexp.MustCompile(`^match(ed|ing)`), b)
+	require.Equal(t, initial+1, InterceptorCount())
+
+	Infof(ctx, "matched entry")
+	Infof(ctx, "unrelated entry")
+	// The message is matched in its redacted form.
+	Infof(ctx, "%s", "matching secret")
+	Infof(ctx, "matching %s", "secret")
+	remove()
+	require.Equal(t, initial, InterceptorCount())
+	// Entries logged after the interception is canceled are not served.
+	Infof(ctx, "matched after the interception is canceled")
+
+	var messages []string
+	for _, line := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		var entry logpb.Entry
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		messages = append(messages, entry.Message)
+	}
+	// The entries are served in their raw form.
+	require.Equal(t, []string{"matched entry", "matching ‹secret›"}, messages)
+}
+
+func TestInterceptorCount(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer Scope(t).Close(t)
+
+	ctx := context.Background()
+	initial := InterceptorCount()
+
+	removeAll := InterceptWith(ctx, &captureInterceptor{t: t, re: regexp.MustCompile("no such luck")})
+	require.True(t, InterceptorActive())
+	require.Equal(t, initial+1, InterceptorCount())
+
+	removeChannel := InterceptChannel(ctx, channel.SQL_EXEC, &captureInterceptor{t: t, re: regexp.MustCompile("no such luck")})
+	require.Equal(t, initial+2, InterceptorCount())
+
+	removeAll()
+	require.Equal(t, initial+1, InterceptorCount())
+	require.True(t, InterceptorActive())
+
+	removeChannel()
+	require.Equal(t, initial, InterceptorCount())
+	require.Equal(t, initial > 0, InterceptorActive())
+}
+
+// blockingInterceptor is a captureInterceptor which blocks each
+// delivery until release is closed. started is closed on the first
+// delivery.
+type blockingInterceptor struct {
+	*captureInterceptor
+	started     chan struct{}
+	startedOnce sync.Once
+	release     chan struct{}
+}
+
+func (b *blockingInterceptor) Intercept(message []byte) {
+	b.startedOnce.Do(func() { close(b.started) })
+	<-b.release
+	b.captureInterceptor.Intercept(message)
+}
+
+func TestInterceptAsync(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer Scope(t).Close(t)
+
+	ctx := context.Background()
+	interceptor := &blockingInterceptor{
+		captureInterceptor: &captureInterceptor{t: t, re: regexp.MustCompile("hello")},
+		started:            make(chan struct{}),
+		release:            make(chan struct{}),
+	}
+	a := InterceptAsync(ctx, 2 /* bufSize */, interceptor)
+	defer a.Close()
+
+	// The first entry is dequeued by the background goroutine, which
+	// then blocks in the interceptor.
+	Infof(ctx, "hello 1")
+	<-interceptor.started
+
+	// The next two entries fill the buffer, and the last two are
+	// dropped. None of the logging calls block.
+	for i := 2; i <= 5; i++ {
+		Infof(ctx, "hello %d", redact.Safe(i))
+	}
+	require.Equal(t, int64(2), a.Dropped())
+
+	close(interceptor.release)
+	a.Close()
+
+	interceptor.Lock()
+	defer interceptor.Unlock()
+	require.Len(t, interceptor.messages, 3)
+	for i, m := range interceptor.messages {
+		require.Contains(t, string(m), fmt.Sprintf("hello %d", i+1))
+	}
+}
+
+// dualFormInterceptor captures both forms of the entries which match
+// re.
+type dualFormInterceptor struct {
+	syncutil.Mutex
+	re                   *regexp.Regexp
+	raw, redactedEntries []logpb.Entry
+}
+
+var _ DualFormInterceptor = (*dualFormInterceptor)(nil)
+
+func (d *dualFormInterceptor) InterceptDualForm(rawEntry, redactedEntry []byte) {
+	if !d.re.Match(rawEntry) {
+		return
+	}
+	var raw, redacted logpb.Entry
+	if err := json.Unmarshal(rawEntry, &raw); err != nil {
+		panic(err)
+	}
+	if err := json.Unmarshal(redactedEntry, &redacted); err != nil {
+		panic(err)
+	}
+	d.Lock()
+	defer d.Unlock()
+	d.raw = append(d.raw, raw)
+	d.redactedEntries = append(d.redactedEntries, redacted)
+}
+
+func TestInterceptWithDualForm(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer Scope(t).Close(t)
+
+	ctx := context.Background()
+	initial := InterceptorCount()
+	d := &dualFormInterceptor{re: regexp.MustCompile("dual form")}
+	remove := InterceptWithDualForm(ctx, d)
+	require.Equal(t, initial+1, InterceptorCount())
+
+	ctx = logtags.AddTag(ctx, "user", "bob")
+	Infof(ctx, "dual form: %s, %s", "secret", redact.Safe("public"))
+	remove()
+	require.Equal(t, initial, InterceptorCount())
+	// Entries logged after the interception is canceled are not served.
+	Infof(ctx, "dual form: %s", "later")
+
+	d.Lock()
+	defer d.Unlock()
+	require.Len(t, d.raw, 1)
+	require.Len(t, d.redactedEntries, 1)
+
+	raw, redacted := d.raw[0], d.redactedEntries[0]
+	require.True(t, raw.Redactable)
+	require.Equal(t, "dual form: ‹secret›, public", raw.Message)
+	require.Equal(t, "user=‹bob›", raw.Tags)
+
+	require.True(t, redacted.Redactable)
+	require.Equal(t, "dual form: ‹×›, public", redacted.Message)
+	require.Equal(t, "user=‹×›", redacted.Tags)
+	// Apart from the redacted fields, both forms are identical.
+	redacted.Message, redacted.Tags = raw.Message, raw.Tags
+	require.Equal(t, raw, redacted)
+}
+
+func TestRedactInterceptedEntryStacks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	msg := "boom: " + string(redact.StartMarker()) + "secret" + string(redact.EndMarker())
+	raw := logpb.Entry{
+		Redactable:      true,
+		Message:         msg + "\ngoroutine 1 [running]:",
+		StackTraceStart: uint32(len(msg)) + 1,
+	}
+	j, err := json.Marshal(raw)
+	require.NoError(t, err)
+
+	var redacted logpb.Entry
+	require.NoError(t, json.Unmarshal(redactInterceptedEntry(j), &redacted))
+	require.Equal(t, "boom: ‹×›\ngoroutine 1 [running]:", redacted.Message)
+	require.Equal(t, "goroutine 1 [running]:", redacted.Message[redacted.StackTraceStart:])
+}
+
+func TestRedactInterceptedEntryNotRedactable(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// An entry which is not redactable carries no markers, so its whole
+	// message and its tag values are considered sensitive.
+	msg := "login by bob"
+	raw := logpb.Entry{
+		Message:         msg + "\ngoroutine 1 [running]:",
+		Tags:            "n1,user=bob",
+		StackTraceStart: uint32(len(msg)) + 1,
+	}
+	j, err := json.Marshal(raw)
+	require.NoError(t, err)
+
+	var redacted logpb.Entry
+	require.NoError(t, json.Unmarshal(redactInterceptedEntry(j), &redacted))
+	require.True(t, redacted.Redactable)
+	require.Equal(t, "‹×›\ngoroutine 1 [running]:", redacted.Message)
+	require.Equal(t, "goroutine 1 [running]:", redacted.Message[redacted.StackTraceStart:])
+	require.Equal(t, "n‹×›,user=‹×›", redacted.Tags)
+
+	redactedMsg, err := redactedInterceptedMessage(j)
+	require.NoError(t, err)
+	require.Equal(t, "‹×›", redactedMsg)
+}
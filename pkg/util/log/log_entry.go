@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
 	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/logtags"
 	"github.com/cockroachdb/redact"
 	"github.com/cockroachdb/redact/interfaces"
@@ -72,6 +73,10 @@ type logEntry struct {
 	file string
 	line int
 
+	// The IDs of the trace and span active on the context of the event,
+	// if any. They are zero otherwise.
+	traceID, spanID uint64
+
 	// The entry counter. Populated by outputLogEntry().
 	counter uint64
 
@@ -204,6 +209,12 @@ func makeEntry(ctx context.Context, s Severity, c Channel, depth int) (res logEn
 	// Populate file/lineno.
 	res.file, res.line, _ = caller.Lookup(depth + 1)
 
+	// Populate the trace/span IDs, for correlation with distributed traces.
+	if sp := tracing.SpanFromContext(ctx); sp != nil {
+		res.traceID = uint64(sp.TraceID())
+		res.spanID = uint64(sp.SpanID())
+	}
+
 	return res
 }
 
@@ -273,14 +284,23 @@ func makeStartLine(formatter logFormatter, format string, args ...interface{}) *
 // getStartLines retrieves the log entries for the start
 // of a new log file output.
 func (l *sinkInfo) getStartLines(now time.Time) []*buffer {
-	f := l.formatter
-	messages := make([]*buffer, 0, 6)
+	f := formatterForSink(l)
+	messages := make([]*buffer, 0, 7)
 	messages = append(messages,
 		makeStartLine(f, "file created at: %s", redact.Safe(now.Format("2006/01/02 15:04:05"))),
 		makeStartLine(f, "running on machine: %s", fullHostName),
 		makeStartLine(f, "binary: %s", redact.Safe(build.GetInfo().Short())),
+		// The build tag and version are also reported on their own, so that
+		// the version of the binary can be recovered from any log file shipped
+		// off the machine without parsing the binary description.
+		makeStartLine(f, "build tag: %s, version: %s",
+			redact.Safe(build.GetInfo().Tag), redact.Safe(build.BinaryVersion())),
 		makeStartLine(f, "arguments: %s", os.Args),
 	)
+	if includeUptime.Get() {
+		messages = append(messages, makeStartLine(f, "process started at: %s",
+			redact.Safe(processStartTime.Format("2006/01/02 15:04:05"))))
+	}
 
 	// Including a non-ascii character in the first 1024 bytes of the log helps
 	// viewers that attempt to guess the character encoding.
@@ -0,0 +1,117 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// EnableTailBuffer configures the logging package to retain the last
+// `size` entries logged on any channel, regardless of the filtering
+// configured on log sinks, so that they can be replayed to the
+// tailers attached with AttachTailer(). Entries retained so far are
+// discarded. A size of zero stops retaining entries.
+//
+// Note that while entries are retained, every log entry is formatted
+// for the interceptors, even when no interceptor is configured.
+func EnableTailBuffer(size int) {
+	i := &logging.interceptor
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.tail.reset(size)
+	var active uint32
+	if size > 0 {
+		active = 1
+	}
+	atomic.StoreUint32(&i.tailActive, active)
+}
+
+// AttachTailer is like InterceptWith, but additionally returns the
+// entries retained by the tail buffer (see EnableTailBuffer) at the
+// time `fn` starts intercepting log traffic, oldest first. Every entry
+// is either part of the returned backlog or passed to `fn`, never
+// both.
+//
+// The returned function should be called to cancel the interception.
+func AttachTailer(fn Interceptor) (backlog []logpb.Entry, detach func()) {
+	i := &logging.interceptor
+	i.mu.Lock()
+	// The entries are served to the interceptors, and appended to the
+	// tail buffer, under the read lock. Taking the snapshot and adding
+	// the interceptor under the write lock thus ensures that no entry
+	// is output in between.
+	retained := i.tail.snapshot()
+	i.mu.fns = append(i.mu.fns, fn)
+	atomic.AddUint32(&i.activeCount, 1)
+	i.mu.Unlock()
+
+	backlog = make([]logpb.Entry, 0, len(retained))
+	for _, b := range retained {
+		var entry logpb.Entry
+		if err := json.Unmarshal(b, &entry); err != nil {
+			// The entries are formatted by formatInterceptor, so this
+			// is not expected. Skip the entry rather than failing the
+			// whole replay.
+			continue
+		}
+		backlog = append(backlog, entry)
+	}
+	return backlog, func() { i.del(fn) }
+}
+
+// tailBuffer is a ring buffer retaining the last entries served to
+// the interceptors.
+type tailBuffer struct {
+	syncutil.Mutex
+	// entries holds the retained entries. Once it is full, the oldest
+	// entry is at index next.
+	entries [][]byte
+	next    int
+	size    int
+}
+
+func (t *tailBuffer) reset(size int) {
+	t.Lock()
+	defer t.Unlock()
+	t.entries = nil
+	t.next = 0
+	t.size = size
+}
+
+// add retains a copy of the entry, evicting the oldest entry if the
+// buffer is full.
+func (t *tailBuffer) add(entry []byte) {
+	t.Lock()
+	defer t.Unlock()
+	if t.size == 0 {
+		return
+	}
+	cp := append([]byte(nil), entry...)
+	if len(t.entries) < t.size {
+		t.entries = append(t.entries, cp)
+		return
+	}
+	t.entries[t.next] = cp
+	t.next = (t.next + 1) % t.size
+}
+
+// snapshot returns the retained entries, oldest first.
+func (t *tailBuffer) snapshot() [][]byte {
+	t.Lock()
+	defer t.Unlock()
+	res := make([][]byte, 0, len(t.entries))
+	res = append(res, t.entries[t.next:]...)
+	return append(res, t.entries[:t.next]...)
+}
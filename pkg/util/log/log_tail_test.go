@@ -0,0 +1,136 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/redact"
+	"github.com/stretchr/testify/require"
+)
+
+// entryInterceptor collects the intercepted entries.
+type entryInterceptor struct {
+	syncutil.Mutex
+	entries []logpb.Entry
+	err     error
+}
+
+var _ Interceptor = (*entryInterceptor)(nil)
+
+func (i *entryInterceptor) Intercept(message []byte) {
+	var entry logpb.Entry
+	i.Lock()
+	defer i.Unlock()
+	if err := json.Unmarshal(message, &entry); err != nil {
+		i.err = err
+		return
+	}
+	i.entries = append(i.entries, entry)
+}
+
+func TestTailBufferRingOrder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var tb tailBuffer
+	tb.reset(3)
+	for _, s := range []string{"a", "b"} {
+		tb.add([]byte(s))
+	}
+	require.Equal(t, [][]byte{[]byte("a"), []byte("b")}, tb.snapshot())
+	for _, s := range []string{"c", "d", "e"} {
+		tb.add([]byte(s))
+	}
+	require.Equal(t, [][]byte{[]byte("c"), []byte("d"), []byte("e")}, tb.snapshot())
+
+	tb.reset(0)
+	tb.add([]byte("f"))
+	require.Empty(t, tb.snapshot())
+}
+
+func TestAttachTailerNoGapsOrOverlaps(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer Scope(t).Close(t)
+
+	const numWorkers = 10
+	const numEntries = 200
+	// The tail buffer is large enough to retain all the entries logged
+	// before the tailer is attached.
+	EnableTailBuffer(2 * numWorkers * numEntries)
+	defer EnableTailBuffer(0)
+
+	// Attach the tailer while the workers are logging. The first worker
+	// waits for the tailer to be attached halfway through, so that both
+	// the backlog and the live stream contain some of its entries.
+	tailer := &entryInterceptor{}
+	var backlog []logpb.Entry
+	var detach func()
+	attachC := make(chan struct{})
+	attachedC := make(chan struct{})
+	require.NoError(t,
+		ctxgroup.GroupWorkers(context.Background(), numWorkers+1, func(ctx context.Context, worker int) error {
+			if worker == numWorkers {
+				<-attachC
+				backlog, detach = AttachTailer(tailer)
+				close(attachedC)
+				return nil
+			}
+			for i := 0; i < numEntries; i++ {
+				if worker == 0 && i == numEntries/2 {
+					close(attachC)
+					<-attachedC
+				}
+				Infof(ctx, "tail %d %d", redact.Safe(worker), redact.Safe(i))
+			}
+			return nil
+		}))
+	detach()
+
+	tailer.Lock()
+	defer tailer.Unlock()
+	require.NoError(t, tailer.err)
+
+	// Every entry is seen exactly once, either in the backlog or by the
+	// tailer, and the backlog precedes the live stream.
+	re := regexp.MustCompile(`tail (\d+) (\d+)`)
+	var numBacklog, numStreamed int
+	next := make([]int, numWorkers)
+	check := func(entries []logpb.Entry, count *int) {
+		for _, e := range entries {
+			m := re.FindStringSubmatch(e.Message)
+			if m == nil {
+				continue
+			}
+			worker, err := strconv.Atoi(m[1])
+			require.NoError(t, err)
+			i, err := strconv.Atoi(m[2])
+			require.NoError(t, err)
+			require.Equal(t, next[worker], i, "worker %d", worker)
+			next[worker]++
+			*count++
+		}
+	}
+	check(backlog, &numBacklog)
+	check(tailer.entries, &numStreamed)
+	for worker := range next {
+		require.Equal(t, numEntries, next[worker], "worker %d", worker)
+	}
+	require.Greater(t, numBacklog, 0)
+	require.Greater(t, numStreamed, 0)
+}
@@ -387,6 +387,19 @@ type FileDefaults struct {
 	// Setting this to false flushes log writes upon every entry.
 	BufferedWrites *bool `yaml:"buffered-writes,omitempty"`
 
+	// CompressRotatedFiles specifies whether to compress log files with
+	// gzip after they are rotated out. The active file is never
+	// compressed; compressed files are named with a `.log.gz` suffix.
+	CompressRotatedFiles *bool `yaml:"compress-rotated-files,omitempty"`
+
+	// RotationInterval is the interval at which log files are rotated,
+	// regardless of their size. Rotations are aligned on multiples of the
+	// interval since the Unix epoch, so that e.g. `24h` rotates files at
+	// midnight UTC and `1h` at the top of each hour. Files are also rotated
+	// when they reach max-file-size, whichever comes first. If zero, files
+	// are only rotated based on their size.
+	RotationInterval *time.Duration `yaml:"rotation-interval,omitempty"`
+
 	// CommonSinkConfig is the configuration common to all sinks. Note
 	// that although the idiom in Go is to place embedded fields at the
 	// beginning of a struct, we purposefully deviate from the idiom
@@ -55,11 +55,13 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 		},
 	}
 	baseFileDefaults := FileDefaults{
-		Dir:             defaultLogDir,
-		BufferedWrites:  &bt,
-		MaxFileSize:     &zeroByteSize,
-		MaxGroupSize:    &zeroByteSize,
-		FilePermissions: func() *FilePermissions { s := FilePermissions(0o644); return &s }(),
+		Dir:                  defaultLogDir,
+		BufferedWrites:       &bt,
+		CompressRotatedFiles: &bf,
+		RotationInterval:     &zeroDuration,
+		MaxFileSize:          &zeroByteSize,
+		MaxGroupSize:         &zeroByteSize,
+		FilePermissions:      func() *FilePermissions { s := FilePermissions(0o644); return &s }(),
 		CommonSinkConfig: CommonSinkConfig{
 			Format:      func() *string { s := DefaultFileFormat; return &s }(),
 			Criticality: &bt,
@@ -340,6 +342,9 @@ func (c *Config) validateFileSinkConfig(fc *FileSinkConfig, defaultLogDir *strin
 			return err
 		}
 	}
+	if *fc.RotationInterval < 0 {
+		return errors.Newf("rotation-interval must not be negative: %s", *fc.RotationInterval)
+	}
 	if fc.Dir == nil {
 		// After normalization, the remaining directory is empty.  Make
 		// this sink filter everything, so we don't spend time computing
@@ -80,6 +80,12 @@ func clearExpectedValues(c *Config) {
 		if *f.BufferedWrites == true {
 			f.BufferedWrites = nil
 		}
+		if *f.CompressRotatedFiles == false {
+			f.CompressRotatedFiles = nil
+		}
+		if *f.RotationInterval == 0 {
+			f.RotationInterval = nil
+		}
 		if *f.Format == "crdb-v2" {
 			f.Format = nil
 		}
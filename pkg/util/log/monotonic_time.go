@@ -0,0 +1,34 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import "sync/atomic"
+
+// lastEntryTimestamp is the largest timestamp, in nanoseconds since
+// the Unix epoch, of the log entries output so far. Accessed
+// atomically.
+var lastEntryTimestamp int64
+
+// monotonicTimestamp corrects the wall clock timestamp of a log entry
+// so that the timestamps of the entries output by the process never
+// decrease. After the wall clock is adjusted backwards, the entries
+// reuse the last timestamp until the wall clock catches up with it.
+func monotonicTimestamp(ts int64) int64 {
+	for {
+		last := atomic.LoadInt64(&lastEntryTimestamp)
+		if ts <= last {
+			return last
+		}
+		if atomic.CompareAndSwapInt64(&lastEntryTimestamp, last, ts) {
+			return ts
+		}
+	}
+}
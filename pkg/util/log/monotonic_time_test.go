@@ -0,0 +1,89 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/stretchr/testify/require"
+)
+
+// timestampInterceptor collects the timestamp of the intercepted
+// entries whose message contains a marker.
+type timestampInterceptor struct {
+	syncutil.Mutex
+	timestamps []int64
+	err        error
+}
+
+var _ Interceptor = (*timestampInterceptor)(nil)
+
+func (i *timestampInterceptor) Intercept(message []byte) {
+	var entry struct {
+		Message string
+		Time    int64
+	}
+	i.Lock()
+	defer i.Unlock()
+	if err := json.Unmarshal(message, &entry); err != nil {
+		i.err = err
+		return
+	}
+	if strings.Contains(entry.Message, "timestamp marker") {
+		i.timestamps = append(i.timestamps, entry.Time)
+	}
+}
+
+func TestMonotonicEntryTimestamps(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer Scope(t).Close(t)
+
+	// The entries below are timestamped in the future. Forget about
+	// them afterwards so that the timestamps of the entries logged by
+	// other tests are not corrected.
+	defer func(last int64) {
+		atomic.StoreInt64(&lastEntryTimestamp, last)
+	}(atomic.LoadInt64(&lastEntryTimestamp))
+
+	ctx := context.Background()
+	interceptor := &timestampInterceptor{}
+	defer InterceptWith(ctx, interceptor)()
+
+	// The wall clock jumps backwards by a minute after the first entry,
+	// then moves past the first entry again.
+	base := timeutil.Now().Add(time.Hour).UnixNano()
+	wallTimes := []int64{
+		base,
+		base - time.Minute.Nanoseconds(),
+		base + time.Second.Nanoseconds(),
+	}
+	for i, ts := range wallTimes {
+		entry := makeUnstructuredEntry(ctx, severity.INFO, channel.DEV, 0, /* depth */
+			true /* redactable */, "timestamp marker %d", i)
+		entry.ts = ts
+		logging.getLogger(channel.DEV).outputLogEntry(entry)
+	}
+
+	interceptor.Lock()
+	defer interceptor.Unlock()
+	require.NoError(t, interceptor.err)
+	require.Equal(t, []int64{base, base, base + time.Second.Nanoseconds()}, interceptor.timestamps)
+}
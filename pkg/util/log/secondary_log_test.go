@@ -11,6 +11,7 @@
 package log
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"io/ioutil"
@@ -19,6 +20,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/cli/exit"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
 	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
@@ -172,3 +174,86 @@ func TestListLogFilesIncludeSecondaryLogs(t *testing.T) {
 		t.Fatalf("unexpected results; expected file %q, got: %+v", expectedName, results)
 	}
 }
+
+// TestChannelWithMultipleFileSinks checks that an entry logged on a
+// channel routed to multiple file groups lands in all their files,
+// and that a failure on the secondary file sink does not terminate the
+// process once the entry was written to the primary one.
+func TestChannelWithMultipleFileSinks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	// Route the SENSITIVE_ACCESS channel both to its dedicated file and
+	// to a combined audit file.
+	cfg := logconfig.DefaultConfig()
+	cfg.Sinks.FileGroups = map[string]*logconfig.FileSinkConfig{
+		"sensitive-access": {Channels: logconfig.SelectChannels(channel.SENSITIVE_ACCESS)},
+		"sql-audit":        {Channels: logconfig.SelectChannels(channel.SENSITIVE_ACCESS, channel.SESSIONS)},
+	}
+	if err := cfg.Validate(&s.logDir); err != nil {
+		t.Fatal(err)
+	}
+	TestingResetActive()
+	cleanup, err := ApplyConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	var fileSinks []*fileSink
+	for _, si := range logging.getLogger(channel.SENSITIVE_ACCESS).sinkInfos {
+		if fs, ok := si.sink.(*fileSink); ok {
+			fileSinks = append(fileSinks, fs)
+		}
+	}
+	if len(fileSinks) != 2 {
+		t.Fatalf("expected 2 file sinks, found %d", len(fileSinks))
+	}
+	// The file groups are attached in the order of their names.
+	primary, secondary := fileSinks[0], fileSinks[1]
+	if primary.groupName != "sensitive-access" || secondary.groupName != "sql-audit" {
+		t.Fatalf("unexpected file sinks: %q, %q", primary.groupName, secondary.groupName)
+	}
+
+	ctx := context.Background()
+	SensitiveAccess.Infof(ctx, "hello both")
+	Flush()
+	for _, fs := range fileSinks {
+		contents, err := ioutil.ReadFile(fs.getFileName(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(contents), "hello both") {
+			t.Errorf("file group %q does not contain entry\n%s", fs.groupName, contents)
+		}
+	}
+
+	// Make the writes to the secondary file sink fail.
+	SetExitFunc(false /* hideStack */, func(exit.Code) {
+		t.Error("unexpected exit on secondary file sink failure")
+	})
+	defer ResetExitFunc()
+	secondary.mu.Lock()
+	prevFile := secondary.mu.file
+	secondary.mu.file = &syncBuffer{
+		fileSink: secondary,
+		Writer:   bufio.NewWriterSize(&outOfSpaceWriter{}, 1),
+	}
+	secondary.mu.Unlock()
+	defer func() {
+		secondary.mu.Lock()
+		defer secondary.mu.Unlock()
+		secondary.mu.file = prevFile
+	}()
+
+	SensitiveAccess.Infof(ctx, "hello primary")
+	Flush()
+	contents, err := ioutil.ReadFile(primary.getFileName(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(contents), "hello primary") {
+		t.Errorf("primary file sink does not contain entry\n%s", contents)
+	}
+}
@@ -73,3 +73,4 @@ var _ logSink = (*fileSink)(nil)
 var _ logSink = (*fluentSink)(nil)
 var _ logSink = (*httpSink)(nil)
 var _ logSink = (*bufferSink)(nil)
+var _ logSink = (*channelInterceptorSink)(nil)
@@ -0,0 +1,67 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// slowSinkWriteReportInterval is the minimum interval between two
+// reports of a slow sink write.
+const slowSinkWriteReportInterval = 10 * time.Second
+
+// slowSinkWrites tracks the reporting of writes to log sinks that
+// take unexpectedly long to complete.
+var slowSinkWrites struct {
+	// threshold is the duration, in nanoseconds, above which a write to
+	// a sink is reported as slow. Zero disables the reporting.
+	threshold int64
+	// lastReport is the time, in nanoseconds since the epoch, of the
+	// last report. Used to rate-limit the reports.
+	lastReport int64
+}
+
+func init() {
+	slowSinkWrites.threshold = int64(time.Second)
+}
+
+// SetSlowSinkWriteThreshold configures the duration above which a
+// write to a log sink is reported as slow on the process' stderr.
+// A zero or negative threshold disables the reporting.
+func SetSlowSinkWriteThreshold(threshold time.Duration) {
+	atomic.StoreInt64(&slowSinkWrites.threshold, int64(threshold))
+}
+
+// maybeReportSlowSinkWrite reports on the process' stderr that a
+// write to the given sink took elapsed time, if this exceeds the
+// configured threshold.
+//
+// This is called with the parent logger's outputMu held, so it must
+// not recursively call into logging. The report is thus written
+// directly to OrigStderr, and rate-limited without locking.
+func maybeReportSlowSinkWrite(sink logSink, elapsed time.Duration) {
+	threshold := time.Duration(atomic.LoadInt64(&slowSinkWrites.threshold))
+	if threshold <= 0 || elapsed < threshold {
+		return
+	}
+	now := timeutil.Now().UnixNano()
+	last := atomic.LoadInt64(&slowSinkWrites.lastReport)
+	if now-last < int64(slowSinkWriteReportInterval) ||
+		!atomic.CompareAndSwapInt64(&slowSinkWrites.lastReport, last, now) {
+		return
+	}
+	fmt.Fprintf(OrigStderr, "WARNING: slow write to log sink %T: %s (threshold %s)\n",
+		sink, elapsed, threshold)
+}
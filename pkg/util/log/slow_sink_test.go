@@ -0,0 +1,91 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/exit"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/stretchr/testify/require"
+)
+
+// slowSink is a logSink which takes a configurable amount of time to
+// complete each write.
+type slowSink struct {
+	delay time.Duration
+}
+
+var _ logSink = (*slowSink)(nil)
+
+func (s *slowSink) active() bool                     { return true }
+func (s *slowSink) attachHints(stacks []byte) []byte { return stacks }
+func (s *slowSink) exitCode() exit.Code              { return exit.UnspecifiedError() }
+func (s *slowSink) output(_ []byte, _ sinkOutputOptions) error {
+	time.Sleep(s.delay)
+	return nil
+}
+
+func TestSlowSinkWriteWarning(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	// Redirect the process' stderr to a file so we can inspect the warning.
+	f, err := os.Create(filepath.Join(s.logDir, "stderr"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	defer func(prevStderr *os.File) { OrigStderr = prevStderr }(OrigStderr)
+	OrigStderr = f
+
+	defer SetSlowSinkWriteThreshold(time.Duration(atomic.LoadInt64(&slowSinkWrites.threshold)))
+	atomic.StoreInt64(&slowSinkWrites.lastReport, 0)
+
+	// Inject a slow sink on the DEV channel. We put it first so that it
+	// is the first sink to be reported.
+	l := logging.getLogger(channel.DEV)
+	si := &sinkInfo{
+		sink:      &slowSink{delay: 10 * time.Millisecond},
+		editor:    getEditor(WithMarkedSensitiveData),
+		formatter: formatCrdbV2{},
+	}
+	si.threshold.setAll(severity.INFO)
+	defer func(prev []*sinkInfo) { l.sinkInfos = prev }(l.sinkInfos)
+	l.sinkInfos = append([]*sinkInfo{si}, l.sinkInfos...)
+
+	readStderr := func() string {
+		b, err := ioutil.ReadFile(f.Name())
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	// A write below the threshold is not reported.
+	SetSlowSinkWriteThreshold(time.Hour)
+	Info(context.Background(), "not slow")
+	require.NotContains(t, readStderr(), "slow write to log sink")
+
+	// A write above the threshold is reported, once.
+	SetSlowSinkWriteThreshold(time.Millisecond)
+	Info(context.Background(), "slow")
+	Info(context.Background(), "slow again")
+	out := readStderr()
+	require.Contains(t, out, "WARNING: slow write to log sink *log.slowSink")
+	require.Equal(t, 1, strings.Count(out, "slow write to log sink"))
+}
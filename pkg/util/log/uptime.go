@@ -0,0 +1,52 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// processStartTime is the time at which the logging package was
+// initialized. It is used as an approximation of the process start
+// time when reporting the process uptime.
+var processStartTime = timeutil.Now()
+
+// includeUptime, when set, causes the process start time to be
+// reported in the header of log files, and the process uptime at the
+// time an entry was emitted to be reported as an "uptime" tag on
+// every log entry. This helps correlate events across restarts.
+var includeUptime = func() (b syncutil.AtomicBool) {
+	b.Set(envutil.EnvOrDefaultBool("COCKROACH_LOG_INCLUDE_UPTIME", false))
+	return b
+}()
+
+// SetIncludeUptime configures whether log entries report the process
+// uptime, and log files the process start time.
+func SetIncludeUptime(include bool) {
+	includeUptime.Set(include)
+}
+
+// withUptime returns a copy of the tags with an additional "uptime"
+// tag reporting the process uptime at the given timestamp.
+func (f formattableTags) withUptime(ts int64) formattableTags {
+	uptime := time.Duration(ts - processStartTime.UnixNano())
+	res := make(formattableTags, 0, len(f)+len("uptime")+len("000h00m00.000000000s")+2)
+	res = append(res, f...)
+	res = append(res, "uptime"...)
+	res = append(res, 0)
+	res = append(res, uptime.String()...)
+	res = append(res, 0)
+	return res
+}
@@ -0,0 +1,77 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+// uptimeInterceptor collects the uptime tag of the intercepted
+// entries whose message contains a marker.
+type uptimeInterceptor struct {
+	syncutil.Mutex
+	uptimes []time.Duration
+	err     error
+}
+
+var _ Interceptor = (*uptimeInterceptor)(nil)
+
+func (u *uptimeInterceptor) Intercept(message []byte) {
+	var entry struct {
+		Message string
+		Tags    map[string]string
+	}
+	u.Lock()
+	defer u.Unlock()
+	if err := json.Unmarshal(message, &entry); err != nil {
+		u.err = err
+		return
+	}
+	if !strings.Contains(entry.Message, "uptime marker") {
+		return
+	}
+	uptime, err := time.ParseDuration(entry.Tags["uptime"])
+	if err != nil {
+		u.err = err
+		return
+	}
+	u.uptimes = append(u.uptimes, uptime)
+}
+
+func TestIncludeUptime(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer Scope(t).Close(t)
+
+	SetIncludeUptime(true)
+	defer SetIncludeUptime(false)
+
+	interceptor := &uptimeInterceptor{}
+	defer InterceptWith(context.Background(), interceptor)()
+
+	Infof(context.Background(), "uptime marker 1")
+	time.Sleep(time.Millisecond)
+	Infof(context.Background(), "uptime marker 2")
+
+	interceptor.Lock()
+	defer interceptor.Unlock()
+	require.NoError(t, interceptor.err)
+	require.Len(t, interceptor.uptimes, 2)
+	require.Greater(t, interceptor.uptimes[0], time.Duration(0))
+	require.Greater(t, interceptor.uptimes[1], interceptor.uptimes[0])
+}
@@ -65,7 +65,9 @@ func SetVModule(value string) error {
 	return logging.vmoduleConfig.mu.vmodule.Set(value)
 }
 
-// GetVModule returns the current vmodule configuration.
+// GetVModule returns the current vmodule configuration, in the format
+// accepted by SetVModule. Patterns set to level 0 are omitted, as they have no
+// effect. It is safe to call concurrently with SetVModule.
 func GetVModule() string {
 	return logging.vmoduleConfig.mu.vmodule.String()
 }
@@ -0,0 +1,65 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// captureWarningStacks, when set, causes the stack trace of the
+// goroutine emitting a WARNING entry to be attached to the entry the
+// first time its format string is seen. Subsequent entries with the
+// same format string are emitted without a stack trace, so that
+// recurring warnings can be root-caused without paying for a stack
+// capture on every entry.
+var captureWarningStacks = func() (b syncutil.AtomicBool) {
+	b.Set(envutil.EnvOrDefaultBool("COCKROACH_LOG_CAPTURE_WARNING_STACKS", false))
+	return b
+}()
+
+// SetCaptureWarningStacks configures whether the first WARNING entry
+// emitted with a given format string carries a stack trace.
+func SetCaptureWarningStacks(capture bool) {
+	captureWarningStacks.Set(capture)
+}
+
+// maxWarningStackFormats bounds the number of format strings for which
+// a stack trace was captured, since some callers log dynamic messages
+// as format strings. Once reached, no more stack traces are captured.
+const maxWarningStackFormats = 1000
+
+// warningStacks tracks the format strings of the WARNING entries for
+// which a stack trace was captured.
+var warningStacks struct {
+	syncutil.Mutex
+	seen map[string]struct{}
+}
+
+// maybeGetWarningStacks returns the stack trace of the current
+// goroutine if capturing stack traces for WARNING entries is enabled
+// and the format string has not been seen before, and nil otherwise.
+func maybeGetWarningStacks(format string) []byte {
+	if !captureWarningStacks.Get() {
+		return nil
+	}
+	warningStacks.Lock()
+	defer warningStacks.Unlock()
+	if _, seen := warningStacks.seen[format]; seen ||
+		len(warningStacks.seen) >= maxWarningStackFormats {
+		return nil
+	}
+	if warningStacks.seen == nil {
+		warningStacks.seen = make(map[string]struct{})
+	}
+	warningStacks.seen[format] = struct{}{}
+	return getStacks(false)
+}
@@ -0,0 +1,47 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureWarningStacks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer Scope(t).Close(t)
+
+	SetCaptureWarningStacks(true)
+	defer SetCaptureWarningStacks(false)
+
+	ctx := context.Background()
+	interceptor := &captureInterceptor{t: t, re: regexp.MustCompile("recurring warning")}
+	defer InterceptWith(ctx, interceptor)()
+
+	for i := 0; i < 3; i++ {
+		Warningf(ctx, "recurring warning %d", i)
+	}
+	// Entries at other severities never carry a stack trace.
+	Infof(ctx, "recurring warning at info")
+
+	interceptor.Lock()
+	defer interceptor.Unlock()
+	require.Len(t, interceptor.messages, 4)
+	for i, m := range interceptor.messages {
+		hasStacks := strings.Contains(string(m), "TestCaptureWarningStacks")
+		require.Equal(t, i == 0, hasStacks, "message %d: %s", i, m)
+	}
+}